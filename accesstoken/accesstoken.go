@@ -0,0 +1,147 @@
+// Package accesstoken manages the personal access tokens of users
+// Tokens are long lived credentials for scripts and the command line. They are named,
+// limited by a scope, expire and are revocable. Only the hash of a token is kept
+package accesstoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+var (
+	ErrRevoked        = errors.New("Access token has been revoked")
+	ErrExpired        = errors.New("Access token has expired")
+	ErrExpiryPassed   = errors.New("Expiry of access token needs to be in the future")
+	ErrExpiryRequired = errors.New("Expiry of access token is required")
+	ErrNameEmpty      = errors.New("Name of access token cannot be empty")
+)
+
+// Scope limits what requests a token is able to make
+type Scope string
+
+// ReadOnly tokens are only able to retrieve items
+var ReadOnly Scope = "read-only"
+
+// ProjectWrite tokens are able to make changes to the content of projects that the user has
+// access to. They are unable to share projects or make changes to the account of the user
+var ProjectWrite Scope = "project-write"
+
+// ParseScope converts the name of a scope into a scope
+func ParseScope(raw string) (Scope, error) {
+	switch Scope(raw) {
+	case ReadOnly, ProjectWrite:
+		return Scope(raw), nil
+	}
+	return "", fmt.Errorf("invalid access token scope %v", raw)
+}
+
+// tokenPrefix marks personal access tokens so that they can be told apart from JWTs
+const tokenPrefix = "stv_"
+
+type AccessToken struct {
+	ID     string `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	UserID string `json:"user_id" gorm:"type:varchar(40);index"`
+	Name   string `json:"name" gorm:"type:varchar(250)"`
+	Scope  Scope  `json:"scope" gorm:"type:varchar(20)"`
+	Hash   string `json:"-" gorm:"type:varchar(64);unique_index"`
+	// Hint is the start of the token so that users can recognise it once the token is no
+	// longer shown
+	Hint        string     `json:"hint" gorm:"type:varchar(20)"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	DateCreated time.Time  `json:"date_created"`
+}
+
+// New creates a token for the user. The token itself is only returned here - it is to be
+// handed to the user right away as only its hash is kept
+func New(userID, name, scope string, expiresAt time.Time) (AccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return AccessToken{}, "", ErrNameEmpty
+	}
+	s, err := ParseScope(scope)
+	if err != nil {
+		return AccessToken{}, "", err
+	}
+	if expiresAt.IsZero() {
+		return AccessToken{}, "", ErrExpiryRequired
+	}
+	if !expiresAt.After(time.Now()) {
+		return AccessToken{}, "", ErrExpiryPassed
+	}
+	raw := make([]byte, 32)
+	_, err = rand.Read(raw)
+	if err != nil {
+		return AccessToken{}, "", err
+	}
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	tokenID, _ := uuid.NewV4()
+	return AccessToken{
+		ID:          tokenID.String(),
+		UserID:      userID,
+		Name:        name,
+		Scope:       s,
+		Hash:        Hash(token),
+		Hint:        token[:len(tokenPrefix)+4],
+		ExpiresAt:   expiresAt,
+		DateCreated: time.Now(),
+	}, token, nil
+}
+
+// IsAccessToken checks if the credential provided in a request is a personal access token
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, tokenPrefix)
+}
+
+// Hash provides the value that is kept for the token and is used to look it up
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Active checks that the token can still be used
+func (t AccessToken) Active(now time.Time) error {
+	if t.RevokedAt != nil {
+		return ErrRevoked
+	}
+	if !now.Before(t.ExpiresAt) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// Allows checks if the scope of the token allows a request with the method. projectWrite is set
+// for the routes that make changes to the content of projects
+func (t AccessToken) Allows(method string, projectWrite bool) bool {
+	readOnly := method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+	switch t.Scope {
+	case ProjectWrite:
+		return readOnly || projectWrite
+	case ReadOnly:
+		return readOnly
+	}
+	return false
+}
+
+// Revoke disables the token. Revoked tokens are kept so that users can see that they were revoked
+func Revoke() ([]func(*AccessToken) error, error) {
+	var setters []func(*AccessToken) error
+	setters = append(setters, func(t *AccessToken) error {
+		if t.RevokedAt != nil {
+			return ErrRevoked
+		}
+		now := time.Now()
+		t.RevokedAt = &now
+		return nil
+	})
+	return setters, nil
+}
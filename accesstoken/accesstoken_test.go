@@ -0,0 +1,81 @@
+package accesstoken
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	if _, _, err := New("user", "ci", string(ReadOnly), time.Now().Add(-time.Hour)); err != ErrExpiryPassed {
+		t.Errorf("expected error for expiry in the past. Err: %v", err)
+	}
+	if _, _, err := New("user", "ci", string(ReadOnly), time.Time{}); err != ErrExpiryRequired {
+		t.Errorf("expected error for missing expiry. Err: %v", err)
+	}
+	if _, _, err := New("user", " ", string(ReadOnly), expiry); err != ErrNameEmpty {
+		t.Errorf("expected error for empty name. Err: %v", err)
+	}
+	if _, _, err := New("user", "ci", "admin", expiry); err == nil {
+		t.Errorf("expected error for unknown scope")
+	}
+
+	a, token, err := New("user", "ci", string(ProjectWrite), expiry)
+	if err != nil {
+		t.Fatalf("unexpected error when creating access token. Err: %v", err)
+	}
+	_, other, _ := New("user", "ci", string(ProjectWrite), expiry)
+	if !IsAccessToken(token) || token == other {
+		t.Errorf("expected random access tokens. %v %v", token, other)
+	}
+	if a.Hash != Hash(token) || a.Hash == token {
+		t.Errorf("expected only hash of token to be kept. %+v", a)
+	}
+	if IsAccessToken("eyJhbGciOiJIUzI1NiJ9.e30.abc") {
+		t.Errorf("expected jwt to not be an access token")
+	}
+}
+
+func TestAccessToken_Active(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	a, _, _ := New("user", "ci", string(ReadOnly), expiry)
+	if err := a.Active(time.Now()); err != nil {
+		t.Errorf("expected access token to be active. Err: %v", err)
+	}
+	if err := a.Active(expiry); err != ErrExpired {
+		t.Errorf("expected access token to be expired. Err: %v", err)
+	}
+
+	setters, _ := Revoke()
+	setters[0](&a)
+	if err := a.Active(time.Now()); err != ErrRevoked {
+		t.Errorf("expected access token to be revoked. Err: %v", err)
+	}
+	if err := setters[0](&a); err != ErrRevoked {
+		t.Errorf("expected error when revoking access token again. Err: %v", err)
+	}
+}
+
+func TestAccessToken_Allows(t *testing.T) {
+	tests := []struct {
+		scope        Scope
+		method       string
+		projectWrite bool
+		want         bool
+	}{
+		{scope: ReadOnly, method: http.MethodGet, want: true},
+		{scope: ReadOnly, method: http.MethodPost, projectWrite: true, want: false},
+		{scope: ReadOnly, method: http.MethodDelete, projectWrite: true, want: false},
+		{scope: ProjectWrite, method: http.MethodGet, want: true},
+		{scope: ProjectWrite, method: http.MethodPut, projectWrite: true, want: true},
+		{scope: ProjectWrite, method: http.MethodPut, want: false},
+		{scope: ProjectWrite, method: http.MethodDelete, want: false},
+		{scope: "", method: http.MethodGet, want: false},
+	}
+	for _, tt := range tests {
+		if got := (AccessToken{Scope: tt.scope}).Allows(tt.method, tt.projectWrite); got != tt.want {
+			t.Errorf("Allows(%v, %v) for %v = %v, want %v", tt.method, tt.projectWrite, tt.scope, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package accesstoken
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger         logger.Logger
+	userEntityName string
+	entityName     string
+	client         *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, userEntity, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:         logger,
+		client:         ds,
+		entityName:     en,
+		userEntityName: userEntity,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e AccessToken) error {
+	userKey := datastore.NameKey(g.userEntityName, e.UserID, nil)
+	newKey := datastore.NameKey(g.entityName, e.ID, userKey)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) Get(ctx context.Context, userID, ID string) (AccessToken, error) {
+	userKey := datastore.NameKey(g.userEntityName, userID, nil)
+	key := datastore.NameKey(g.entityName, ID, userKey)
+	t := AccessToken{}
+	if err := g.client.Get(ctx, key, &t); err != nil {
+		return AccessToken{}, fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+	}
+	t.ID = ID
+	return t, nil
+}
+
+func (g *googleDatastore) GetByHash(ctx context.Context, hash string) (AccessToken, error) {
+	query := datastore.NewQuery(g.entityName).Filter("Hash =", hash).Limit(1)
+	tokens := []AccessToken{}
+	keys, err := g.client.GetAll(ctx, query, &tokens)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	if len(keys) == 0 {
+		return AccessToken{}, fmt.Errorf("access token not found")
+	}
+	tokens[0].ID = keys[0].Name
+	return tokens[0], nil
+}
+
+func (g *googleDatastore) GetAll(ctx context.Context, userID string) ([]AccessToken, error) {
+	userKey := datastore.NameKey(g.userEntityName, userID, nil)
+	query := datastore.NewQuery(g.entityName).Ancestor(userKey).Order("-DateCreated")
+	tokens := []AccessToken{}
+	keys, err := g.client.GetAll(ctx, query, &tokens)
+	if err != nil {
+		return []AccessToken{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	for i, key := range keys {
+		tokens[i].ID = key.Name
+	}
+	return tokens, nil
+}
+
+func (g *googleDatastore) Update(ctx context.Context, userID, ID string, setters ...func(*AccessToken) error) (AccessToken, error) {
+	userKey := datastore.NameKey(g.userEntityName, userID, nil)
+	key := datastore.NameKey(g.entityName, ID, userKey)
+	t := AccessToken{}
+	_, err := g.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(key, &t); err != nil {
+			return fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+		}
+		t.ID = ID
+		for _, setFunc := range setters {
+			err := setFunc(&t)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := tx.Put(key, &t)
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,73 @@
+package accesstoken
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e AccessToken) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, userID, ID string) (AccessToken, error) {
+	t := AccessToken{}
+	result := m.db.Where("id = ? AND user_id = ?", ID, userID).First(&t)
+	if result.Error != nil {
+		return t, result.Error
+	}
+	return t, nil
+}
+
+func (m mysql) GetByHash(ctx context.Context, hash string) (AccessToken, error) {
+	t := AccessToken{}
+	result := m.db.Where("hash = ?", hash).First(&t)
+	if result.Error != nil {
+		return t, result.Error
+	}
+	return t, nil
+}
+
+func (m mysql) GetAll(ctx context.Context, userID string) ([]AccessToken, error) {
+	var tokens []AccessToken
+	result := m.db.Where("user_id = ?", userID).Order("date_created desc").Find(&tokens)
+	if result.Error != nil {
+		return []AccessToken{}, result.Error
+	}
+	return tokens, nil
+}
+
+func (m mysql) Update(ctx context.Context, userID, ID string, setters ...func(*AccessToken) error) (AccessToken, error) {
+	t, err := m.Get(ctx, userID, ID)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	for _, s := range setters {
+		err := s(&t)
+		if err != nil {
+			return AccessToken{}, err
+		}
+	}
+	result := m.db.Save(&t)
+	if result.Error != nil {
+		return AccessToken{}, result.Error
+	}
+	return t, nil
+}
@@ -0,0 +1,13 @@
+package accesstoken
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e AccessToken) error
+	Get(ctx context.Context, userID, ID string) (AccessToken, error)
+	// GetByHash retrieves the token that is provided in a request. Requests only carry the token
+	GetByHash(ctx context.Context, hash string) (AccessToken, error)
+	// GetAll retrieves the tokens of the user. Tokens are sorted from the latest token
+	GetAll(ctx context.Context, userID string) ([]AccessToken, error)
+	Update(ctx context.Context, userID, ID string, setters ...func(*AccessToken) error) (AccessToken, error)
+}
@@ -4,52 +4,50 @@
 package acl
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid"
 )
 
-type permission string
+// Permission is the role of a user on a project
+type Permission string
 
 // Anonymous permission to resources - applied on project level
-// Creates a temporary user on system. User does not need to login
-// A temporary user with expiry for this user - only can access certain features
-// Similar to reader
-var Anonymous permission = "anonymous"
+// Held by viewers that open a share link of the project. Viewers do not need to login
+// and their access ends when the share link expires or is revoked
+// Similar to reader. Anonymous permission cannot be granted to users
+var Anonymous Permission = "anonymous"
 
 // Reader permission to resources - applied on project level
 // Able to access projects on the platform and can read but is unable
 // to alter anything on it
 // Can view scripts and videos but all buttons will be grayed out
-var Reader permission = "reader"
+var Reader Permission = "reader"
 
 // Editor permission to resource - applied on project level
 // Able to alter the following:
 // - Edit scripts
 // - Regenerate video segments
-var Editor permission = "editor"
+// - Concatenate video segments into videos
+var Editor Permission = "editor"
 
 // Publisher permission to resource - applied on project level
 // This permission is needed to provide some sort of granularity to
 // publish video to a video publishing platform
-var Publisher permission = "publisher"
+var Publisher Permission = "publisher"
 
 // Owner permission to resource - applied on project level
-var Owner permission = "owner"
-
-func (p permission) Int() int {
-	switch p {
-	case Anonymous:
-		return 0
-	case Reader:
-		return 1
-	case Editor:
-		return 2
-	case Owner:
-		return 3
-	default:
-		return -1
+// Owners are able to do everything on the project including sharing and deleting it
+var Owner Permission = "owner"
+
+// ParsePermission converts the name of a permission into a permission
+func ParsePermission(raw string) (Permission, error) {
+	p := Permission(raw)
+	if _, ok := roles[p]; !ok {
+		return p, fmt.Errorf("invalid permission %v", raw)
 	}
+	return p, nil
 }
 
 // ACL - manage permission model of handling project resources
@@ -57,9 +55,15 @@ type ACL struct {
 	ID           string     `json:"id"`
 	ProjectID    string     `json:"project_id"`
 	UserID       string     `json:"user_id"`
-	Permission   permission `json:"permission"`
+	Permission   Permission `json:"permission"`
 	DateCreated  time.Time  `json:"date_created"`
 	DateModified time.Time  `json:"date_modified"`
+	// InheritedFrom is the folder that granted this permission. Empty if the permission
+	// was granted on the project directly
+	InheritedFrom string `json:"inherited_from,omitempty" gorm:"type:varchar(40)"`
+	// TeamID is set for acls whose principal is a team instead of a user. Members of the team
+	// inherit its permission. It is also set on the acl resolved for a member of the team
+	TeamID string `json:"team_id,omitempty" gorm:"type:varchar(40)"`
 }
 
 func New(projectID, userID string) ACL {
@@ -74,12 +78,48 @@ func New(projectID, userID string) ACL {
 	}
 }
 
-func (a ACL) IsAuthorized(minPermission permission) bool {
-	if a.Permission == Owner {
-		return true
-	}
-	if a.Permission.Int() >= minPermission.Int() {
-		return true
+// NewInherited creates the acl for a user that is granted access to a project
+// through the shares of a folder that contains the project
+func NewInherited(projectID, userID, folderID string, p Permission) ACL {
+	newACL := New(projectID, userID)
+	newACL.Permission = p
+	newACL.InheritedFrom = folderID
+	return newACL
+}
+
+// NewTeam creates the acl for a team that is granted access to a project
+func NewTeam(projectID, teamID string, p Permission) ACL {
+	newACL := New(projectID, "")
+	newACL.Permission = p
+	newACL.TeamID = teamID
+	return newACL
+}
+
+// NewAnonymous is the acl of a viewer that opens a share link of the project without an account
+// It is not stored as the access of the viewer is held by the share link
+func NewAnonymous(projectID string) ACL {
+	return ACL{
+		ProjectID:    projectID,
+		Permission:   Anonymous,
+		DateCreated:  time.Now(),
+		DateModified: time.Now(),
 	}
-	return false
+}
+
+// Clone copies the permission of the user or team over to another project
+func (a ACL) Clone(projectID string) ACL {
+	newACL := New(projectID, a.UserID)
+	newACL.Permission = a.Permission
+	newACL.TeamID = a.TeamID
+	return newACL
+}
+
+// IsTeam checks if the principal of the acl is a team
+func (a ACL) IsTeam() bool {
+	return a.UserID == "" && a.TeamID != ""
+}
+
+// IsAuthorized checks if the role of the user on the project grants the capability
+func (a ACL) IsAuthorized(c Capability) bool {
+	return a.Permission.Can(c)
 }
@@ -13,12 +13,12 @@ func TestACL_IsAuthorized(t *testing.T) {
 		ID           string
 		ProjectID    string
 		UserID       string
-		Permission   permission
+		Permission   Permission
 		DateCreated  time.Time
 		DateModified time.Time
 	}
 	type args struct {
-		p permission
+		c Capability
 	}
 	tests := []struct {
 		name   string
@@ -26,46 +26,17 @@ func TestACL_IsAuthorized(t *testing.T) {
 		args   args
 		want   bool
 	}{
-		{
-			name: "editor but need reader",
-			fields: fields{
-				Permission: Editor,
-			},
-			args: args{
-				Reader,
-			},
-			want: true,
-		},
-		{
-			name: "owner but need reader",
-			fields: fields{
-				Permission: Owner,
-			},
-			args: args{
-				Reader,
-			},
-			want: true,
-		},
-		{
-			name: "reader but need editor",
-			fields: fields{
-				Permission: Reader,
-			},
-			args: args{
-				Editor,
-			},
-			want: false,
-		},
-		{
-			name: "anonymous but need reader",
-			fields: fields{
-				Permission: Anonymous,
-			},
-			args: args{
-				Reader,
-			},
-			want: false,
-		},
+		{name: "editor but need view", fields: fields{Permission: Editor}, args: args{View}, want: true},
+		{name: "owner but need view", fields: fields{Permission: Owner}, args: args{View}, want: true},
+		{name: "reader but need edit script", fields: fields{Permission: Reader}, args: args{EditScript}, want: false},
+		{name: "anonymous but need view", fields: fields{Permission: Anonymous}, args: args{View}, want: true},
+		{name: "anonymous but need generate", fields: fields{Permission: Anonymous}, args: args{Generate}, want: false},
+		{name: "editor but need publish", fields: fields{Permission: Editor}, args: args{Publish}, want: false},
+		{name: "publisher but need publish", fields: fields{Permission: Publisher}, args: args{Publish}, want: true},
+		{name: "publisher but need concat", fields: fields{Permission: Publisher}, args: args{Concat}, want: true},
+		{name: "publisher but need share", fields: fields{Permission: Publisher}, args: args{Share}, want: false},
+		{name: "owner but need delete", fields: fields{Permission: Owner}, args: args{Delete}, want: true},
+		{name: "unknown role", fields: fields{Permission: "removed-role"}, args: args{View}, want: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -77,9 +48,84 @@ func TestACL_IsAuthorized(t *testing.T) {
 				DateCreated:  tt.fields.DateCreated,
 				DateModified: tt.fields.DateModified,
 			}
-			if got := a.IsAuthorized(tt.args.p); got != tt.want {
+			if got := a.IsAuthorized(tt.args.c); got != tt.want {
 				t.Errorf("ACL.IsAuthorized() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestCheckOwnerRemains(t *testing.T) {
+	owner := New("project", "owner")
+	coOwner := New("project", "co-owner")
+	editor := New("project", "editor")
+	editor.Permission = Editor
+	folderOwner := NewInherited("project", "folder-owner", "folder", Owner)
+	teamOwner := NewTeam("project", "team", Owner)
+
+	tests := []struct {
+		name          string
+		acls          []ACL
+		userID        string
+		newPermission Permission
+		wantErr       bool
+	}{
+		{name: "demote last owner", acls: []ACL{owner, editor}, userID: "owner", newPermission: Editor, wantErr: true},
+		{name: "remove last owner", acls: []ACL{owner, editor}, userID: "owner", newPermission: "", wantErr: true},
+		{name: "last owner with folder owner", acls: []ACL{owner, folderOwner}, userID: "owner", newPermission: "", wantErr: true},
+		{name: "demote with co-owner", acls: []ACL{owner, coOwner}, userID: "owner", newPermission: Reader},
+		{name: "remove editor", acls: []ACL{owner, editor}, userID: "editor", newPermission: ""},
+		{name: "keep owner", acls: []ACL{owner}, userID: "owner", newPermission: Owner},
+		{name: "remove owner with team owner", acls: []ACL{owner, teamOwner}, userID: "owner", newPermission: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckOwnerRemains(tt.acls, tt.userID, tt.newPermission); (err != nil) != tt.wantErr {
+				t.Errorf("CheckOwnerRemains() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckTeamOwnerRemains(t *testing.T) {
+	owner := New("project", "owner")
+	teamOwner := NewTeam("project", "team", Owner)
+	teamReader := NewTeam("project", "other-team", Reader)
+
+	tests := []struct {
+		name          string
+		acls          []ACL
+		teamID        string
+		newPermission Permission
+		wantErr       bool
+	}{
+		{name: "remove last owner", acls: []ACL{teamOwner, teamReader}, teamID: "team", newPermission: "", wantErr: true},
+		{name: "demote with user owner", acls: []ACL{owner, teamOwner}, teamID: "team", newPermission: Editor},
+		{name: "remove reader team", acls: []ACL{teamOwner, teamReader}, teamID: "other-team", newPermission: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckTeamOwnerRemains(tt.acls, tt.teamID, tt.newPermission); (err != nil) != tt.wantErr {
+				t.Errorf("CheckTeamOwnerRemains() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetPermission(t *testing.T) {
+	a := NewInherited("project", "user", "folder", Reader)
+	setters, err := SetPermission("editor")
+	if err != nil {
+		t.Fatalf("unexpected error when setting permission. Err: %v", err)
+	}
+	setters[0](&a)
+	if a.Permission != Editor || a.InheritedFrom != "" {
+		t.Errorf("expected permission to be granted on the project directly. ACL: %+v", a)
+	}
+	if _, err = SetPermission("anonymous"); err == nil {
+		t.Errorf("expected error when granting anonymous permission")
+	}
+	if _, err = SetPermission("admin"); err == nil {
+		t.Errorf("expected error for unknown permission")
+	}
+}
@@ -27,4 +27,43 @@ func common_ops(t *testing.T, aclDB Store) {
 	if tACL.ProjectID != "1" || tACL.UserID != "1111" {
 		t.Fatalf("bad acl pulled from store. Expected %+v. Actual %v", acl1, tACL)
 	}
+
+	setters, _ := SetPermission("reader")
+	tACL, err = aclDB.Update(context.TODO(), "1", "1111", setters...)
+	if err != nil {
+		t.Fatalf("unable to update data in store. Err: %v", err)
+	}
+	if tACL.Permission != Reader {
+		t.Fatalf("expected permission to be updated. Actual %v", tACL.Permission)
+	}
+
+	err = aclDB.Delete(context.TODO(), "1", "1111")
+	if err != nil {
+		t.Fatalf("unable to delete data from store. Err: %v", err)
+	}
+	_, err = aclDB.Get(context.TODO(), "1", "1111")
+	if err == nil {
+		t.Fatalf("expected acl to be removed from store")
+	}
+
+	aclDB.Create(context.TODO(), NewTeam("2", "team-1", Editor))
+	aclDB.Create(context.TODO(), NewTeam("2", "team-2", Reader))
+	teamACLs, err := aclDB.GetForTeams(context.TODO(), "2", []string{"team-1"})
+	if err != nil {
+		t.Fatalf("unable to pull team acls from store. Err: %v", err)
+	}
+	if len(teamACLs) != 1 || teamACLs[0].TeamID != "team-1" || teamACLs[0].Permission != Editor {
+		t.Fatalf("bad team acls pulled from store. Actual %+v", teamACLs)
+	}
+	err = aclDB.DeleteTeam(context.TODO(), "2", "team-1")
+	if err != nil {
+		t.Fatalf("unable to delete team acl from store. Err: %v", err)
+	}
+	teamACLs, _ = aclDB.GetForTeams(context.TODO(), "2", []string{"team-1", "team-2"})
+	if len(teamACLs) != 1 || teamACLs[0].TeamID != "team-2" {
+		t.Fatalf("expected only acl of team-2 to remain. Actual %+v", teamACLs)
+	}
+	if _, err = aclDB.Get(context.TODO(), "2", "1111"); err != nil {
+		t.Fatalf("expected acl of user to remain. Err: %v", err)
+	}
 }
@@ -57,16 +57,77 @@ func (g *googleDatastore) GetAll(ctx context.Context, ProjectID string, Limit, A
 	if err != nil {
 		return []ACL{}, err
 	}
-	if len(acls) == 0 || len(acls) > 1 {
-		return []ACL{}, fmt.Errorf("bad query to get acl for project id and user id combination")
-	}
 	return acls, nil
 }
 
 func (g *googleDatastore) Update(ctx context.Context, ProjectID, UserID string, setters ...func(*ACL) error) (ACL, error) {
-	return ACL{}, nil
+	query := datastore.NewQuery(g.entityName).Filter("ProjectID =", ProjectID).Filter("UserID =", UserID).KeysOnly()
+	keys, err := g.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return ACL{}, err
+	}
+	if len(keys) != 1 {
+		return ACL{}, fmt.Errorf("no records found")
+	}
+	a := ACL{}
+	_, err = g.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(keys[0], &a); err != nil {
+			return fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+		}
+		for _, setFunc := range setters {
+			err := setFunc(&a)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := tx.Put(keys[0], &a)
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return ACL{}, err
+	}
+	return a, nil
 }
 
 func (g *googleDatastore) Delete(ctx context.Context, ProjectID, UserID string) error {
+	query := datastore.NewQuery(g.entityName).Filter("ProjectID =", ProjectID).Filter("UserID =", UserID).KeysOnly()
+	keys, err := g.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return err
+	}
+	err = g.client.DeleteMulti(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("unable to delete acl. err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) GetForTeams(ctx context.Context, ProjectID string, TeamIDs []string) ([]ACL, error) {
+	acls := []ACL{}
+	for _, teamID := range TeamIDs {
+		query := datastore.NewQuery(g.entityName).Filter("ProjectID =", ProjectID).Filter("UserID =", "").Filter("TeamID =", teamID)
+		teamACLs := []ACL{}
+		_, err := g.client.GetAll(ctx, query, &teamACLs)
+		if err != nil {
+			return []ACL{}, err
+		}
+		acls = append(acls, teamACLs...)
+	}
+	return acls, nil
+}
+
+func (g *googleDatastore) DeleteTeam(ctx context.Context, ProjectID, TeamID string) error {
+	query := datastore.NewQuery(g.entityName).Filter("ProjectID =", ProjectID).Filter("UserID =", "").Filter("TeamID =", TeamID).KeysOnly()
+	keys, err := g.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return err
+	}
+	err = g.client.DeleteMulti(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("unable to delete acl. err: %v", err)
+	}
 	return nil
 }
@@ -71,3 +71,23 @@ func (m mysql) Delete(ctx context.Context, ProjectID, UserID string) error {
 	}
 	return nil
 }
+
+func (m mysql) GetForTeams(ctx context.Context, ProjectID string, TeamIDs []string) ([]ACL, error) {
+	var acls []ACL
+	if len(TeamIDs) == 0 {
+		return acls, nil
+	}
+	result := m.db.Where("project_id = ? AND user_id = ? AND team_id IN (?)", ProjectID, "", TeamIDs).Find(&acls)
+	if result.Error != nil {
+		return []ACL{}, result.Error
+	}
+	return acls, nil
+}
+
+func (m mysql) DeleteTeam(ctx context.Context, ProjectID, TeamID string) error {
+	result := m.db.Where("project_id = ? AND user_id = ? AND team_id = ?", ProjectID, "", TeamID).Delete(ACL{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capability is an action on a project that a role may allow
+type Capability string
+
+var (
+	// View the project along with its scripts, videos and collaborators
+	View Capability = "view"
+	// EditScript covers changes to the content of the project such as its scripts, slides,
+	// settings and music
+	EditScript Capability = "edit-script"
+	// Generate the videos of the video segments
+	Generate Capability = "generate"
+	// Concat the videos of the video segments into the videos of the project and its video outputs
+	Concat Capability = "concat"
+	// Publish versions of the videos of the project
+	Publish Capability = "publish"
+	// Share the project with other users and manage their roles
+	Share Capability = "share"
+	// Delete and restore the project
+	Delete Capability = "delete"
+)
+
+var capabilities = []Capability{View, EditScript, Generate, Concat, Publish, Share, Delete}
+
+// roles holds the capabilities of the built in roles and the custom roles registered on startup
+var roles = map[Permission][]Capability{
+	Anonymous: {View},
+	Reader:    {View},
+	Editor:    {View, EditScript, Generate, Concat},
+	Publisher: {View, EditScript, Generate, Concat, Publish},
+	Owner:     capabilities,
+}
+
+var builtInRoles = map[Permission]bool{
+	Anonymous: true,
+	Reader:    true,
+	Editor:    true,
+	Publisher: true,
+	Owner:     true,
+}
+
+// RegisterRole adds a custom role with the capabilities provided. Custom roles are defined
+// in the configuration and are to be registered on startup before any requests are served
+// Built in roles cannot be redefined and every role needs to be able to view the project
+func RegisterRole(name string, capabilityNames []string) error {
+	p := Permission(name)
+	if name == "" || strings.TrimSpace(strings.ToLower(name)) != name {
+		return fmt.Errorf("role name %q needs to be lowercase without surrounding spaces", name)
+	}
+	if builtInRoles[p] {
+		return fmt.Errorf("built in role %v cannot be redefined", name)
+	}
+	parsed := []Capability{}
+	canView := false
+	for _, raw := range capabilityNames {
+		c, err := ParseCapability(raw)
+		if err != nil {
+			return fmt.Errorf("invalid role %v. %v", name, err)
+		}
+		canView = canView || c == View
+		parsed = append(parsed, c)
+	}
+	if !canView {
+		return fmt.Errorf("role %v needs the %v capability", name, View)
+	}
+	roles[p] = parsed
+	return nil
+}
+
+// ParseCapability converts the name of a capability into a capability
+func ParseCapability(raw string) (Capability, error) {
+	for _, c := range capabilities {
+		if string(c) == raw {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("invalid capability %v", raw)
+}
+
+// Capabilities lists what the role allows. Unknown roles, such as custom roles that are
+// no longer configured, allow nothing
+func (p Permission) Capabilities() []Capability {
+	return roles[p]
+}
+
+// Can checks if the role allows the capability
+func (p Permission) Can(c Capability) bool {
+	for _, roleCapability := range roles[p] {
+		if roleCapability == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Includes checks if the role allows everything that the other role allows
+func (p Permission) Includes(other Permission) bool {
+	for _, c := range other.Capabilities() {
+		if !p.Can(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Stronger checks if the role grants at least as much as the other role. Roles that do not
+// include one another are compared by the number of capabilities they allow
+func (p Permission) Stronger(other Permission) bool {
+	if p.Includes(other) {
+		return true
+	}
+	if other.Includes(p) {
+		return false
+	}
+	return len(p.Capabilities()) >= len(other.Capabilities())
+}
+
+// MigratePermission maps the role stored on existing acls to a role of the capability
+// based model. Roles are matched regardless of case and surrounding spaces. Roles that are
+// no longer known are reduced to reader so that users keep read access without being
+// granted more than before. The bool is true if the stored role needs to be changed
+func MigratePermission(raw string) (Permission, bool) {
+	normalized := Permission(strings.ToLower(strings.TrimSpace(raw)))
+	if _, ok := roles[normalized]; !ok {
+		return Reader, true
+	}
+	return normalized, string(normalized) != raw
+}
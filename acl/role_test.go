@@ -0,0 +1,62 @@
+package acl
+
+import "testing"
+
+func TestRegisterRole(t *testing.T) {
+	defer delete(roles, "reviewer")
+
+	tests := []struct {
+		name         string
+		role         string
+		capabilities []string
+		wantErr      bool
+	}{
+		{name: "custom role", role: "reviewer", capabilities: []string{"view", "publish"}},
+		{name: "built in role", role: "editor", capabilities: []string{"view"}, wantErr: true},
+		{name: "unknown capability", role: "approver", capabilities: []string{"view", "approve"}, wantErr: true},
+		{name: "unable to view", role: "approver", capabilities: []string{"publish"}, wantErr: true},
+		{name: "uppercase name", role: "Approver", capabilities: []string{"view"}, wantErr: true},
+		{name: "empty name", role: "", capabilities: []string{"view"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterRole(tt.role, tt.capabilities); (err != nil) != tt.wantErr {
+				t.Errorf("RegisterRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	reviewer, err := ParsePermission("reviewer")
+	if err != nil {
+		t.Fatalf("expected custom role to be parsed. Err: %v", err)
+	}
+	if !reviewer.Can(Publish) || reviewer.Can(EditScript) {
+		t.Errorf("unexpected capabilities of custom role. %v", reviewer.Capabilities())
+	}
+	if !Publisher.Includes(reviewer) || reviewer.Includes(Publisher) {
+		t.Errorf("expected publisher to include the reviewer role only")
+	}
+}
+
+func TestMigratePermission(t *testing.T) {
+	tests := []struct {
+		raw         string
+		want        Permission
+		wantChanged bool
+	}{
+		{raw: "owner", want: Owner},
+		{raw: "publisher", want: Publisher},
+		{raw: " Editor ", want: Editor, wantChanged: true},
+		{raw: "READER", want: Reader, wantChanged: true},
+		{raw: "", want: Reader, wantChanged: true},
+		{raw: "admin", want: Reader, wantChanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, changed := MigratePermission(tt.raw)
+			if got != tt.want || changed != tt.wantChanged {
+				t.Errorf("MigratePermission() = %v %v, want %v %v", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
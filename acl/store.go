@@ -1,11 +1,88 @@
 package acl
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 type Store interface {
 	Create(ctx context.Context, e ACL) error
 	Get(ctx context.Context, ProjectID, UserID string) (ACL, error)
-	// GetAll(ctx context.Context, ProjectID string, Limit, After int) ([]ACL, error)
-	// Update(ctx context.Context, ProjectID, UserID string, setters ...func(*ACL) error) (ACL, error)
-	// Delete(ctx context.Context, ProjectID, UserID string) error
+	GetAll(ctx context.Context, ProjectID string, Limit, After int) ([]ACL, error)
+	Update(ctx context.Context, ProjectID, UserID string, setters ...func(*ACL) error) (ACL, error)
+	Delete(ctx context.Context, ProjectID, UserID string) error
+	// GetForTeams retrieves the acls of the teams on the project
+	GetForTeams(ctx context.Context, ProjectID string, TeamIDs []string) ([]ACL, error)
+	DeleteTeam(ctx context.Context, ProjectID, TeamID string) error
+}
+
+// ErrLastOwner is returned when a change would leave the project without an owner
+var ErrLastOwner = fmt.Errorf("project needs to have at least one owner")
+
+// SetPermission changes the permission of the user on the project
+// The permission is then held on the project directly and is no longer inherited from a folder
+func SetPermission(raw string) ([]func(*ACL) error, error) {
+	p, err := ParsePermission(raw)
+	if err != nil {
+		return nil, err
+	}
+	if p == Anonymous {
+		return nil, fmt.Errorf("anonymous permission cannot be granted to a user")
+	}
+	var setters []func(*ACL) error
+	setters = append(setters, func(a *ACL) error {
+		a.Permission = p
+		a.InheritedFrom = ""
+		a.DateModified = time.Now()
+		return nil
+	})
+	return setters, nil
+}
+
+// SetInherited changes the permission that the user inherits on the project from the folder
+// Permissions granted on the project directly are left as they are
+func SetInherited(folderID string, p Permission) []func(*ACL) error {
+	var setters []func(*ACL) error
+	setters = append(setters, func(a *ACL) error {
+		if a.InheritedFrom == "" {
+			return nil
+		}
+		a.Permission = p
+		a.InheritedFrom = folderID
+		a.DateModified = time.Now()
+		return nil
+	})
+	return setters
+}
+
+// CheckOwnerRemains ensures that the project still has an owner if the user is changed to
+// the new permission. An empty permission means that the user is removed from the project
+// Owners inherited from folders are not counted as they go away once the project is moved
+// Teams that own the project are counted as owners
+func CheckOwnerRemains(acls []ACL, userID string, newPermission Permission) error {
+	return checkOwnerRemains(acls, func(a ACL) bool { return !a.IsTeam() && a.UserID == userID }, newPermission)
+}
+
+// CheckTeamOwnerRemains ensures that the project still has an owner if the team is changed
+// to the new permission. An empty permission means that the team is removed from the project
+func CheckTeamOwnerRemains(acls []ACL, teamID string, newPermission Permission) error {
+	return checkOwnerRemains(acls, func(a ACL) bool { return a.IsTeam() && a.TeamID == teamID }, newPermission)
+}
+
+func checkOwnerRemains(acls []ACL, isChanged func(ACL) bool, newPermission Permission) error {
+	if newPermission == Owner {
+		return nil
+	}
+	for _, a := range acls {
+		if !isChanged(a) && a.Permission == Owner && a.InheritedFrom == "" {
+			return nil
+		}
+	}
+	for _, a := range acls {
+		if isChanged(a) && a.Permission == Owner && a.InheritedFrom == "" {
+			return ErrLastOwner
+		}
+	}
+	return nil
 }
@@ -1,8 +1,32 @@
 package blobstorage
 
-import "context"
+import (
+	"context"
+	"path"
+	"strings"
+)
 
 type BlobStorage interface {
 	Save(ctx context.Context, fileName string, content []byte) error
 	Load(ctx context.Context, fileName string) (content []byte, err error)
+	Delete(ctx context.Context, fileName string) error
+}
+
+// maxExtLength is the longest extension kept by Ext, including the dot
+const maxExtLength = 6
+
+// Ext returns the extension of the file name in lower case. Extensions that are too long or
+// that contain anything other than letters and digits are dropped so that new file names
+// can be safely derived from file names that came from users
+func Ext(fileName string) string {
+	ext := strings.ToLower(path.Ext(fileName))
+	if len(ext) < 2 || len(ext) > maxExtLength {
+		return ""
+	}
+	for _, c := range ext[1:] {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ext
 }
@@ -56,3 +56,11 @@ func (b GCSStorage) Load(ctx context.Context, fileName string) (content []byte,
 
 	return content, nil
 }
+
+func (b GCSStorage) Delete(ctx context.Context, fileName string) error {
+	err := b.Client.Bucket(b.BucketName).Object(fileName).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to delete file. Bucket Name: %v, File Name: %v, Error: %v", b.BucketName, fileName, err)
+	}
+	return nil
+}
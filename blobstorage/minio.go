@@ -53,3 +53,10 @@ func (b Minio) Load(ctx context.Context, fileName string) (content []byte, err e
 	}
 	return rawData, nil
 }
+
+func (b Minio) Delete(ctx context.Context, fileName string) error {
+	if b.Client == nil {
+		return fmt.Errorf("S3 Client not initialized")
+	}
+	return b.Client.RemoveObject(ctx, b.BucketName, fileName, minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+)
+
+// ExportProject retrieves the zip archive of the project from the manager
+func (c *Client) ExportProject(projectID string) ([]byte, error) {
+	endpoint := c.mgrURL + "/api/v1/project/" + projectID + ":export"
+	req, err := c.newRequest("GET", endpoint, nil)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Unable to create export request. Err: %v", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Unable to export project from api endpoint. Err: %v", err)
+	}
+	defer resp.Body.Close()
+	rawArchive, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []byte{}, fmt.Errorf("Unable to retrieve read data")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return []byte{}, fmt.Errorf("Unable to export project. Resp: %v", string(rawArchive))
+	}
+	return rawArchive, nil
+}
+
+// ImportProject sends the zip archive over to the manager which would recreate the project
+// under the ownership of the user that the auth token belongs to
+func (c *Client) ImportProject(archive []byte) (project.Project, error) {
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	fw, err := mw.CreateFormFile("myfile", "project.zip")
+	if err != nil {
+		return project.Project{}, fmt.Errorf("Unable to create import request. Err: %v", err)
+	}
+	fw.Write(archive)
+	mw.Close()
+
+	endpoint := c.mgrURL + "/api/v1/project:import"
+	req, err := c.newRequest("POST", endpoint, b.Bytes())
+	if err != nil {
+		return project.Project{}, fmt.Errorf("Unable to create import request. Err: %v", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return project.Project{}, fmt.Errorf("Unable to import project to api endpoint. Err: %v", err)
+	}
+	defer resp.Body.Close()
+	rawProject, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return project.Project{}, fmt.Errorf("Unable to retrieve read data")
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return project.Project{}, fmt.Errorf("Unable to import project. Resp: %v", string(rawProject))
+	}
+	respProject := project.Project{}
+	err = json.Unmarshal(rawProject, &respProject)
+	if err != nil {
+		return project.Project{}, fmt.Errorf("Unable to marshall response")
+	}
+	return respProject, nil
+}
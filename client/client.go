@@ -2,6 +2,8 @@
 package client
 
 import (
+	"bytes"
+	"io"
 	"net/http"
 
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
@@ -9,6 +11,7 @@ import (
 
 type Client struct {
 	mgrURL     string
+	authToken  string
 	httpClient *http.Client
 	logger     logger.Logger
 }
@@ -20,3 +23,27 @@ func NewClient(mgrURL string, httpClient *http.Client, logger logger.Logger) *Cl
 		logger:     logger,
 	}
 }
+
+// SetAuthToken sets the token that would be passed in the Authorization header
+// for all subsequent calls to the manager
+func (c *Client) SetAuthToken(token string) {
+	c.authToken = token
+}
+
+func (c *Client) newRequest(method, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytesReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+	return req, nil
+}
+
+func bytesReader(body []byte) io.Reader {
+	if body == nil {
+		return nil
+	}
+	return bytes.NewReader(body)
+}
@@ -93,18 +93,83 @@ func (b basic) FailedTask(ctx context.Context, authToken, projectID, idemKey str
 	return nil
 }
 
-func (b basic) CompleteTask(ctx context.Context, authToken, projectID, idemKey, videoOutputID string) error {
+func (b basic) CompleteTask(ctx context.Context, authToken, projectID, idemKey, videoOutputID string, videoSegments []string) error {
 	endpoint := b.baseEndpoint + "/project/" + projectID
 	type updateInput struct {
-		Status             string `json:"status"`
-		VideoOutputID      string `json:"video_output_id"`
-		IdemKeyCompleteRec string `json:"idem_key_complete_rec"`
+		Status             string   `json:"status"`
+		VideoOutputID      string   `json:"video_output_id"`
+		VideoSegments      []string `json:"video_segments"`
+		IdemKeyCompleteRec string   `json:"idem_key_complete_rec"`
 	}
 	updateInputReq := updateInput{
 		Status:             "completed",
 		VideoOutputID:      videoOutputID,
+		VideoSegments:      videoSegments,
+		IdemKeyCompleteRec: idemKey,
+	}
+	rawUpdateInputReq, err := json.Marshal(updateInputReq)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", endpoint, bytes.NewBuffer(rawUpdateInputReq))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Authorization", authToken)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	rawResp, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("issue with updating. %v", string(rawResp))
+	}
+
+	return nil
+}
+
+func (b basic) UpdateOutputRunning(ctx context.Context, authToken, projectID, outputID, idemKey string) error {
+	type updateInput struct {
+		Status            string `json:"status"`
+		IdemKeySetRunning string `json:"idem_key_running"`
+	}
+	return b.updateOutput(ctx, authToken, projectID, outputID, updateInput{
+		Status:            "running",
+		IdemKeySetRunning: idemKey,
+	})
+}
+
+func (b basic) FailedOutputTask(ctx context.Context, authToken, projectID, outputID, idemKey string) error {
+	type updateInput struct {
+		Status             string `json:"status"`
+		IdemKeyCompleteRec string `json:"idem_key_complete_rec"`
+	}
+	return b.updateOutput(ctx, authToken, projectID, outputID, updateInput{
+		Status:             "error",
 		IdemKeyCompleteRec: idemKey,
+	})
+}
+
+func (b basic) CompleteOutputTask(ctx context.Context, authToken, projectID, outputID, idemKey, videoFile string, videoSegments []string) error {
+	type updateInput struct {
+		Status             string   `json:"status"`
+		OutputID           string   `json:"output_id"`
+		VideoSegments      []string `json:"video_segments"`
+		IdemKeyCompleteRec string   `json:"idem_key_complete_rec"`
 	}
+	return b.updateOutput(ctx, authToken, projectID, outputID, updateInput{
+		Status:             "completed",
+		OutputID:           videoFile,
+		VideoSegments:      videoSegments,
+		IdemKeyCompleteRec: idemKey,
+	})
+}
+
+func (b basic) updateOutput(ctx context.Context, authToken, projectID, outputID string, updateInputReq interface{}) error {
+	endpoint := b.baseEndpoint + "/project/" + projectID + "/videooutput/" + outputID
 	rawUpdateInputReq, err := json.Marshal(updateInputReq)
 	if err != nil {
 		return err
@@ -5,5 +5,9 @@ import "context"
 type Client interface {
 	UpdateRunning(ctx context.Context, authToken, projectID, idemKey string) error
 	FailedTask(ctx context.Context, authToken, projectID, idemKey string) error
-	CompleteTask(ctx context.Context, authToken, projectID, idemKey, videoOutputID string) error
+	CompleteTask(ctx context.Context, authToken, projectID, idemKey, videoOutputID string, videoSegments []string) error
+	// Video output of the project
+	UpdateOutputRunning(ctx context.Context, authToken, projectID, outputID, idemKey string) error
+	FailedOutputTask(ctx context.Context, authToken, projectID, outputID, idemKey string) error
+	CompleteOutputTask(ctx context.Context, authToken, projectID, outputID, idemKey, videoFile string, videoSegments []string) error
 }
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/cmd/concatenate-video/mgrclient"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/subtitles"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -37,20 +40,20 @@ func (h *Basic) Process(ctx context.Context, job JobDetails) error {
 		h.logger.Errorf("Error validating for struct. Err: %v", err)
 		return err
 	}
-	h.mgrClient.UpdateRunning(ctx, job.AuthToken, job.ID, job.RunningIdemKey)
+	h.updateRunning(ctx, job)
 
 	videosToBeCombined := ""
 	for _, videoID := range job.VideoIDs {
 		videoContent, err := h.blobStorage.Load(context.TODO(), videoID)
 		if err != nil {
-			h.mgrClient.FailedTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey)
+			h.failedTask(ctx, job)
 			return fmt.Errorf("Error while to download video. Error: %v. VideoID: %v", err, videoID)
 		}
 		defer os.Remove(videoID)
 
 		err = ioutil.WriteFile(videoID, videoContent, 777)
 		if err != nil {
-			h.mgrClient.FailedTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey)
+			h.failedTask(ctx, job)
 			return fmt.Errorf("Error while writing video content for specific video snippet file. Error: %v. VideoID: %v", err, videoID)
 		}
 		videosToBeCombined = videosToBeCombined + fmt.Sprintf("file %s\n", videoID)
@@ -58,29 +61,153 @@ func (h *Basic) Process(ctx context.Context, job JobDetails) error {
 
 	combinedVideoListFileName := fmt.Sprintf("combined_%s.txt", job.ID)
 	combinedVideoFileName := job.ID + ".mp4"
+	if job.OutputID != "" {
+		combinedVideoFileName = job.OutputID + ".mp4"
+	}
+	if job.OutputFileName != "" {
+		combinedVideoFileName = job.OutputFileName
+	}
 	h.logger.Infof("Videos to be combined: %v", videosToBeCombined)
 	err = ioutil.WriteFile(combinedVideoListFileName, []byte(videosToBeCombined), 777)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey)
+		h.failedTask(ctx, job)
 		return fmt.Errorf("Error while combining videos. Error: %v", err)
 	}
 	defer os.Remove(combinedVideoListFileName)
 
-	err = combineVideo(combinedVideoListFileName, combinedVideoFileName)
+	if job.Scale != "" || job.VideoBitrate != "" {
+		err = combineAndEncodeVideo(combinedVideoListFileName, combinedVideoFileName, job.Scale, job.VideoBitrate)
+	} else {
+		err = combineVideo(combinedVideoListFileName, combinedVideoFileName)
+	}
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey)
+		h.failedTask(ctx, job)
 		return fmt.Errorf("Error while combining videos. Error: %v", err)
 	}
 	defer os.Remove(combinedVideoFileName)
 
+	err = h.createSubtitles(ctx, job, combinedVideoFileName)
+	if err != nil {
+		h.failedTask(ctx, job)
+		return fmt.Errorf("Error while creating subtitles. Error: %v", err)
+	}
+
+	if job.Music != nil && job.Music.File != "" {
+		err = h.mixMusic(ctx, job, combinedVideoFileName)
+		if err != nil {
+			h.failedTask(ctx, job)
+			return fmt.Errorf("Error while mixing background music. Error: %v", err)
+		}
+	}
+
 	videoContent, err := ioutil.ReadFile(combinedVideoFileName)
 	err = h.blobStorage.Save(context.TODO(), combinedVideoFileName, videoContent)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey)
+		h.failedTask(ctx, job)
 		return fmt.Errorf("Error while combining videos. Error: %v", err)
 	}
 
-	h.mgrClient.CompleteTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey, combinedVideoFileName)
+	h.completeTask(ctx, job, combinedVideoFileName)
 
 	return nil
 }
+
+// createSubtitles saves the WebVTT and SRT subtitles of the combined video alongside it and
+// burns them into the combined video if required. Cues are timed with the duration of each
+// of the videos that were combined
+func (h *Basic) createSubtitles(ctx context.Context, job JobDetails, combinedVideoFileName string) error {
+	if len(job.Scripts) != len(job.VideoIDs) {
+		h.logger.Infof("No scripts provided for subtitles of %v", combinedVideoFileName)
+		return nil
+	}
+	clips := []subtitles.Clip{}
+	for i, videoID := range job.VideoIDs {
+		duration, err := getVideoDuration(videoID)
+		if err != nil {
+			return fmt.Errorf("Unable to get duration of video. Error: %v. VideoID: %v", err, videoID)
+		}
+		clips = append(clips, subtitles.Clip{
+			Script:   job.Scripts[i],
+			Duration: time.Duration(duration * float64(time.Second)),
+		})
+	}
+	// Narration of each video segment is padded with a second of silence on both ends
+	cues := subtitles.Build(clips, time.Second)
+	if len(cues) == 0 {
+		return nil
+	}
+
+	srtFileName := subtitles.FileName(combinedVideoFileName, subtitles.FormatSRT)
+	srtContent := subtitles.SRT(cues)
+	err := h.blobStorage.Save(ctx, srtFileName, srtContent)
+	if err != nil {
+		return err
+	}
+	err = h.blobStorage.Save(ctx, subtitles.FileName(combinedVideoFileName, subtitles.FormatVTT), subtitles.WebVTT(cues))
+	if err != nil {
+		return err
+	}
+	if !job.BurnSubtitles {
+		return nil
+	}
+
+	err = ioutil.WriteFile(srtFileName, srtContent, 777)
+	if err != nil {
+		return fmt.Errorf("Unable to write subtitles to file system. Error: %v", err)
+	}
+	defer os.Remove(srtFileName)
+	subtitledVideoFileName := "subtitled_" + combinedVideoFileName
+	err = burnSubtitles(combinedVideoFileName, srtFileName, subtitledVideoFileName)
+	if err != nil {
+		os.Remove(subtitledVideoFileName)
+		return err
+	}
+	return os.Rename(subtitledVideoFileName, combinedVideoFileName)
+}
+
+// mixMusic mixes the background music of the job under the narration of the combined video
+// The combined video is replaced with the mixed video
+func (h *Basic) mixMusic(ctx context.Context, job JobDetails, combinedVideoFileName string) error {
+	music := job.Music.WithDefaults()
+	musicContent, err := h.blobStorage.Load(ctx, music.File)
+	if err != nil {
+		return fmt.Errorf("Unable to download background music. Error: %v. File: %v", err, music.File)
+	}
+	musicFileName := "music_" + job.ID + filepath.Ext(music.File)
+	err = ioutil.WriteFile(musicFileName, musicContent, 777)
+	if err != nil {
+		return fmt.Errorf("Unable to write background music to file system. Error: %v", err)
+	}
+	defer os.Remove(musicFileName)
+
+	mixedVideoFileName := "mixed_" + combinedVideoFileName
+	err = mixBackgroundMusic(combinedVideoFileName, musicFileName, mixedVideoFileName, music)
+	if err != nil {
+		os.Remove(mixedVideoFileName)
+		return err
+	}
+	return os.Rename(mixedVideoFileName, combinedVideoFileName)
+}
+
+// updateRunning, failedTask and completeTask report the status to either the project or
+// the video output of the project depending on the job being processed
+func (h *Basic) updateRunning(ctx context.Context, job JobDetails) error {
+	if job.OutputID != "" {
+		return h.mgrClient.UpdateOutputRunning(ctx, job.AuthToken, job.ID, job.OutputID, job.RunningIdemKey)
+	}
+	return h.mgrClient.UpdateRunning(ctx, job.AuthToken, job.ID, job.RunningIdemKey)
+}
+
+func (h *Basic) failedTask(ctx context.Context, job JobDetails) error {
+	if job.OutputID != "" {
+		return h.mgrClient.FailedOutputTask(ctx, job.AuthToken, job.ID, job.OutputID, job.CompleteRecIdemKey)
+	}
+	return h.mgrClient.FailedTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey)
+}
+
+func (h *Basic) completeTask(ctx context.Context, job JobDetails, videoFile string) error {
+	if job.OutputID != "" {
+		return h.mgrClient.CompleteOutputTask(ctx, job.AuthToken, job.ID, job.OutputID, job.CompleteRecIdemKey, videoFile, job.VideoIDs)
+	}
+	return h.mgrClient.CompleteTask(ctx, job.AuthToken, job.ID, job.CompleteRecIdemKey, videoFile, job.VideoIDs)
+}
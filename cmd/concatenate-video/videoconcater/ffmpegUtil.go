@@ -2,10 +2,23 @@ package videoconcater
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 )
 
+type ffprobeFormatted struct {
+	Format videoDuration
+}
+
+type videoDuration struct {
+	Duration string
+}
+
 func combineVideo(videoListFile, combinedOutputVideoFile string) error {
 	cmd := exec.Command("ffmpeg", "-f", "concat", "-safe", "0", "-i", videoListFile, "-c", "copy", combinedOutputVideoFile)
 	var out bytes.Buffer
@@ -18,3 +31,119 @@ func combineVideo(videoListFile, combinedOutputVideoFile string) error {
 	}
 	return nil
 }
+
+// combineAndEncodeVideo concatenates the videos and re-encodes them at the required scale and video bitrate
+// Videos are padded to fit the scale so as to keep the aspect ratio of the slides
+func combineAndEncodeVideo(videoListFile, combinedOutputVideoFile, scale, videoBitrate string) error {
+	args := []string{"-f", "concat", "-safe", "0", "-i", videoListFile}
+	if scale != "" {
+		width, height := scale, scale
+		if parts := strings.Split(scale, ":"); len(parts) == 2 {
+			width, height = parts[0], parts[1]
+		}
+		args = append(args, "-vf", fmt.Sprintf("scale=%v:%v:force_original_aspect_ratio=decrease,pad=%v:%v:(ow-iw)/2:(oh-ih)/2", width, height, width, height))
+	}
+	args = append(args, "-c:v", "libx264")
+	if videoBitrate != "" {
+		args = append(args, "-b:v", videoBitrate)
+	}
+	args = append(args, "-c:a", "copy", combinedOutputVideoFile)
+	cmd := exec.Command("ffmpeg", args...)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("Error: %v, Stdout: %v, Stderr: %v", err, out.String(), stderr.String())
+	}
+	return nil
+}
+
+func getVideoDuration(filename string) (duration float64, err error) {
+	cmd := exec.Command("ffprobe", "-i", filename, "-show_entries", "format=duration", "-v", "quiet", "-of", "json")
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return 0.0, fmt.Errorf("Error %v %v", out.String(), stderr.String())
+	}
+	var ffprobe ffprobeFormatted
+	rawVideoProbe := out.Bytes()
+	json.Unmarshal(rawVideoProbe, &ffprobe)
+	val, err := strconv.ParseFloat(ffprobe.Format.Duration, 64)
+	if err != nil {
+		return 0.0, fmt.Errorf("Unable to parse the following value. %v %v %v", val, ffprobe, rawVideoProbe)
+	}
+	return val, nil
+}
+
+// musicFilter builds the ffmpeg filter graph that mixes the background music (second input)
+// under the narration of the video (first input). The music is looped by the input options
+// and trimmed to the duration of the video before it is faded and ducked
+func musicFilter(music settings.Music, duration float64) string {
+	musicChain := fmt.Sprintf("[1:a]atrim=0:%.3f,asetpts=PTS-STARTPTS,volume=%.3f", duration, music.Volume)
+	if music.FadeIn > 0 {
+		musicChain = musicChain + fmt.Sprintf(",afade=t=in:st=0:d=%.3f", music.FadeIn)
+	}
+	if music.FadeOut > 0 {
+		start := duration - music.FadeOut
+		if start < 0 {
+			start = 0
+		}
+		musicChain = musicChain + fmt.Sprintf(",afade=t=out:st=%.3f:d=%.3f", start, music.FadeOut)
+	}
+	if !music.Ducking {
+		// amix lowers each input by the number of inputs; the volume filter restores the narration
+		return musicChain + "[music];[0:a][music]amix=inputs=2:duration=first:dropout_transition=0,volume=2[aout]"
+	}
+	return "[0:a]asplit=2[voice][sidechain];" +
+		musicChain + "[music];" +
+		fmt.Sprintf("[music][sidechain]sidechaincompress=threshold=0.03:ratio=%.1f:attack=20:release=400[ducked];", music.DuckingRatio) +
+		"[voice][ducked]amix=inputs=2:duration=first:dropout_transition=0,volume=2[aout]"
+}
+
+// mixBackgroundMusic mixes the background music under the narration of the video
+// The video stream is copied as is and only the audio is re-encoded
+func mixBackgroundMusic(videoFile, musicFile, outputVideoFile string, music settings.Music) error {
+	duration, err := getVideoDuration(videoFile)
+	if err != nil {
+		return fmt.Errorf("Unable to get duration of the video. Error: %v", err)
+	}
+	args := []string{
+		"-i", videoFile,
+		"-stream_loop", "-1", "-i", musicFile,
+		"-filter_complex", musicFilter(music, duration),
+		"-map", "0:v", "-map", "[aout]",
+		"-c:v", "copy", "-c:a", "aac",
+		"-t", fmt.Sprintf("%.3f", duration),
+		outputVideoFile,
+	}
+	cmd := exec.Command("ffmpeg", args...)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("Error: %v, Stdout: %v, Stderr: %v", err, out.String(), stderr.String())
+	}
+	return nil
+}
+
+// burnSubtitles renders the subtitles into the video. The video has to be re-encoded
+// while the audio is copied as is
+func burnSubtitles(videoFile, subtitleFile, outputVideoFile string) error {
+	cmd := exec.Command("ffmpeg", "-i", videoFile, "-vf", "subtitles="+subtitleFile, "-c:v", "libx264", "-c:a", "copy", outputVideoFile)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("Error: %v, Stdout: %v, Stderr: %v", err, out.String(), stderr.String())
+	}
+	return nil
+}
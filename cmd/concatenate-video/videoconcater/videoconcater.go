@@ -1,6 +1,10 @@
 package videoconcater
 
-import "context"
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
 
 type JobDetails struct {
 	ID                 string   `json:"id" validate:"required"`
@@ -8,6 +12,20 @@ type JobDetails struct {
 	VideoIDs           []string `json:"video_segments" validate:"required"`
 	RunningIdemKey     string   `json:"idem_key_running" validate:"required"`
 	CompleteRecIdemKey string   `json:"idem_key_complete_rec" validate:"required"`
+	// OutputFileName is the name of the concatenated video. Each run gets a new file
+	// so that earlier versions of the video are not overwritten
+	OutputFileName string `json:"output_file"`
+	// Only provided when generating a video output of the project
+	OutputID     string `json:"output_id"`
+	Scale        string `json:"scale"`
+	VideoBitrate string `json:"video_bitrate"`
+	// Scripts are the scripts narrated in each of the videos and are used to create
+	// the subtitles of the combined video
+	Scripts       []string `json:"scripts"`
+	BurnSubtitles bool     `json:"burn_subtitles"`
+	// Music is the background music to be mixed under the narration. Jobs without
+	// background music keep the audio of the video segments as is
+	Music *settings.Music `json:"music"`
 }
 
 type VideoConcater interface {
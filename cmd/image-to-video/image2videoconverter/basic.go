@@ -9,6 +9,7 @@ import (
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/cmd/image-to-video/mgrclient"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -39,14 +40,21 @@ func (h *basic) Process(ctx context.Context, job JobDetails) error {
 		h.logger.Errorf("Error validating for struct. Err: %v", err)
 		return err
 	}
-	h.mgrClient.UpdateRunning(ctx, job.ProjectID, job.ID, job.RunningIdemKey)
-
+	h.mgrClient.UpdateRunning(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.RunningIdemKey)
+	jobSettings := settings.Default().Merge(job.Settings)
+
+	// Videos in additional languages are named with the language so that they do not
+	// replace the video in the primary language
+	fileID := job.ID
+	if job.Language != "" {
+		fileID = job.ID + "-" + job.Language
+	}
 	imageFileName := job.ImageID
-	audioFileName := job.ID + ".mp3"
-	adjustedAudioFileName := "adjusted_" + job.ID + ".mp3"
-	convertedAudioFileName := "converted_" + job.ID + ".m4a"
-	silentVideoFileName := "silent_" + job.ID + ".mp4"
-	outputVideoFileName := job.ID + ".mp4"
+	audioFileName := fileID + ".mp3"
+	adjustedAudioFileName := "adjusted_" + fileID + ".mp3"
+	convertedAudioFileName := "converted_" + fileID + ".m4a"
+	silentVideoFileName := "silent_" + fileID + ".mp4"
+	outputVideoFileName := fileID + ".mp4"
 	defer func() {
 		// Cleanup
 		os.Remove(imageFileName)
@@ -59,68 +67,68 @@ func (h *basic) Process(ctx context.Context, job JobDetails) error {
 
 	rawImage, err := h.blobStorage.Load(ctx, h.imagesFolder+"/"+imageFileName)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to load image from blobstorage. Err: %v", err)
 	}
 	err = ioutil.WriteFile(imageFileName, rawImage, 777)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to write image to file system for further processing. Err: %v", err)
 	}
 
-	audioContent, err := h.textToSpeechEngine.Generate(job.Text)
+	audioContent, err := h.textToSpeechEngine.Generate(job.Text, jobSettings.Voice)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to retrieve speech content from Google Cloud. Err: %v", err)
 	}
 	err = ioutil.WriteFile(audioFileName, audioContent, 777)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to write speech to file system for further processing. Err: %v", err)
 	}
 
 	err = addSilentAudio(audioFileName, adjustedAudioFileName)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to create silent audio. Err: %v", err)
 	}
 
-	err = convertToUseAAC(adjustedAudioFileName, convertedAudioFileName)
+	err = convertToUseAAC(adjustedAudioFileName, convertedAudioFileName, jobSettings.Render)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to convert audio to be acc format. Err: %v", err)
 	}
 
 	audioDuration, err := getAudioDuration(convertedAudioFileName)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to get duration of the audio. Err: %v", err)
 	}
 
-	err = generateSilentVideo(imageFileName, audioDuration, silentVideoFileName)
+	err = generateSilentVideo(imageFileName, audioDuration, silentVideoFileName, jobSettings.Render)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to generate the silent video. Err: %v", err)
 	}
 
-	err = muxSilentVideoAndAudio(silentVideoFileName, convertedAudioFileName, outputVideoFileName)
+	err = muxSilentVideoAndAudio(silentVideoFileName, convertedAudioFileName, outputVideoFileName, jobSettings.Render)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to mux the silent video and audio into a single video. Err: %v", err)
 	}
 
 	videoContent, err := ioutil.ReadFile(outputVideoFileName)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to write the video content file to fs. Err: %v", err)
 	}
 
 	err = h.blobStorage.Save(ctx, outputVideoFileName, videoContent)
 	if err != nil {
-		h.mgrClient.FailedTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey)
+		h.mgrClient.FailedTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey)
 		return fmt.Errorf("Unable to store file into blob storage. Err: %v", err)
 	}
 
-	h.mgrClient.CompleteTask(ctx, job.ProjectID, job.ID, job.CompleteRecIdemKey, outputVideoFileName)
+	h.mgrClient.CompleteTask(ctx, job.AuthToken, job.ProjectID, job.ID, job.Language, job.CompleteRecIdemKey, outputVideoFileName)
 	return nil
 }
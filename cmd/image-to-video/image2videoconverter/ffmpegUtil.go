@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 )
 
 type ffprobeFormatted struct {
@@ -18,9 +20,9 @@ type audioDuration struct {
 	Duration string
 }
 
-func convertToUseAAC(filename, adjustedFilename string) error {
+func convertToUseAAC(filename, adjustedFilename string, render settings.Render) error {
 	// tempFilename := strings.Replace(filename, ".mp3", ".m4a", -1)
-	cmd := exec.Command("ffmpeg", "-y", "-i", filename, "-c:a", "aac", adjustedFilename)
+	cmd := exec.Command("ffmpeg", "-y", "-i", filename, "-c:a", "aac", "-b:a", audioBitrate(render), adjustedFilename)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
@@ -55,8 +57,22 @@ func getAudioDuration(filename string) (duration float32, err error) {
 	return float32(val), nil
 }
 
-func generateSilentVideo(imageFilename string, duration float32, outputFile string) error {
-	cmd := exec.Command("ffmpeg", "-r", "1/"+fmt.Sprintf("%f", duration), "-i", imageFilename, "-y", "-c:v", "libx264", "-vf", "fps=25", "-pix_fmt", "yuv420p", outputFile)
+// videoFilter sets the frame rate of the video. If a resolution is provided, the slide is
+// scaled to fit within it and padded to keep its aspect ratio
+func videoFilter(render settings.Render) string {
+	filter := fmt.Sprintf("fps=%v", render.FrameRate)
+	if render.Width > 0 && render.Height > 0 {
+		filter = filter + fmt.Sprintf(",scale=%v:%v:force_original_aspect_ratio=decrease,pad=%v:%v:(ow-iw)/2:(oh-ih)/2", render.Width, render.Height, render.Width, render.Height)
+	}
+	return filter
+}
+
+func audioBitrate(render settings.Render) string {
+	return fmt.Sprintf("%vk", render.AudioBitrate)
+}
+
+func generateSilentVideo(imageFilename string, duration float32, outputFile string, render settings.Render) error {
+	cmd := exec.Command("ffmpeg", "-r", "1/"+fmt.Sprintf("%f", duration), "-i", imageFilename, "-y", "-c:v", render.VideoCodec, "-crf", strconv.Itoa(render.CRF), "-vf", videoFilter(render), "-pix_fmt", "yuv420p", outputFile)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
@@ -106,8 +122,8 @@ func addSilentAudio(filename, outputFilename string) error {
 	return nil
 }
 
-func muxSilentVideoAndAudio(silentVideoFilename, audioFilename, outputFilename string) error {
-	cmd := exec.Command("ffmpeg", "-i", silentVideoFilename, "-y", "-i", audioFilename, "-c:v", "copy", "-c:a", "aac", outputFilename)
+func muxSilentVideoAndAudio(silentVideoFilename, audioFilename, outputFilename string, render settings.Render) error {
+	cmd := exec.Command("ffmpeg", "-i", silentVideoFilename, "-y", "-i", audioFilename, "-c:v", "copy", "-c:a", "aac", "-b:a", audioBitrate(render), outputFilename)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
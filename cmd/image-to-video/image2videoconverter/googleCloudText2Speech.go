@@ -5,6 +5,7 @@ import (
 
 	texttospeech "cloud.google.com/go/texttospeech/apiv1"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 	texttospeechpb "google.golang.org/genproto/googleapis/cloud/texttospeech/v1"
 )
 
@@ -20,17 +21,21 @@ func NewGoogleTextToSpeech(l logger.Logger, t *texttospeech.Client) GoogleTextTo
 	}
 }
 
-func (g *GoogleTextToSpeech) Generate(text string) ([]byte, error) {
+func (g *GoogleTextToSpeech) Generate(text string, voice settings.Voice) ([]byte, error) {
 	req := &texttospeechpb.SynthesizeSpeechRequest{
 		Input: &texttospeechpb.SynthesisInput{
-			InputSource: &texttospeechpb.SynthesisInput_Text{text},
+			InputSource: &texttospeechpb.SynthesisInput_Text{Text: text},
 		},
 		Voice: &texttospeechpb.VoiceSelectionParams{
-			LanguageCode: "en-US",
-			SsmlGender:   texttospeechpb.SsmlVoiceGender_FEMALE,
+			LanguageCode: voice.LanguageCode,
+			Name:         voice.Name,
+			SsmlGender:   texttospeechpb.SsmlVoiceGender(texttospeechpb.SsmlVoiceGender_value[voice.Gender]),
 		},
 		AudioConfig: &texttospeechpb.AudioConfig{
+			// The audio is kept as MP3 as the silent padding is concatenated without re-encoding
 			AudioEncoding: texttospeechpb.AudioEncoding_MP3,
+			SpeakingRate:  voice.SpeakingRate,
+			Pitch:         voice.Pitch,
 		},
 	}
 	resp, err := g.text2speechClient.SynthesizeSpeech(context.Background(), req)
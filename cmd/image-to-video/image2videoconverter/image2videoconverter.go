@@ -1,14 +1,24 @@
 package image2videoconverter
 
-import "context"
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
 
 type JobDetails struct {
-	ID                 string `json:"id" validate:"required"`
-	ProjectID          string `json:"project_id" validate:"required"`
-	ImageID            string `json:"image_id" validate:"required"`
+	ID        string `json:"id" validate:"required"`
+	ProjectID string `json:"project_id" validate:"required"`
+	ImageID   string `json:"image_id" validate:"required"`
+	AuthToken string `json:"auth_token" validate:"required"`
+	// Language is set for videos in the additional languages of the project
+	Language           string `json:"language"`
 	Text               string `json:"script" validate:"required"`
 	RunningIdemKey     string `json:"idem_key_running" validate:"required"`
 	CompleteRecIdemKey string `json:"idem_key_complete_rec" validate:"required"`
+	// Settings are the effective settings of the video segment. Settings missing from
+	// jobs sent by older managers fall back to the defaults
+	Settings settings.Settings `json:"settings"`
 }
 
 type Image2VideoConverter interface {
@@ -16,5 +26,5 @@ type Image2VideoConverter interface {
 }
 
 type TextToSpeechEngine interface {
-	Generate(text string) ([]byte, error)
+	Generate(text string, voice settings.Voice) ([]byte, error)
 }
@@ -25,14 +25,16 @@ func NewBasic(l logger.Logger, endpoint string, cl *http.Client) basic {
 	}
 }
 
-func (b basic) UpdateRunning(ctx context.Context, projectID, videoSegmentID, idemKey string) error {
+func (b basic) UpdateRunning(ctx context.Context, authToken, projectID, videoSegmentID, language, idemKey string) error {
 	endpoint := b.baseEndpoint + "/project/" + projectID + "/videosegment/" + videoSegmentID
 	type updateInput struct {
 		Status            string `json:"status"`
+		Language          string `json:"language,omitempty"`
 		IdemKeySetRunning string `json:"idem_key_running"`
 	}
 	updateInputReq := updateInput{
 		Status:            "running",
+		Language:          language,
 		IdemKeySetRunning: idemKey,
 	}
 	rawUpdateInputReq, err := json.Marshal(updateInputReq)
@@ -45,6 +47,8 @@ func (b basic) UpdateRunning(ctx context.Context, projectID, videoSegmentID, ide
 		return err
 	}
 
+	req.Header.Add("Authorization", authToken)
+
 	resp, err := b.client.Do(req)
 	if err != nil {
 		return err
@@ -57,14 +61,16 @@ func (b basic) UpdateRunning(ctx context.Context, projectID, videoSegmentID, ide
 	return nil
 }
 
-func (b basic) FailedTask(ctx context.Context, projectID, videoSegmentID, idemKey string) error {
+func (b basic) FailedTask(ctx context.Context, authToken, projectID, videoSegmentID, language, idemKey string) error {
 	endpoint := b.baseEndpoint + "/project/" + projectID + "/videosegment/" + videoSegmentID
 	type updateInput struct {
 		Status             string `json:"status"`
+		Language           string `json:"language,omitempty"`
 		IdemKeyCompleteRec string `json:"idem_key_complete_rec"`
 	}
 	updateInputReq := updateInput{
 		Status:             "error",
+		Language:           language,
 		IdemKeyCompleteRec: idemKey,
 	}
 	rawUpdateInputReq, err := json.Marshal(updateInputReq)
@@ -77,6 +83,8 @@ func (b basic) FailedTask(ctx context.Context, projectID, videoSegmentID, idemKe
 		return err
 	}
 
+	req.Header.Add("Authorization", authToken)
+
 	resp, err := b.client.Do(req)
 	if err != nil {
 		return err
@@ -89,15 +97,17 @@ func (b basic) FailedTask(ctx context.Context, projectID, videoSegmentID, idemKe
 	return nil
 }
 
-func (b basic) CompleteTask(ctx context.Context, projectID, videoSegmentID, idemKey, videoFile string) error {
+func (b basic) CompleteTask(ctx context.Context, authToken, projectID, videoSegmentID, language, idemKey, videoFile string) error {
 	endpoint := b.baseEndpoint + "/project/" + projectID + "/videosegment/" + videoSegmentID
 	type updateInput struct {
 		Status             string `json:"status"`
+		Language           string `json:"language,omitempty"`
 		VideoFile          string `json:"video_file"`
 		IdemKeyCompleteRec string `json:"idem_key_complete_rec"`
 	}
 	updateInputReq := updateInput{
 		Status:             "completed",
+		Language:           language,
 		VideoFile:          videoFile,
 		IdemKeyCompleteRec: idemKey,
 	}
@@ -111,6 +121,8 @@ func (b basic) CompleteTask(ctx context.Context, projectID, videoSegmentID, idem
 		return err
 	}
 
+	req.Header.Add("Authorization", authToken)
+
 	resp, err := b.client.Do(req)
 	if err != nil {
 		return err
@@ -4,8 +4,10 @@ package mgrclient
 
 import "context"
 
+// Client reports the progress of the video of a video segment to the manager
+// An empty language refers to the primary language of the project
 type Client interface {
-	UpdateRunning(ctx context.Context, projectID, videoSegmentID, idemKey string) error
-	FailedTask(ctx context.Context, projectID, videoSegmentID, idemKey string) error
-	CompleteTask(ctx context.Context, projectID, videoSegmentID, idemKey, videoFile string) error
+	UpdateRunning(ctx context.Context, authToken, projectID, videoSegmentID, language, idemKey string) error
+	FailedTask(ctx context.Context, authToken, projectID, videoSegmentID, language, idemKey string) error
+	CompleteTask(ctx context.Context, authToken, projectID, videoSegmentID, language, idemKey, videoFile string) error
 }
@@ -25,7 +25,7 @@ func NewBasic(l logger.Logger, endpoint string, cl *http.Client) basic {
 	}
 }
 
-func (b basic) UpdateRunning(ctx context.Context, projectID, pdfslideimagesID, idemKey string) error {
+func (b basic) UpdateRunning(ctx context.Context, authToken, projectID, pdfslideimagesID, idemKey string) error {
 	endpoint := b.baseEndpoint + "/project/" + projectID + "/pdfslideimages/" + pdfslideimagesID
 	type updateInput struct {
 		Status            string `json:"status"`
@@ -45,6 +45,8 @@ func (b basic) UpdateRunning(ctx context.Context, projectID, pdfslideimagesID, i
 		return err
 	}
 
+	req.Header.Add("Authorization", authToken)
+
 	resp, err := b.client.Do(req)
 	if err != nil {
 		return err
@@ -57,7 +59,7 @@ func (b basic) UpdateRunning(ctx context.Context, projectID, pdfslideimagesID, i
 	return nil
 }
 
-func (b basic) FailedTask(ctx context.Context, projectID, pdfslideimagesID, idemKey string) error {
+func (b basic) FailedTask(ctx context.Context, authToken, projectID, pdfslideimagesID, idemKey string) error {
 	endpoint := b.baseEndpoint + "/project/" + projectID + "/pdfslideimages/" + pdfslideimagesID
 	type updateInput struct {
 		Status             string `json:"status"`
@@ -77,6 +79,8 @@ func (b basic) FailedTask(ctx context.Context, projectID, pdfslideimagesID, idem
 		return err
 	}
 
+	req.Header.Add("Authorization", authToken)
+
 	resp, err := b.client.Do(req)
 	if err != nil {
 		return err
@@ -89,7 +93,7 @@ func (b basic) FailedTask(ctx context.Context, projectID, pdfslideimagesID, idem
 	return nil
 }
 
-func (b basic) CompleteTask(ctx context.Context, projectID, pdfslideimagesID, idemKey string, slideAssets []SlideAsset) error {
+func (b basic) CompleteTask(ctx context.Context, authToken, projectID, pdfslideimagesID, idemKey string, slideAssets []SlideAsset) error {
 	endpoint := b.baseEndpoint + "/project/" + projectID + "/pdfslideimages/" + pdfslideimagesID
 	type updateInput struct {
 		Status             string       `json:"status"`
@@ -111,6 +115,8 @@ func (b basic) CompleteTask(ctx context.Context, projectID, pdfslideimagesID, id
 		return err
 	}
 
+	req.Header.Add("Authorization", authToken)
+
 	resp, err := b.client.Do(req)
 	if err != nil {
 		return err
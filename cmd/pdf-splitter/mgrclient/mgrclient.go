@@ -5,10 +5,11 @@ import "context"
 type SlideAsset struct {
 	ImageID string `json:"image_id"`
 	Order   int    `json:"order"`
+	Script  string `json:"script,omitempty"`
 }
 
 type Client interface {
-	UpdateRunning(ctx context.Context, projectID, pdfslideimagesID, idemKey string) error
-	FailedTask(ctx context.Context, projectID, pdfslideimagesID, idemKey string) error
-	CompleteTask(ctx context.Context, projectID, pdfslideimagesID, idemKey string, slideAssets []SlideAsset) error
+	UpdateRunning(ctx context.Context, authToken, projectID, pdfslideimagesID, idemKey string) error
+	FailedTask(ctx context.Context, authToken, projectID, pdfslideimagesID, idemKey string) error
+	CompleteTask(ctx context.Context, authToken, projectID, pdfslideimagesID, idemKey string, slideAssets []SlideAsset) error
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/cmd/pdf-splitter/mgrclient"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 )
 
 type basic struct {
@@ -34,10 +35,10 @@ func NewBasic(logger logger.Logger, storage blobstorage.BlobStorage, mgrclient m
 }
 
 func (h *basic) Process(job PdfSplitJob) error {
-	h.MgrClient.UpdateRunning(context.Background(), job.ProjectID, job.ID, job.IdemKeySetRunning)
+	h.MgrClient.UpdateRunning(context.Background(), job.AuthToken, job.ProjectID, job.ID, job.IdemKeySetRunning)
 
 	if job.Validate() != nil {
-		h.MgrClient.FailedTask(context.Background(), job.ProjectID, job.ID, job.IdemKeyCompleteRec)
+		h.MgrClient.FailedTask(context.Background(), job.AuthToken, job.ProjectID, job.ID, job.IdemKeyCompleteRec)
 		return fmt.Errorf("%+v", job.Validate())
 	}
 
@@ -48,7 +49,8 @@ func (h *basic) Process(job PdfSplitJob) error {
 
 	ioutil.WriteFile(job.PdfFileName, content, os.FileMode(0777))
 
-	cmd := exec.Command("convert", "-density", "150", job.ID+".pdf", "-quality", "90", job.ID+".png")
+	jobSettings := settings.Default().Merge(job.Settings)
+	cmd := exec.Command("convert", "-density", strconv.Itoa(jobSettings.Render.SlideDensity), job.ID+".pdf", "-quality", "90", job.ID+".png")
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
@@ -60,7 +62,7 @@ func (h *basic) Process(job PdfSplitJob) error {
 
 	fileInfo, err := ioutil.ReadDir(".")
 	if err != nil {
-		h.MgrClient.FailedTask(context.Background(), job.ProjectID, job.ID, job.IdemKeyCompleteRec)
+		h.MgrClient.FailedTask(context.Background(), job.AuthToken, job.ProjectID, job.ID, job.IdemKeyCompleteRec)
 		return fmt.Errorf("Error occured while getting file info %v", err)
 	}
 
@@ -76,7 +78,7 @@ func (h *basic) Process(job PdfSplitJob) error {
 		content, _ := ioutil.ReadFile(file)
 		err = h.SlidesToVideoStorage.Save(context.Background(), h.ImagesFolder+"/"+file, content)
 		if err != nil {
-			h.MgrClient.FailedTask(context.Background(), job.ProjectID, job.ID, job.IdemKeyCompleteRec)
+			h.MgrClient.FailedTask(context.Background(), job.AuthToken, job.ProjectID, job.ID, job.IdemKeyCompleteRec)
 			return fmt.Errorf("Error occured while saving %v", err)
 		}
 	}
@@ -104,6 +106,20 @@ func (h *basic) Process(job PdfSplitJob) error {
 		slideDetails = append(slideDetails, s)
 	}
 
+	if job.ExtractScripts {
+		pageTexts, err := extractPageTexts(job.PdfFileName)
+		if err != nil {
+			// Scripts are optional - the slides can still be used without them
+			h.Logger.Errorf("Unable to extract text from pdf. Err: %v", err)
+		}
+		for k := range slideDetails {
+			order := slideDetails[k].Order
+			if order >= 0 && order < len(pageTexts) {
+				slideDetails[k].Script = pageTexts[order]
+			}
+		}
+	}
+
 	fileList = append(fileList, job.PdfFileName)
 
 	// Cleanup of files
@@ -114,10 +130,30 @@ func (h *basic) Process(job PdfSplitJob) error {
 		}
 	}
 
-	err = h.MgrClient.CompleteTask(context.Background(), job.ProjectID, job.ID, job.IdemKeyCompleteRec, slideDetails)
+	err = h.MgrClient.CompleteTask(context.Background(), job.AuthToken, job.ProjectID, job.ID, job.IdemKeyCompleteRec, slideDetails)
 	if err != nil {
-		h.MgrClient.FailedTask(context.Background(), job.ProjectID, job.ID, job.IdemKeyCompleteRec)
+		h.MgrClient.FailedTask(context.Background(), job.AuthToken, job.ProjectID, job.ID, job.IdemKeyCompleteRec)
 		return fmt.Errorf("Error occured while saving %v", err)
 	}
 	return nil
 }
+
+// extractPageTexts uses pdftotext (poppler-utils) to retrieve the text of each page
+// of the pdf. Pages are separated by form feed characters in the output
+func extractPageTexts(pdfFileName string) ([]string, error) {
+	cmd := exec.Command("pdftotext", "-layout", "-enc", "UTF-8", pdfFileName, "-")
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return []string{}, fmt.Errorf("Error occured while extracting text %v. Stderr: %v", err, stderr.String())
+	}
+	pages := strings.Split(out.String(), "\f")
+	var pageTexts []string
+	for _, p := range pages {
+		pageTexts = append(pageTexts, strings.Join(strings.Fields(p), " "))
+	}
+	return pageTexts, nil
+}
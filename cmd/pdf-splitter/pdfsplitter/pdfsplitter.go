@@ -1,13 +1,22 @@
 package pdfsplitter
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
 
 type PdfSplitJob struct {
 	ID                 string `json:"id"`
 	ProjectID          string `json:"project_id"`
 	PdfFileName        string `json:"pdf_filename"`
+	AuthToken          string `json:"auth_token"`
 	IdemKeySetRunning  string `json:"running_idem_key"`
 	IdemKeyCompleteRec string `json:"complete_rec_idem_key"`
+	ExtractScripts     bool   `json:"extract_scripts"`
+	// Settings are the effective settings of the project. Only the slide density is used
+	// when splitting the pdf
+	Settings settings.Settings `json:"settings"`
 }
 
 func (j *PdfSplitJob) Validate() error {
@@ -18,6 +27,9 @@ func (j *PdfSplitJob) Validate() error {
 	if j.PdfFileName == "" {
 		allError = allError + fmt.Sprintf("PDF file name cannot be empty")
 	}
+	if j.AuthToken == "" {
+		allError = allError + fmt.Sprintf("Auth token cannot be empty")
+	}
 	if allError != "" {
 		return fmt.Errorf(allError)
 	}
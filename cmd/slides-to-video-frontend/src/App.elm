@@ -75,6 +75,7 @@ type alias Model =
     , userDetails : UserDetails
     , projects : ProjectList
     , singleProject : SingleProject
+    , selectedLanguage : String
     }
 
 
@@ -129,7 +130,7 @@ init flags url key =
             UserDetails "" "" ""
 
         emptySingleProject =
-            SingleProject "" "" "" "" "" [] [] ""
+            SingleProject "" "" "" "" "" [] [] "" "" []
 
         initialAppState =
             { key = key
@@ -144,6 +145,7 @@ init flags url key =
             , userDetails = emptyUserDetails
             , projects = emptyProjectList
             , singleProject = emptySingleProject
+            , selectedLanguage = ""
             }
     in
     case flags.token of
@@ -211,6 +213,7 @@ type Msg
     | SubmitScriptInput String
     | UpdateVideoSegmentResponse (Result Http.Error VideoSegment)
     | SubmitGenerateVideo
+    | SelectLanguage String
     | SubmitUploadPDFSlides
     | UploadPDFSlidesResponse (Result Http.Error PDFSlideImages)
     | DownloadGeneratedVideo String String String
@@ -226,7 +229,10 @@ update msg model =
             ( model, Cmd.none )
 
         SubmitGenerateVideo ->
-            ( model, Cmd.batch [ apiProjectGenerateVideo model.serverSettings.serverEndpoint model.singleProject.id ] )
+            ( model, Cmd.batch [ apiProjectGenerateVideo model.serverSettings.serverEndpoint model.singleProject.id model.selectedLanguage ] )
+
+        SelectLanguage language ->
+            ( { model | selectedLanguage = language }, Cmd.none )
 
         UpdateVideoSegmentResponse result ->
             case result of
@@ -246,12 +252,16 @@ update msg model =
                     ( model, Cmd.batch [] )
 
                 Just vs ->
-                    ( model, Cmd.batch [ apiUpdateVideoSegmentScript model.serverSettings.serverEndpoint model.singleProject.id vs.id vs.script ] )
+                    if model.selectedLanguage == "" then
+                        ( model, Cmd.batch [ apiUpdateVideoSegmentScript model.serverSettings.serverEndpoint model.singleProject.id vs.id vs.script ] )
+
+                    else
+                        ( model, Cmd.batch [ apiUpdateVideoSegmentTranslation model.serverSettings.serverEndpoint model.singleProject.id vs.id model.selectedLanguage (translationScript model.selectedLanguage vs) ] )
 
         ScriptInput videoSegmentID script ->
             let
                 updatedVideoSegments =
-                    List.map (updateVideoSegmentScript videoSegmentID script) model.singleProject.videoSegments
+                    List.map (updateVideoSegmentScript videoSegmentID model.selectedLanguage script) model.singleProject.videoSegments
 
                 copiedSingleProject =
                     model.singleProject
@@ -404,8 +414,15 @@ update msg model =
 
                         tempVs =
                             List.sortBy .order vs
+
+                        language =
+                            if List.member model.selectedLanguage p.languages then
+                                model.selectedLanguage
+
+                            else
+                                ""
                     in
-                    ( { model | singleProject = { p | videoSegments = tempVs } }, Cmd.none )
+                    ( { model | singleProject = { p | videoSegments = tempVs }, selectedLanguage = language }, Cmd.none )
 
                 Err _ ->
                     ( model, Cmd.none )
@@ -591,16 +608,48 @@ type alias SingleProject =
     , pdfSlideImages : List PDFSlideImages
     , videoSegments : List VideoSegment
     , videoOutputID : String
+    , primaryLanguage : String
+    , languages : List String
     }
 
 
-updateVideoSegmentScript : String -> String -> VideoSegment -> VideoSegment
-updateVideoSegmentScript videoSegmentID script videoSegment =
-    if videoSegment.id == videoSegmentID then
+updateVideoSegmentScript : String -> String -> String -> VideoSegment -> VideoSegment
+updateVideoSegmentScript videoSegmentID language script videoSegment =
+    if videoSegment.id /= videoSegmentID then
+        videoSegment
+
+    else if language == "" then
         { videoSegment | script = script }
 
+    else if List.any (\t -> t.language == language) videoSegment.translations then
+        { videoSegment
+            | translations =
+                List.map
+                    (\t ->
+                        if t.language == language then
+                            { t | script = script }
+
+                        else
+                            t
+                    )
+                    videoSegment.translations
+        }
+
     else
-        videoSegment
+        { videoSegment | translations = videoSegment.translations ++ [ Translation language script "" ] }
+
+
+translationScript : String -> VideoSegment -> String
+translationScript language videoSegment =
+    if language == "" then
+        videoSegment.script
+
+    else
+        videoSegment.translations
+            |> List.filter (\t -> t.language == language)
+            |> List.head
+            |> Maybe.map .script
+            |> Maybe.withDefault ""
 
 
 isVideoSegment : String -> VideoSegment -> Bool
@@ -623,6 +672,8 @@ singleProjectDecoder =
         |> Pipeline.optional "pdf_slide_images" (Decode.list pdfSlideImagesDecoder) []
         |> Pipeline.optional "video_segments" (Decode.list videoSegmentDecoder) []
         |> Pipeline.optional "video_output_id" string ""
+        |> Pipeline.optionalAt [ "settings", "voice", "language_code" ] string "en-US"
+        |> Pipeline.optional "languages" (Decode.list (Decode.field "code" string)) []
 
 
 type alias ProjectList =
@@ -689,9 +740,25 @@ type alias VideoSegment =
     , script : String
     , audioID : String
     , videoSrcID : String
+    , translations : List Translation
     }
 
 
+type alias Translation =
+    { language : String
+    , script : String
+    , status : String
+    }
+
+
+translationDecoder : Decoder Translation
+translationDecoder =
+    Decode.succeed Translation
+        |> Pipeline.required "language" string
+        |> Pipeline.optional "script" string ""
+        |> Pipeline.optional "status" string ""
+
+
 videoSegmentDecoder : Decoder VideoSegment
 videoSegmentDecoder =
     Decode.succeed VideoSegment
@@ -707,6 +774,7 @@ videoSegmentDecoder =
         |> Pipeline.optional "script" string ""
         |> Pipeline.optional "audio_id" string ""
         |> Pipeline.optional "video_src_id" string ""
+        |> Pipeline.optional "translations" (Decode.list translationDecoder) []
 
 
 loginPage : Model -> Url.Url -> Html Msg
@@ -867,8 +935,9 @@ singleProjectPage model =
 
                 else
                     p [] [ text "File already uploaded" ]
+              , languageSelector model
               ]
-            , List.map (videoSegmentRow imageServeURL) model.singleProject.videoSegments
+            , List.map (videoSegmentRow imageServeURL model.selectedLanguage) model.singleProject.videoSegments
             ]
         )
 
@@ -882,8 +951,30 @@ downloadProjectVideo mgrURL projectID videoOutputID =
     Download.url videoServeURL
 
 
-videoSegmentRow : String -> VideoSegment -> Html Msg
-videoSegmentRow serveImageURL videoSegment =
+languageSelector : Model -> Html Msg
+languageSelector model =
+    if List.isEmpty model.singleProject.languages then
+        div [] []
+
+    else
+        div []
+            (p [] [ text "Language" ]
+                :: languageButton model.selectedLanguage "" model.singleProject.primaryLanguage
+                :: List.map (\l -> languageButton model.selectedLanguage l l) model.singleProject.languages
+            )
+
+
+languageButton : String -> String -> String -> Html Msg
+languageButton selectedLanguage language label =
+    if selectedLanguage == language then
+        Button.button [ Button.primary, Button.onClick (SelectLanguage language) ] [ text label ]
+
+    else
+        Button.button [ Button.outlinePrimary, Button.onClick (SelectLanguage language) ] [ text label ]
+
+
+videoSegmentRow : String -> String -> VideoSegment -> Html Msg
+videoSegmentRow serveImageURL language videoSegment =
     Card.config []
         |> Card.block []
             [ Block.custom <|
@@ -896,7 +987,7 @@ videoSegmentRow serveImageURL videoSegment =
                         , Textarea.textarea
                             [ Textarea.id "script"
                             , Textarea.rows 3
-                            , Textarea.value videoSegment.script
+                            , Textarea.value (translationScript language videoSegment)
                             , Textarea.onInput (ScriptInput videoSegment.id)
                             ]
                         ]
@@ -1046,6 +1137,29 @@ apiUpdateVideoSegmentScript mgrURL projectID videoSegmentID script =
         }
 
 
+apiUpdateVideoSegmentTranslation : String -> String -> String -> String -> String -> Cmd Msg
+apiUpdateVideoSegmentTranslation mgrURL projectID videoSegmentID language script =
+    let
+        url =
+            mgrURL ++ "/api/v1/project/" ++ projectID ++ "/videosegment/" ++ videoSegmentID ++ "/translation/" ++ language
+
+        body =
+            Http.jsonBody <|
+                Encode.object
+                    [ ( "script", Encode.string script )
+                    ]
+    in
+    Http.request
+        { body = body
+        , method = "PUT"
+        , url = url
+        , headers = []
+        , timeout = Nothing
+        , tracker = Nothing
+        , expect = Http.expectJson UpdateVideoSegmentResponse videoSegmentDecoder
+        }
+
+
 apiUploadPDFSlides : String -> String -> List File -> Cmd Msg
 apiUploadPDFSlides mgrURL projectID files =
     let
@@ -1063,11 +1177,15 @@ apiUploadPDFSlides mgrURL projectID files =
         }
 
 
-apiProjectGenerateVideo : String -> String -> Cmd Msg
-apiProjectGenerateVideo mgrURL projectID =
+apiProjectGenerateVideo : String -> String -> String -> Cmd Msg
+apiProjectGenerateVideo mgrURL projectID language =
     let
         url =
-            mgrURL ++ "/api/v1/project/" ++ projectID ++ ":generate-video"
+            if language == "" then
+                mgrURL ++ "/api/v1/project/" ++ projectID ++ ":generate-video"
+
+            else
+                mgrURL ++ "/api/v1/project/" ++ projectID ++ ":generate-video?language=" ++ language
     in
     Http.request
         { body = Http.emptyBody
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/client"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveEndpoint  string
+	archiveToken     string
+	archiveFile      string
+	archiveProjectID string
+
+	archiveCmd = func() *cobra.Command {
+		archiveCmd := &cobra.Command{
+			Use:   "archive",
+			Short: "Export and import projects as portable zip archives",
+			Long: `Exports a project from a running manager into a zip archive or imports such an archive
+into a running manager. Imported projects are owned by the user that the token belongs to`,
+			Run: func(cmd *cobra.Command, args []string) {
+				cmd.Help()
+			},
+		}
+		archiveCmd.PersistentFlags().StringVar(&archiveEndpoint, "endpoint", envVarOrDefault("ARCHIVE_ENDPOINT", "http://localhost:8080"), "Endpoint of the manager")
+		archiveCmd.PersistentFlags().StringVar(&archiveToken, "token", envVarOrDefault("ARCHIVE_TOKEN", ""), "Auth token of the user calling the manager")
+		archiveCmd.PersistentFlags().StringVarP(&archiveFile, "file", "f", "", "Path of the zip archive")
+		archiveCmd.AddCommand(archiveExportCmd())
+		archiveCmd.AddCommand(archiveImportCmd())
+		return archiveCmd
+	}
+
+	archiveExportCmd = func() *cobra.Command {
+		archiveExportCmd := &cobra.Command{
+			Use:   "export",
+			Short: "Export a project into a zip archive",
+			Run: func(cmd *cobra.Command, args []string) {
+				if archiveProjectID == "" || archiveFile == "" {
+					fmt.Fprintln(os.Stderr, "Error: project id and file are required")
+					os.Exit(1)
+				}
+				c := client.NewClient(archiveEndpoint, http.DefaultClient, logrus.New())
+				c.SetAuthToken(archiveToken)
+				rawArchive, err := c.ExportProject(archiveProjectID)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				err = ioutil.WriteFile(archiveFile, rawArchive, 0644)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: unable to write archive. %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("Exported project %v to %v\n", archiveProjectID, archiveFile)
+			},
+		}
+		archiveExportCmd.Flags().StringVarP(&archiveProjectID, "project-id", "p", "", "ID of the project to be exported")
+		return archiveExportCmd
+	}
+
+	archiveImportCmd = func() *cobra.Command {
+		archiveImportCmd := &cobra.Command{
+			Use:   "import",
+			Short: "Import a project from a zip archive",
+			Run: func(cmd *cobra.Command, args []string) {
+				if archiveFile == "" {
+					fmt.Fprintln(os.Stderr, "Error: file is required")
+					os.Exit(1)
+				}
+				rawArchive, err := ioutil.ReadFile(archiveFile)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: unable to read archive. %v\n", err)
+					os.Exit(1)
+				}
+				c := client.NewClient(archiveEndpoint, http.DefaultClient, logrus.New())
+				c.SetAuthToken(archiveToken)
+				p, err := c.ImportProject(rawArchive)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("Imported project as %v\n", p.ID)
+			},
+		}
+		return archiveImportCmd
+	}
+)
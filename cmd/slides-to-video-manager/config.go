@@ -1,9 +1,16 @@
 package main
 
 import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
 
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/oidc"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -21,11 +28,20 @@ type datastoreConfig struct {
 }
 
 type googleDatastoreConfig struct {
-	ProjectID              string `yaml:"projectID"`
-	UserTableName          string `yaml:"userTableName"`
-	ProjectTableName       string `yaml:"projectTableName"`
-	PDFSlidesTableName     string `yaml:"pdfSlidesTableName"`
-	VideoSegmentsTableName string `yaml:"videoSegmentsTableName"`
+	ProjectID                string `yaml:"projectID"`
+	UserTableName            string `yaml:"userTableName"`
+	ProjectTableName         string `yaml:"projectTableName"`
+	PDFSlidesTableName       string `yaml:"pdfSlidesTableName"`
+	VideoSegmentsTableName   string `yaml:"videoSegmentsTableName"`
+	ScriptRevisionsTableName string `yaml:"scriptRevisionsTableName"`
+	VideoOutputsTableName    string `yaml:"videoOutputsTableName"`
+	OutputVersionsTableName  string `yaml:"outputVersionsTableName"`
+	FoldersTableName         string `yaml:"foldersTableName"`
+	ShareLinksTableName      string `yaml:"shareLinksTableName"`
+	TeamsTableName           string `yaml:"teamsTableName"`
+	AccessTokensTableName    string `yaml:"accessTokensTableName"`
+	SessionsTableName        string `yaml:"sessionsTableName"`
+	IdentitiesTableName      string `yaml:"identitiesTableName"`
 }
 
 type mysqlConfig struct {
@@ -68,6 +84,121 @@ type serverConfig struct {
 	AuthSecret     string `yaml:"authSecret"`
 	AuthIssuer     string `yaml:"issuer"`
 	AuthExpiryTime int    `yaml:"expiryTime"`
+	// VersionsToKeep is the number of unpublished output versions kept for each video
+	VersionsToKeep int `yaml:"versionsToKeep"`
+	// TrashRetentionDays is the number of days that a deleted project can be restored before it is purged
+	TrashRetentionDays int `yaml:"trashRetentionDays"`
+	// PreviousAuthSecrets are still accepted for JWTs that were signed before the auth secret was rotated
+	PreviousAuthSecrets []string `yaml:"previousAuthSecrets"`
+	// CookieKeys encode the cookies of sessions. The first pair encodes new cookies while the rest
+	// are still accepted so that keys can be rotated. Random keys are used if none are configured
+	CookieKeys []cookieKeysConfig `yaml:"cookieKeys"`
+	// SessionExpiryTime is the number of seconds that users stay signed in after logging in
+	SessionExpiryTime int `yaml:"sessionExpiryTime"`
+	// SigningKeys sign JWTs with RS256 or EdDSA in place of the auth secret. The first key signs
+	// new tokens while the rest are still accepted so that keys can be rotated
+	SigningKeys []signingKeyConfig `yaml:"signingKeys"`
+	// OIDCProviders are the OpenID Connect providers that users are able to sign in with
+	OIDCProviders []oidcProviderConfig `yaml:"oidcProviders"`
+	// LoginRedirectOrigins are the origins that users can be sent back to once they sign in with a
+	// provider. Paths on the manager itself are always allowed
+	LoginRedirectOrigins []string `yaml:"loginRedirectOrigins"`
+}
+
+// oidcProviderConfig identifies the manager to an OpenID Connect provider. The name is used in
+// the login and callback routes of the provider
+type oidcProviderConfig struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"clientID"`
+	ClientSecret string   `yaml:"clientSecret"`
+	RedirectURI  string   `yaml:"redirectURI"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// oidcProviders creates the OpenID Connect providers in the configuration
+func (s serverConfig) oidcProviders() (map[string]*oidc.Provider, error) {
+	providers := map[string]*oidc.Provider{}
+	for i, c := range s.OIDCProviders {
+		if _, exists := providers[c.Name]; exists {
+			return nil, fmt.Errorf("oidc provider %v is configured more than once", c.Name)
+		}
+		p, err := oidc.NewProvider(nil, oidc.Config{
+			Name:         c.Name,
+			Issuer:       c.Issuer,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURI:  c.RedirectURI,
+			Scopes:       c.Scopes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid oidc provider %v. Err: %v", i, err)
+		}
+		providers[c.Name] = p
+	}
+	return providers, nil
+}
+
+// cookieKeysConfig holds base64 encoded keys. The block key needs to be 16, 24 or 32 bytes long
+type cookieKeysConfig struct {
+	HashKey  string `yaml:"hashKey"`
+	BlockKey string `yaml:"blockKey"`
+}
+
+// cookieKeys decodes the cookie keys in the configuration
+func (s serverConfig) cookieKeys() ([]services.CookieKeys, error) {
+	keys := []services.CookieKeys{}
+	for i, k := range s.CookieKeys {
+		hashKey, err := base64.StdEncoding.DecodeString(k.HashKey)
+		if err != nil || len(hashKey) == 0 {
+			return nil, fmt.Errorf("invalid hash key of cookie keys %v. Err: %v", i, err)
+		}
+		blockKey, err := base64.StdEncoding.DecodeString(k.BlockKey)
+		if err != nil || (len(blockKey) != 16 && len(blockKey) != 24 && len(blockKey) != 32) {
+			return nil, fmt.Errorf("invalid block key of cookie keys %v. Err: %v", i, err)
+		}
+		keys = append(keys, services.CookieKeys{HashKey: hashKey, BlockKey: blockKey})
+	}
+	return keys, nil
+}
+
+// signingKeyConfig points to PEM encoded keys. The private key can be left out of keys that
+// are only kept to verify tokens while the public key can be left out if the private key is set
+type signingKeyConfig struct {
+	ID             string `yaml:"id"`
+	Algorithm      string `yaml:"algorithm"`
+	PrivateKeyFile string `yaml:"privateKeyFile"`
+	PublicKeyFile  string `yaml:"publicKeyFile"`
+}
+
+// signingKeys reads the signing keys in the configuration
+func (s serverConfig) signingKeys() (services.KeySet, error) {
+	keys := services.KeySet{}
+	for i, k := range s.SigningKeys {
+		var privateKey, publicKey []byte
+		var err error
+		if k.PrivateKeyFile != "" {
+			privateKey, err = ioutil.ReadFile(k.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read private key of signing key %v. Err: %v", i, err)
+			}
+		}
+		if k.PublicKeyFile != "" {
+			publicKey, err = ioutil.ReadFile(k.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read public key of signing key %v. Err: %v", i, err)
+			}
+		}
+		key, err := services.ParseSigningKey(k.ID, k.Algorithm, privateKey, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) > 0 && keys[0].PrivateKey == nil {
+		return nil, fmt.Errorf("first signing key %v needs a private key to sign tokens", keys[0].ID)
+	}
+	return keys, nil
 }
 
 type blobConfig struct {
@@ -96,11 +227,34 @@ type localBlobConfig struct {
 	PDFFolder string `yaml:"pdfFolder"`
 }
 
+// aclConfig defines custom roles in addition to the built in roles of projects
+type aclConfig struct {
+	Roles []roleConfig `yaml:"roles"`
+}
+
+type roleConfig struct {
+	Name string `yaml:"name"`
+	// Capabilities are any of view, edit-script, generate, concat, publish, share and delete
+	Capabilities []string `yaml:"capabilities"`
+}
+
 type config struct {
 	Server      serverConfig    `yaml:"server"`
 	Datastore   datastoreConfig `yaml:"datastore"`
 	Queue       queueConfig     `yaml:"queue"`
 	BlobStorage blobConfig      `yaml:"blobStorage"`
+	ACL         aclConfig       `yaml:"acl"`
+}
+
+// registerRoles makes the custom roles in the configuration available to the acls of projects
+func registerRoles(c aclConfig) error {
+	for _, r := range c.Roles {
+		err := acl.RegisterRole(r.Name, r.Capabilities)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func envVarOrDefault(envVar, defaultVal string) string {
@@ -111,6 +265,43 @@ func envVarOrDefault(envVar, defaultVal string) string {
 	return defaultVal
 }
 
+// envVarOrDefaultList reads a comma separated list from the environment variable
+func envVarOrDefaultList(envVar string, defaultVal []string) []string {
+	overrideVal, exists := os.LookupEnv(envVar)
+	if exists && overrideVal != "" {
+		return strings.Split(overrideVal, ",")
+	}
+	return defaultVal
+}
+
+// cookieKeysFromEnv reads cookie keys from the environment variable as a comma separated list
+// of hash and block keys joined by a colon
+func cookieKeysFromEnv(envVar string) []cookieKeysConfig {
+	keys := []cookieKeysConfig{}
+	for _, pair := range envVarOrDefaultList(envVar, []string{}) {
+		splitPair := strings.SplitN(pair, ":", 2)
+		if len(splitPair) != 2 {
+			splitPair = append(splitPair, "")
+		}
+		keys = append(keys, cookieKeysConfig{HashKey: splitPair[0], BlockKey: splitPair[1]})
+	}
+	return keys
+}
+
+// signingKeysFromEnv reads signing keys from the environment variable as a comma separated
+// list of the ID, algorithm, private key file and public key file of each key joined by colons
+func signingKeysFromEnv(envVar string) []signingKeyConfig {
+	keys := []signingKeyConfig{}
+	for _, raw := range envVarOrDefaultList(envVar, []string{}) {
+		splitKey := strings.SplitN(raw, ":", 4)
+		for len(splitKey) < 4 {
+			splitKey = append(splitKey, "")
+		}
+		keys = append(keys, signingKeyConfig{ID: splitKey[0], Algorithm: splitKey[1], PrivateKeyFile: splitKey[2], PublicKeyFile: splitKey[3]})
+	}
+	return keys
+}
+
 func envVarOrDefaultInt(envVar string, defaultVal int) int {
 	overrideVal, exists := os.LookupEnv(envVar)
 	if exists {
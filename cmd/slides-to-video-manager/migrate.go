@@ -1,19 +1,32 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 
+	"cloud.google.com/go/datastore"
 	stackdriver "github.com/TV4/logrus-stackdriver-formatter"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/accesstoken"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/folder"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/identity"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/job"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/scriptrevision"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/sharelink"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/team"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"google.golang.org/api/option"
 
 	_ "github.com/jinzhu/gorm/dialects/mysql"
 )
@@ -34,6 +47,12 @@ var (
 				logger.Info("Run migration")
 				defer logger.Info("Migration completed")
 
+				err := registerRoles(cfg.ACL)
+				if err != nil {
+					logger.Errorf("Unable to load custom roles. %v", err)
+					os.Exit(1)
+				}
+
 				switch cfg.Datastore.Type {
 				case "mysql":
 					logger.Info("Run mysql migration")
@@ -54,20 +73,85 @@ var (
 					db.AutoMigrate(&user.User{})
 					db.AutoMigrate(&project.Project{})
 					db.AutoMigrate(&videosegment.VideoSegment{})
+					db.AutoMigrate(&videosegment.Translation{})
+					db.AutoMigrate(&scriptrevision.ScriptRevision{})
+					db.AutoMigrate(&videooutput.VideoOutput{})
+					db.AutoMigrate(&videooutput.OutputSegment{})
+					db.AutoMigrate(&outputversion.OutputVersion{})
+					db.AutoMigrate(&outputversion.VersionSegment{})
+					db.AutoMigrate(&project.ProjectTag{})
+					db.AutoMigrate(&project.ProjectLanguage{})
+					db.AutoMigrate(&folder.Folder{})
+					db.AutoMigrate(&folder.FolderShare{})
 					db.AutoMigrate(&pdfslideimages.PDFSlideImages{})
 					db.AutoMigrate(&pdfslideimages.SlideAsset{})
 					db.AutoMigrate(&acl.ACL{})
 					db.AutoMigrate(&job.Job{})
+					db.AutoMigrate(&sharelink.ShareLink{})
+					db.AutoMigrate(&team.Team{})
+					db.AutoMigrate(&team.Membership{})
+					db.AutoMigrate(&accesstoken.AccessToken{})
+					db.AutoMigrate(&session.Session{})
+					db.AutoMigrate(&identity.Identity{})
 					db.Model(&pdfslideimages.PDFSlideImages{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
 					db.Model(&videosegment.VideoSegment{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
+					db.Model(&videosegment.Translation{}).AddForeignKey("video_segment_id", "video_segments(id)", "CASCADE", "RESTRICT")
+					db.Model(&scriptrevision.ScriptRevision{}).AddForeignKey("video_segment_id", "video_segments(id)", "CASCADE", "RESTRICT")
+					db.Model(&videooutput.VideoOutput{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
+					db.Model(&videooutput.OutputSegment{}).AddForeignKey("video_output_id", "video_outputs(id)", "CASCADE", "RESTRICT")
+					db.Model(&outputversion.OutputVersion{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
+					db.Model(&outputversion.VersionSegment{}).AddForeignKey("output_version_id", "output_versions(id)", "CASCADE", "RESTRICT")
+					db.Model(&project.ProjectTag{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
+					db.Model(&project.ProjectLanguage{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
+					db.Model(&folder.FolderShare{}).AddForeignKey("folder_id", "folders(id)", "CASCADE", "RESTRICT")
 					db.Model(&pdfslideimages.SlideAsset{}).AddForeignKey("pdf_slide_image_id", "pdf_slide_images(id)", "CASCADE", "RESTRICT")
 					db.Model(&acl.ACL{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
-					db.Model(&acl.ACL{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
+					// Acls of teams are not held by a user
+					db.Model(&acl.ACL{}).RemoveForeignKey("user_id", "users(id)")
 					db.Model(&job.Job{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
 					db.Model(&job.Job{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
+					db.Model(&sharelink.ShareLink{}).AddForeignKey("project_id", "projects(id)", "CASCADE", "RESTRICT")
+					db.Model(&team.Membership{}).AddForeignKey("team_id", "teams(id)", "CASCADE", "RESTRICT")
+					db.Model(&team.Membership{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
+					db.Model(&accesstoken.AccessToken{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
+					db.Model(&session.Session{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
+					db.Model(&identity.Identity{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
 					if db.Error != nil {
 						logger.Errorf("unable to migrate project table. %v", db.Error)
 					}
+					err = migrateSlideAssetPrimaryKey(db, logger)
+					if err != nil {
+						logger.Errorf("unable to migrate primary key of slide assets. %v", err)
+						os.Exit(1)
+					}
+					err = migratePermissions(db, logger)
+					if err != nil {
+						logger.Errorf("unable to migrate roles of acls. %v", err)
+						os.Exit(1)
+					}
+				case googleDatastore:
+					// Datastore has no schema - only the data that changed its meaning is migrated
+					logger.Info("Run google datastore migration")
+					defer logger.Info("google datastore migration complete")
+					var svcAcctOptions []option.ClientOption
+					if cfg.Server.SvcAcctFile != "" {
+						credJSON, err := ioutil.ReadFile(cfg.Server.SvcAcctFile)
+						if err != nil {
+							logger.Errorf("Unable to load slides-to-video-manager cred file. err: %v", err)
+						}
+						svcAcctOptions = append(svcAcctOptions, option.WithCredentialsJSON(credJSON))
+					}
+					datastoreClient, err := datastore.NewClient(context.Background(), cfg.Datastore.GoogleDatastoreConfig.ProjectID, svcAcctOptions...)
+					if err != nil {
+						logger.Errorf("Unable to create datastore client. %v", err)
+						os.Exit(1)
+					}
+					defer datastoreClient.Close()
+					err = migrateDatastorePermissions(context.Background(), datastoreClient, "acl", cfg.Datastore.GoogleDatastoreConfig.FoldersTableName, logger)
+					if err != nil {
+						logger.Errorf("unable to migrate roles of acls. %v", err)
+						os.Exit(1)
+					}
 				default:
 					logger.Errorf("Database defined is not meant to run migration")
 					os.Exit(1)
@@ -79,3 +163,119 @@ var (
 		return migrateCmd
 	}
 )
+
+// migrateSlideAssetPrimaryKey widens the primary key of slide assets from the image to the image
+// and its pdf slide images. AutoMigrate does not alter the primary key of existing tables
+func migrateSlideAssetPrimaryKey(db *gorm.DB, logger *logrus.Logger) error {
+	tableName := db.NewScope(&pdfslideimages.SlideAsset{}).TableName()
+	rows, err := db.Raw("SELECT column_name FROM information_schema.key_column_usage WHERE table_schema = DATABASE() AND table_name = ? AND constraint_name = 'PRIMARY'", tableName).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns := map[string]bool{}
+	for rows.Next() {
+		var column string
+		err = rows.Scan(&column)
+		if err != nil {
+			return err
+		}
+		columns[column] = true
+	}
+	if columns["image_id"] && columns["pdf_slide_image_id"] {
+		return nil
+	}
+	logger.Infof("Migrate primary key of %v to image_id and pdf_slide_image_id", tableName)
+	dropPrimaryKey := ""
+	if len(columns) > 0 {
+		dropPrimaryKey = "DROP PRIMARY KEY, "
+	}
+	return db.Exec(fmt.Sprintf("ALTER TABLE %v %vADD PRIMARY KEY (image_id, pdf_slide_image_id)", tableName, dropPrimaryKey)).Error
+}
+
+// migratePermissions moves the roles of existing acls and folder shares over to the roles of
+// the capability based permission model. Roles that are no longer known are reduced to reader
+func migratePermissions(db *gorm.DB, logger *logrus.Logger) error {
+	var acls []acl.ACL
+	result := db.Find(&acls)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, a := range acls {
+		p, changed := acl.MigratePermission(string(a.Permission))
+		if !changed {
+			continue
+		}
+		logger.Infof("Migrate role of user %v on project %v from %q to %v", a.UserID, a.ProjectID, a.Permission, p)
+		result = db.Model(&acl.ACL{}).Where("id = ?", a.ID).Update("permission", string(p))
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	var shares []folder.FolderShare
+	result = db.Find(&shares)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, s := range shares {
+		p, changed := acl.MigratePermission(s.Permission)
+		if !changed {
+			continue
+		}
+		logger.Infof("Migrate role of user %v on folder %v from %q to %v", s.UserID, s.FolderID, s.Permission, p)
+		result = db.Model(&folder.FolderShare{}).Where("folder_id = ? AND user_id = ?", s.FolderID, s.UserID).Update("permission", string(p))
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// migrateDatastorePermissions is migratePermissions for acls and folders that are kept on
+// Google Datastore. Shares of folders are kept on the folder entity
+func migrateDatastorePermissions(ctx context.Context, client *datastore.Client, aclEntity, folderEntity string, logger *logrus.Logger) error {
+	var acls []acl.ACL
+	keys, err := client.GetAll(ctx, datastore.NewQuery(aclEntity), &acls)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve acls. err: %v", err)
+	}
+	for i, a := range acls {
+		p, changed := acl.MigratePermission(string(a.Permission))
+		if !changed {
+			continue
+		}
+		logger.Infof("Migrate role of user %v on project %v from %q to %v", a.UserID, a.ProjectID, a.Permission, p)
+		acls[i].Permission = p
+		_, err = client.Put(ctx, keys[i], &acls[i])
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+	}
+
+	var folders []folder.Folder
+	keys, err = client.GetAll(ctx, datastore.NewQuery(folderEntity), &folders)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve folders. err: %v", err)
+	}
+	for i, f := range folders {
+		changed := false
+		for j, s := range f.Shares {
+			p, shareChanged := acl.MigratePermission(s.Permission)
+			if !shareChanged {
+				continue
+			}
+			logger.Infof("Migrate role of user %v on folder %v from %q to %v", s.UserID, keys[i].Name, s.Permission, p)
+			folders[i].Shares[j].Permission = string(p)
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		_, err = client.Put(ctx, keys[i], &folders[i])
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+	}
+	return nil
+}
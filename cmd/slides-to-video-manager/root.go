@@ -20,25 +20,41 @@ var (
 	// TODO: Utilize Inmemory queue and inmemory datastores in the future
 	cfg = config{
 		Server: serverConfig{
-			Host:           envVarOrDefault("SERVER_HOST", "0.0.0.0"),
-			Port:           envVarOrDefaultInt("SERVER_PORT", 8080),
-			Scope:          "https://www.googleapis.com/auth/userinfo.email https://www.googleapis.com/auth/drive.metadata.readonly",
-			SvcAcctFile:    envVarOrDefault("SERVER_SVCACCTFILE", ""),
-			ClientID:       envVarOrDefault("SERVER_CLIENTID", ""),
-			ClientSecret:   envVarOrDefault("SERVER_CLIENTSECRET", ""),
-			RedirectURI:    envVarOrDefault("SERVER_REDIRECTURI", "http://localhost:8000/api/v1/callback"),
-			AuthSecret:     envVarOrDefault("SERVER_AUTHSECRET", "secret"),
-			AuthIssuer:     envVarOrDefault("SERVER_AUTHISSUER", "issuer"),
-			AuthExpiryTime: envVarOrDefaultInt("SERVER_AUTHEXPIRYTIME", 3600),
+			Host:                 envVarOrDefault("SERVER_HOST", "0.0.0.0"),
+			Port:                 envVarOrDefaultInt("SERVER_PORT", 8080),
+			Scope:                "https://www.googleapis.com/auth/userinfo.email https://www.googleapis.com/auth/drive.metadata.readonly",
+			SvcAcctFile:          envVarOrDefault("SERVER_SVCACCTFILE", ""),
+			ClientID:             envVarOrDefault("SERVER_CLIENTID", ""),
+			ClientSecret:         envVarOrDefault("SERVER_CLIENTSECRET", ""),
+			RedirectURI:          envVarOrDefault("SERVER_REDIRECTURI", "http://localhost:8000/api/v1/callback"),
+			AuthSecret:           envVarOrDefault("SERVER_AUTHSECRET", "secret"),
+			AuthIssuer:           envVarOrDefault("SERVER_AUTHISSUER", "issuer"),
+			AuthExpiryTime:       envVarOrDefaultInt("SERVER_AUTHEXPIRYTIME", 3600),
+			VersionsToKeep:       envVarOrDefaultInt("SERVER_VERSIONSTOKEEP", 10),
+			TrashRetentionDays:   envVarOrDefaultInt("SERVER_TRASHRETENTIONDAYS", 30),
+			PreviousAuthSecrets:  envVarOrDefaultList("SERVER_PREVIOUSAUTHSECRETS", []string{}),
+			CookieKeys:           cookieKeysFromEnv("SERVER_COOKIEKEYS"),
+			SessionExpiryTime:    envVarOrDefaultInt("SERVER_SESSIONEXPIRYTIME", 30*24*3600),
+			SigningKeys:          signingKeysFromEnv("SERVER_SIGNINGKEYS"),
+			LoginRedirectOrigins: envVarOrDefaultList("SERVER_LOGINREDIRECTORIGINS", []string{}),
 		},
 		Datastore: datastoreConfig{
 			Type: envVarOrDefault("DATASTORE_TYPE", "google_datastore"),
 			GoogleDatastoreConfig: &googleDatastoreConfig{
-				ProjectID:              envVarOrDefault("DATASTORE_GOOGLEDATASTORE_PROJECTID", ""),
-				UserTableName:          envVarOrDefault("DATASTORE_GOOGLEDATASTORE_USERTABLENAME", "UserTable"),
-				ProjectTableName:       envVarOrDefault("DATASTORE_GOOGLEDATASTORE_PROJECTTABLENAME", "ProjectTable"),
-				PDFSlidesTableName:     envVarOrDefault("DATASTORE_GOOGLEDATASTORE_PDFSLIDESTABLENAME", "PDFSlideTable"),
-				VideoSegmentsTableName: envVarOrDefault("DATASTORE_GOOGLEDATASTORE_VIDEOSEGMENTSTABLENAME", "VideoSegmentsTable"),
+				ProjectID:                envVarOrDefault("DATASTORE_GOOGLEDATASTORE_PROJECTID", ""),
+				UserTableName:            envVarOrDefault("DATASTORE_GOOGLEDATASTORE_USERTABLENAME", "UserTable"),
+				ProjectTableName:         envVarOrDefault("DATASTORE_GOOGLEDATASTORE_PROJECTTABLENAME", "ProjectTable"),
+				PDFSlidesTableName:       envVarOrDefault("DATASTORE_GOOGLEDATASTORE_PDFSLIDESTABLENAME", "PDFSlideTable"),
+				VideoSegmentsTableName:   envVarOrDefault("DATASTORE_GOOGLEDATASTORE_VIDEOSEGMENTSTABLENAME", "VideoSegmentsTable"),
+				ScriptRevisionsTableName: envVarOrDefault("DATASTORE_GOOGLEDATASTORE_SCRIPTREVISIONSTABLENAME", "ScriptRevisionsTable"),
+				VideoOutputsTableName:    envVarOrDefault("DATASTORE_GOOGLEDATASTORE_VIDEOOUTPUTSTABLENAME", "VideoOutputsTable"),
+				OutputVersionsTableName:  envVarOrDefault("DATASTORE_GOOGLEDATASTORE_OUTPUTVERSIONSTABLENAME", "OutputVersionsTable"),
+				FoldersTableName:         envVarOrDefault("DATASTORE_GOOGLEDATASTORE_FOLDERSTABLENAME", "FoldersTable"),
+				ShareLinksTableName:      envVarOrDefault("DATASTORE_GOOGLEDATASTORE_SHARELINKSTABLENAME", "ShareLinksTable"),
+				TeamsTableName:           envVarOrDefault("DATASTORE_GOOGLEDATASTORE_TEAMSTABLENAME", "TeamsTable"),
+				AccessTokensTableName:    envVarOrDefault("DATASTORE_GOOGLEDATASTORE_ACCESSTOKENSTABLENAME", "AccessTokensTable"),
+				SessionsTableName:        envVarOrDefault("DATASTORE_GOOGLEDATASTORE_SESSIONSTABLENAME", "SessionsTable"),
+				IdentitiesTableName:      envVarOrDefault("DATASTORE_GOOGLEDATASTORE_IDENTITIESTABLENAME", "IdentitiesTable"),
 			},
 			MySQLConfig: &mysqlConfig{
 				User:     envVarOrDefault("DATASTORE_MYSQL_USER", "user"),
@@ -89,6 +105,7 @@ var (
 		rootCmd.AddCommand(configCmd())
 		rootCmd.AddCommand(serverCmd())
 		rootCmd.AddCommand(migrateCmd())
+		rootCmd.AddCommand(archiveCmd())
 		return rootCmd
 	}
 )
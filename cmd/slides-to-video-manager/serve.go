@@ -8,18 +8,28 @@ import (
 	"os"
 	"time"
 
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/accesstoken"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/folder"
 	h "github.com/hairizuanbinnoorazman/slides-to-video-manager/handlers"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/identity"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/imageimporter"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/job"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/queue"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/scriptrevision"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/sharelink"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/team"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/trash"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videoconcater"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videogenerator"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 	"github.com/jinzhu/gorm"
 	"gopkg.in/go-playground/validator.v9"
@@ -58,6 +68,11 @@ var (
 					logger.Errorf("Error with loading configuration. %v", err)
 					os.Exit(1)
 				}
+				err = registerRoles(cfg.ACL)
+				if err != nil {
+					logger.Errorf("Error with loading custom roles. %v", err)
+					os.Exit(1)
+				}
 
 				var svcAcctOptions []option.ClientOption
 				if cfg.Server.SvcAcctFile != "" {
@@ -96,6 +111,15 @@ var (
 				var videoSegmentsStore videosegment.Store
 				var aclStore acl.Store
 				var jobStore job.Store
+				var scriptRevisionStore scriptrevision.Store
+				var videoOutputStore videooutput.Store
+				var outputVersionStore outputversion.Store
+				var folderStore folder.Store
+				var shareLinkStore sharelink.Store
+				var teamStore team.Store
+				var accessTokenStore accesstoken.Store
+				var sessionStore session.Store
+				var identityStore identity.Store
 				if cfg.Datastore.Type == googleDatastore {
 					datastoreClient, err := datastore.NewClient(context.Background(), cfg.Datastore.GoogleDatastoreConfig.ProjectID, svcAcctOptions...)
 					if err != nil {
@@ -107,6 +131,15 @@ var (
 					userStore = user.NewGoogleDatastore(datastoreClient, cfg.Datastore.GoogleDatastoreConfig.UserTableName)
 					videoSegmentsStore = videosegment.NewGoogleDatastore(datastoreClient, cfg.Datastore.GoogleDatastoreConfig.ProjectTableName, cfg.Datastore.GoogleDatastoreConfig.VideoSegmentsTableName)
 					aclStore, _ = acl.NewGoogleDatastore(logger, datastoreClient, "acl")
+					scriptRevisionStore = scriptrevision.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.ProjectTableName, cfg.Datastore.GoogleDatastoreConfig.ScriptRevisionsTableName)
+					videoOutputStore = videooutput.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.ProjectTableName, cfg.Datastore.GoogleDatastoreConfig.VideoOutputsTableName)
+					outputVersionStore = outputversion.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.ProjectTableName, cfg.Datastore.GoogleDatastoreConfig.OutputVersionsTableName)
+					folderStore = folder.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.FoldersTableName)
+					shareLinkStore = sharelink.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.ProjectTableName, cfg.Datastore.GoogleDatastoreConfig.ShareLinksTableName)
+					teamStore = team.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.TeamsTableName)
+					accessTokenStore = accesstoken.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.UserTableName, cfg.Datastore.GoogleDatastoreConfig.AccessTokensTableName)
+					sessionStore = session.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.SessionsTableName)
+					identityStore = identity.NewGoogleDatastore(logger, datastoreClient, cfg.Datastore.GoogleDatastoreConfig.IdentitiesTableName)
 				} else if cfg.Datastore.Type == mysqlDatastore {
 					connectionString := fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?parseTime=True", cfg.Datastore.MySQLConfig.User, cfg.Datastore.MySQLConfig.Password, cfg.Datastore.MySQLConfig.Host, cfg.Datastore.MySQLConfig.Port, cfg.Datastore.MySQLConfig.DBName)
 					db, err := gorm.Open("mysql", connectionString)
@@ -120,12 +153,23 @@ var (
 					videoSegmentsStore = videosegment.NewMySQL(logger, db)
 					aclStore = acl.NewMySQL(logger, db)
 					jobStore = job.NewMySQL(logger, db)
+					scriptRevisionStore = scriptrevision.NewMySQL(logger, db)
+					videoOutputStore = videooutput.NewMySQL(logger, db)
+					outputVersionStore = outputversion.NewMySQL(logger, db)
+					folderStore = folder.NewMySQL(logger, db)
+					shareLinkStore = sharelink.NewMySQL(logger, db)
+					teamStore = team.NewMySQL(logger, db)
+					accessTokenStore = accesstoken.NewMySQL(logger, db)
+					sessionStore = session.NewMySQL(logger, db)
+					identityStore = identity.NewMySQL(logger, db)
 				}
 
 				if projectStore == nil || pdfSlideImagesStore == nil || userStore == nil || videoSegmentsStore == nil {
 					logger.Errorf("Some of the database instantiation is nil")
 					os.Exit(1)
 				}
+				// Members of teams inherit the access of their teams on projects
+				aclStore = team.NewACLStore(aclStore, teamStore)
 
 				var pdfToImageQueue queue.Queue
 				var imageToVideoQueue queue.Queue
@@ -160,26 +204,65 @@ var (
 					os.Exit(1)
 				}
 
+				cookieKeys, err := cfg.Server.cookieKeys()
+				if err != nil {
+					logger.Errorf("Unable to read cookie keys. %v", err)
+					os.Exit(1)
+				}
+				if len(cookieKeys) == 0 {
+					logger.Warning("No cookie keys configured - users are signed out whenever the manager restarts")
+					cookieKeys = []services.CookieKeys{{
+						HashKey:  securecookie.GenerateRandomKey(64),
+						BlockKey: securecookie.GenerateRandomKey(32),
+					}}
+				}
+
+				oidcProviders, err := cfg.Server.oidcProviders()
+				if err != nil {
+					logger.Errorf("Unable to configure oidc providers. %v", err)
+					os.Exit(1)
+				}
+
+				signingKeys, err := cfg.Server.signingKeys()
+				if err != nil {
+					logger.Errorf("Unable to read signing keys. %v", err)
+					os.Exit(1)
+				}
+
 				auth := services.Auth{
-					Secret:     cfg.Server.AuthSecret,
-					Issuer:     cfg.Server.AuthIssuer,
-					ExpiryTime: cfg.Server.AuthExpiryTime,
-					HashKey:    securecookie.GenerateRandomKey(64),
-					BlockKey:   securecookie.GenerateRandomKey(32),
-					CookieName: "slidestovideo",
+					Secret:             cfg.Server.AuthSecret,
+					PreviousSecrets:    cfg.Server.PreviousAuthSecrets,
+					SigningKeys:        signingKeys,
+					Issuer:             cfg.Server.AuthIssuer,
+					ExpiryTime:         cfg.Server.AuthExpiryTime,
+					HashKey:            cookieKeys[0].HashKey,
+					BlockKey:           cookieKeys[0].BlockKey,
+					PreviousCookieKeys: cookieKeys[1:],
+					CookieName:         "slidestovideo",
+					AccessTokens:       accessTokenStore,
+					Sessions:           sessionStore,
+					SessionExpiryTime:  cfg.Server.SessionExpiryTime,
 				}
 
-				pdfSlideImporter := imageimporter.NewBasicPDFImporter(pdfToImageQueue)
-				videoGenerator := videogenerator.NewBasic(imageToVideoQueue, videoSegmentsStore)
-				videoConcater := videoconcater.NewBasic(concatQueue, projectStore, auth)
+				pdfSlideImporter := imageimporter.NewBasicPDFImporter(pdfToImageQueue, auth)
+				videoGenerator := videogenerator.NewBasic(imageToVideoQueue, videoSegmentsStore, auth)
+				videoConcater := videoconcater.NewBasic(concatQueue, projectStore, videoOutputStore, auth)
 
-				jobProcessor, err := job.NewProcessor(logger, jobStore, projectStore, videoConcater)
+				jobProcessor, err := job.NewProcessor(logger, jobStore, projectStore, videoOutputStore, videoConcater)
 				if err != nil {
 					logger.Errorf("Unable to start job processor. Err - %v", err)
 					os.Exit(1)
 				}
 				go jobProcessor.Start()
 
+				trashRetention := time.Duration(cfg.Server.TrashRetentionDays) * 24 * time.Hour
+				trashPurger, err := trash.NewPurger(logger, projectStore, videoOutputStore, outputVersionStore, slideToVideoStorage, project.Folders{PDF: cfg.BlobStorage.GCS.PDFFolder, Images: "images"}, trashRetention)
+				if err != nil {
+					logger.Errorf("Unable to start trash purger. Err - %v", err)
+					os.Exit(1)
+				}
+				go trashPurger.Start()
+
 				r := mux.NewRouter()
 				r.Handle("/status", h.Status{
 					Logger: logger,
@@ -190,16 +273,22 @@ var (
 				r.Handle("/readyz", h.Status{
 					Logger: logger,
 				})
+				r.Handle("/.well-known/jwks.json", h.JWKS{
+					Logger: logger,
+					Auth:   auth,
+				}).Methods("GET")
 
 				s := r.PathPrefix("/api/v1").Subrouter()
 				// Project based routes
 				s.Handle("/project", h.RequireJWTAuth{
-					Auth:   auth,
-					Logger: logger,
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
 					NextHandler: h.CreateProject{
 						Logger:       logger,
 						ProjectStore: projectStore,
 						ACLStore:     aclStore,
+						TeamStore:    teamStore,
 					},
 				}).Methods("POST")
 				s.Handle("/projects", h.RequireJWTAuth{
@@ -208,96 +297,1007 @@ var (
 					NextHandler: h.GetAllProjects{
 						Logger:       logger,
 						ProjectStore: projectStore,
+						TeamStore:    teamStore,
 					},
 				}).Methods("GET")
 				s.Handle("/project/{project_id}", h.RequireJWTAuth{
 					Auth:   auth,
 					Logger: logger,
-					NextHandler: h.GetProject{
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetProject{
+							Logger:       logger,
+							ProjectStore: projectStore,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					ServiceScope: services.UpdateProjectStatus,
+					NextHandler: h.RequireActiveProject{
 						Logger:       logger,
 						ProjectStore: projectStore,
-						ACLStore:     aclStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateProject{
+								Logger:             logger,
+								ProjectStore:       projectStore,
+								OutputVersionStore: outputVersionStore,
+								Blobstorage:        slideToVideoStorage,
+								BucketFolderName:   cfg.BlobStorage.GCS.PDFFolder,
+								VersionsToKeep:     cfg.Server.VersionsToKeep,
+							},
+						},
+					},
+				}).Methods("PUT")
+				s.Handle("/project/{project_id}:move", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Share,
+							NextHandler: h.MoveProject{
+								Logger:       logger,
+								ProjectStore: projectStore,
+								FolderStore:  folderStore,
+								ACLStore:     aclStore,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/tags", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateProjectTags{
+								Logger:       logger,
+								ProjectStore: projectStore,
+							},
+						},
+					},
+				}).Methods("PUT")
+				s.Handle("/project/{project_id}/settings", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateProjectSettings{
+								Logger:       logger,
+								ProjectStore: projectStore,
+							},
+						},
+					},
+				}).Methods("PUT")
+				s.Handle("/project/{project_id}/music", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UploadBackgroundMusic{
+								Logger:           logger,
+								ProjectStore:     projectStore,
+								VideoOutputStore: videoOutputStore,
+								Blobstorage:      slideToVideoStorage,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/music", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateBackgroundMusic{
+								Logger:           logger,
+								ProjectStore:     projectStore,
+								VideoOutputStore: videoOutputStore,
+							},
+						},
+					},
+				}).Methods("PUT", "DELETE")
+				s.Handle("/project/{project_id}/languages", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateProjectLanguages{
+								Logger:       logger,
+								ProjectStore: projectStore,
+							},
+						},
+					},
+				}).Methods("PUT")
+				s.Handle("/project/{project_id}/collaborators", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetCollaborators{
+							Logger:    logger,
+							ACLStore:  aclStore,
+							UserStore: userStore,
+						},
 					},
 				}).Methods("GET")
-				s.Handle("/project/{project_id}", h.RequireJWTAuth{
+				s.Handle("/project/{project_id}/collaborators", h.RequireJWTAuth{
 					Auth:   auth,
 					Logger: logger,
-					NextHandler: h.UpdateProject{
+					NextHandler: h.RequireActiveProject{
 						Logger:       logger,
 						ProjectStore: projectStore,
-						ACLStore:     aclStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Share,
+							NextHandler: h.InviteCollaborator{
+								Logger:    logger,
+								ACLStore:  aclStore,
+								UserStore: userStore,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/collaborators/{user_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Share,
+							NextHandler: h.UpdateCollaborator{
+								Logger:   logger,
+								ACLStore: aclStore,
+							},
+						},
 					},
 				}).Methods("PUT")
-				s.Handle("/project/{project_id}:concat", h.RequireJWTAuth{
+				s.Handle("/project/{project_id}/collaborators/{user_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.Share,
+						NextHandler: h.RemoveCollaborator{
+							Logger:   logger,
+							ACLStore: aclStore,
+						},
+					},
+				}).Methods("DELETE")
+				s.Handle("/team", h.RequireJWTAuth{
 					Auth:   auth,
 					Logger: logger,
-					NextHandler: h.StartVideoConcat{
-						Logger:        logger,
-						ProjectStore:  projectStore,
-						VideoConcater: videoConcater,
-						ACLStore:      aclStore,
+					NextHandler: h.CreateTeam{
+						Logger:    logger,
+						TeamStore: teamStore,
 					},
 				}).Methods("POST")
-				s.Handle("/project/{project_id}:generate-video", h.RequireJWTAuth{
+				s.Handle("/teams", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.GetTeams{
+						Logger:    logger,
+						TeamStore: teamStore,
+					},
+				}).Methods("GET")
+				s.Handle("/team/{team_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.GetTeam{
+						Logger:    logger,
+						TeamStore: teamStore,
+					},
+				}).Methods("GET")
+				s.Handle("/team/{team_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.UpdateTeam{
+						Logger:    logger,
+						TeamStore: teamStore,
+					},
+				}).Methods("PUT")
+				s.Handle("/team/{team_id}/members", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.SetTeamMember{
+						Logger:    logger,
+						TeamStore: teamStore,
+						UserStore: userStore,
+					},
+				}).Methods("POST")
+				s.Handle("/team/{team_id}/members/{user_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RemoveTeamMember{
+						Logger:    logger,
+						TeamStore: teamStore,
+					},
+				}).Methods("DELETE")
+				s.Handle("/project/{project_id}/teams", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetProjectTeams{
+							Logger:    logger,
+							ACLStore:  aclStore,
+							TeamStore: teamStore,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/teams/{team_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Share,
+							NextHandler: h.ShareProjectWithTeam{
+								Logger:    logger,
+								ACLStore:  aclStore,
+								TeamStore: teamStore,
+							},
+						},
+					},
+				}).Methods("PUT")
+				s.Handle("/project/{project_id}/teams/{team_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.Share,
+						NextHandler: h.UnshareProjectWithTeam{
+							Logger:   logger,
+							ACLStore: aclStore,
+						},
+					},
+				}).Methods("DELETE")
+				s.Handle("/project/{project_id}/sharelinks", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Share,
+							NextHandler: h.CreateShareLink{
+								Logger:           logger,
+								VideoOutputStore: videoOutputStore,
+								ShareLinkStore:   shareLinkStore,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/sharelinks", h.RequireJWTAuth{
 					Auth:   auth,
 					Logger: logger,
-					NextHandler: h.StartProjectGenerateVideo{
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.Share,
+						NextHandler: h.GetShareLinks{
+							Logger:         logger,
+							ShareLinkStore: shareLinkStore,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/sharelinks/{sharelink_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.Share,
+						NextHandler: h.RevokeShareLink{
+							Logger:         logger,
+							ShareLinkStore: shareLinkStore,
+						},
+					},
+				}).Methods("DELETE")
+				s.Handle("/share/{token}", h.OpenShareLink{
+					Logger:           logger,
+					Auth:             auth,
+					ProjectStore:     projectStore,
+					VideoOutputStore: videoOutputStore,
+					ShareLinkStore:   shareLinkStore,
+				}).Methods("GET", "POST")
+				s.Handle("/share/{token}/video", h.RequireShareLink{
+					Logger:         logger,
+					Auth:           auth,
+					ShareLinkStore: shareLinkStore,
+					NextHandler: h.DownloadSharedVideo{
 						Logger:             logger,
-						JobStore:           jobStore,
 						ProjectStore:       projectStore,
-						ACLStore:           aclStore,
-						VideoSegmentsStore: videoSegmentsStore,
-						VideoGenerator:     videoGenerator,
+						VideoOutputStore:   videoOutputStore,
+						OutputVersionStore: outputVersionStore,
+						StorageClient:      slideToVideoStorage,
+					},
+				}).Methods("GET")
+				s.Handle("/share/{token}/subtitles/{format}", h.RequireShareLink{
+					Logger:         logger,
+					Auth:           auth,
+					ShareLinkStore: shareLinkStore,
+					NextHandler: h.DownloadSharedSubtitles{
+						Logger:             logger,
+						ProjectStore:       projectStore,
+						VideoOutputStore:   videoOutputStore,
+						OutputVersionStore: outputVersionStore,
+						StorageClient:      slideToVideoStorage,
+					},
+				}).Methods("GET")
+				s.Handle("/folder", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.CreateFolder{
+						Logger:      logger,
+						FolderStore: folderStore,
 					},
 				}).Methods("POST")
-				s.Handle("/project/{project_id}/pdfslideimages", h.CreatePDFSlideImages{
-					Logger:              logger,
-					PDFSlideImagesStore: pdfSlideImagesStore,
-					Blobstorage:         slideToVideoStorage,
-					BucketFolderName:    cfg.BlobStorage.GCS.PDFFolder,
-					PDFSlideImporter:    pdfSlideImporter,
+				s.Handle("/folders", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.GetFolders{
+						Logger:      logger,
+						FolderStore: folderStore,
+					},
+				}).Methods("GET")
+				s.Handle("/folder/{folder_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.GetFolder{
+						Logger:      logger,
+						FolderStore: folderStore,
+					},
+				}).Methods("GET")
+				s.Handle("/folder/{folder_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.UpdateFolder{
+						Logger:       logger,
+						FolderStore:  folderStore,
+						ProjectStore: projectStore,
+						ACLStore:     aclStore,
+					},
+				}).Methods("PUT")
+				s.Handle("/folder/{folder_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.DeleteFolder{
+						Logger:       logger,
+						FolderStore:  folderStore,
+						ProjectStore: projectStore,
+					},
+				}).Methods("DELETE")
+				s.Handle("/folder/{folder_id}/share", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.ShareFolder{
+						Logger:       logger,
+						FolderStore:  folderStore,
+						ProjectStore: projectStore,
+						ACLStore:     aclStore,
+						UserStore:    userStore,
+					},
+				}).Methods("PUT")
+				s.Handle("/folder/{folder_id}/share/{user_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.UnshareFolder{
+						Logger:       logger,
+						FolderStore:  folderStore,
+						ProjectStore: projectStore,
+						ACLStore:     aclStore,
+					},
+				}).Methods("DELETE")
+				s.Handle("/project/{project_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.Delete,
+						NextHandler: h.DeleteProject{
+							Logger:         logger,
+							ProjectStore:   projectStore,
+							TrashRetention: trashRetention,
+						},
+					},
+				}).Methods("DELETE")
+				s.Handle("/project/{project_id}:restore", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.Delete,
+						NextHandler: h.RestoreProject{
+							Logger:         logger,
+							ProjectStore:   projectStore,
+							TrashRetention: trashRetention,
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}:concat", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Concat,
+							NextHandler: h.StartVideoConcat{
+								Logger:           logger,
+								ProjectStore:     projectStore,
+								VideoOutputStore: videoOutputStore,
+								VideoConcater:    videoConcater,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}:generate-video", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Generate,
+							NextHandler: h.StartProjectGenerateVideo{
+								Logger:             logger,
+								JobStore:           jobStore,
+								ProjectStore:       projectStore,
+								VideoSegmentsStore: videoSegmentsStore,
+								VideoGenerator:     videoGenerator,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/videooutput", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Concat,
+							NextHandler: h.CreateVideoOutput{
+								Logger:            logger,
+								ProjectStore:      projectStore,
+								VideoOutputStore:  videoOutputStore,
+								VideoSegmentStore: videoSegmentsStore,
+							},
+						},
+					},
 				}).Methods("POST")
-				s.Handle("/project/{project_id}/pdfslideimages/{pdfslideimages_id}", h.UpdatePDFSlideImages{
-					Logger:              logger,
-					PDFSlideImagesStore: pdfSlideImagesStore,
-					VideoSegmentStore:   videoSegmentsStore,
+				s.Handle("/project/{project_id}/videooutputs", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetVideoOutputs{
+							Logger:           logger,
+							VideoOutputStore: videoOutputStore,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/videooutput/{videooutput_id}", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					ServiceScope: services.UpdateVideoOutputStatus,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Concat,
+							NextHandler: h.UpdateVideoOutput{
+								Logger:             logger,
+								ProjectStore:       projectStore,
+								VideoOutputStore:   videoOutputStore,
+								OutputVersionStore: outputVersionStore,
+								Blobstorage:        slideToVideoStorage,
+								BucketFolderName:   cfg.BlobStorage.GCS.PDFFolder,
+								VersionsToKeep:     cfg.Server.VersionsToKeep,
+							},
+						},
+					},
 				}).Methods("PUT")
-				s.Handle("/project/{project_id}/pdfslideimages/{pdfslideimages_id}", h.GetPDFSlideImages{
-					Logger:              logger,
-					PDFSlideImagesStore: pdfSlideImagesStore,
+				s.Handle("/project/{project_id}/videooutput/{videooutput_id}/music", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UploadBackgroundMusic{
+								Logger:           logger,
+								ProjectStore:     projectStore,
+								VideoOutputStore: videoOutputStore,
+								Blobstorage:      slideToVideoStorage,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/videooutput/{videooutput_id}/music", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateBackgroundMusic{
+								Logger:           logger,
+								ProjectStore:     projectStore,
+								VideoOutputStore: videoOutputStore,
+							},
+						},
+					},
+				}).Methods("PUT", "DELETE")
+				s.Handle("/project/{project_id}/videooutput/{videooutput_id}:generate", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Concat,
+							NextHandler: h.StartVideoOutputGeneration{
+								Logger:           logger,
+								ProjectStore:     projectStore,
+								VideoOutputStore: videoOutputStore,
+								VideoConcater:    videoConcater,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/outputversions", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetOutputVersions{
+							Logger:             logger,
+							OutputVersionStore: outputVersionStore,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/outputversion/{outputversion_id}:download", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.DownloadOutputVersion{
+							Logger:             logger,
+							OutputVersionStore: outputVersionStore,
+							StorageClient:      slideToVideoStorage,
+						},
+					},
 				}).Methods("GET")
-				s.Handle("/project/{project_id}/videosegment", h.CreateVideoSegment{
-					Logger:            logger,
-					VideoSegmentStore: videoSegmentsStore,
+				s.Handle("/project/{project_id}/subtitles/{format}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.DownloadSubtitles{
+							Logger:             logger,
+							ProjectStore:       projectStore,
+							VideoOutputStore:   videoOutputStore,
+							OutputVersionStore: outputVersionStore,
+							StorageClient:      slideToVideoStorage,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/videooutput/{videooutput_id}/subtitles/{format}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.DownloadSubtitles{
+							Logger:             logger,
+							ProjectStore:       projectStore,
+							VideoOutputStore:   videoOutputStore,
+							OutputVersionStore: outputVersionStore,
+							StorageClient:      slideToVideoStorage,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/outputversion/{outputversion_id}/subtitles/{format}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.DownloadSubtitles{
+							Logger:             logger,
+							ProjectStore:       projectStore,
+							VideoOutputStore:   videoOutputStore,
+							OutputVersionStore: outputVersionStore,
+							StorageClient:      slideToVideoStorage,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/outputversion/{outputversion_id}:publish", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Publish,
+							NextHandler: h.PublishOutputVersion{
+								Logger:             logger,
+								OutputVersionStore: outputVersionStore,
+							},
+						},
+					},
 				}).Methods("POST")
-				s.Handle("/project/{project_id}/videosegment/{videosegment_id}", h.UpdateVideoSegment{
-					Logger:            logger,
-					VideoSegmentStore: videoSegmentsStore,
+				s.Handle("/project/{project_id}:clone", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.View,
+							NextHandler: h.CloneProject{
+								Logger:              logger,
+								ProjectStore:        projectStore,
+								PDFSlideImagesStore: pdfSlideImagesStore,
+								VideoSegmentStore:   videoSegmentsStore,
+								ACLStore:            aclStore,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}:export", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.View,
+							NextHandler: h.ExportProject{
+								Logger:           logger,
+								ProjectStore:     projectStore,
+								Blobstorage:      slideToVideoStorage,
+								BucketFolderName: cfg.BlobStorage.GCS.PDFFolder,
+							},
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project:import", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.ImportProject{
+						Logger:              logger,
+						ProjectStore:        projectStore,
+						PDFSlideImagesStore: pdfSlideImagesStore,
+						VideoSegmentStore:   videoSegmentsStore,
+						ACLStore:            aclStore,
+						Blobstorage:         slideToVideoStorage,
+						BucketFolderName:    cfg.BlobStorage.GCS.PDFFolder,
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/pdfslideimages", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.CreatePDFSlideImages{
+								Logger:              logger,
+								ProjectStore:        projectStore,
+								PDFSlideImagesStore: pdfSlideImagesStore,
+								Blobstorage:         slideToVideoStorage,
+								BucketFolderName:    cfg.BlobStorage.GCS.PDFFolder,
+								PDFSlideImporter:    pdfSlideImporter,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/pdfslideimages/{pdfslideimages_id}", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					ServiceScope: services.UpdatePDFSlideImagesStatus,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdatePDFSlideImages{
+								Logger:              logger,
+								PDFSlideImagesStore: pdfSlideImagesStore,
+								VideoSegmentStore:   videoSegmentsStore,
+								Blobstorage:         slideToVideoStorage,
+								BucketFolderName:    cfg.BlobStorage.GCS.PDFFolder,
+							},
+						},
+					},
 				}).Methods("PUT")
-				s.Handle("/project/{project_id}/videosegment/{videosegment_id}", h.GetVideoSegment{
-					Logger:            logger,
-					VideoSegmentStore: videoSegmentsStore,
+				s.Handle("/project/{project_id}/pdfslideimages/{pdfslideimages_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetPDFSlideImages{
+							Logger:              logger,
+							PDFSlideImagesStore: pdfSlideImagesStore,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/videosegment", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.CreateVideoSegment{
+								Logger:            logger,
+								VideoSegmentStore: videoSegmentsStore,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/videosegment/{videosegment_id}", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					ServiceScope: services.UpdateVideoSegmentStatus,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateVideoSegment{
+								Logger:              logger,
+								VideoSegmentStore:   videoSegmentsStore,
+								ScriptRevisionStore: scriptRevisionStore,
+							},
+						},
+					},
+				}).Methods("PUT")
+				s.Handle("/project/{project_id}/videosegment/{videosegment_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetVideoSegment{
+							Logger:            logger,
+							VideoSegmentStore: videoSegmentsStore,
+						},
+					},
+				}).Methods("GET")
+				s.Handle("/project/{project_id}/videosegment/{videosegment_id}/voice", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateVideoSegmentVoice{
+								Logger:            logger,
+								ProjectStore:      projectStore,
+								VideoSegmentStore: videoSegmentsStore,
+							},
+						},
+					},
+				}).Methods("PUT")
+				s.Handle("/project/{project_id}/videosegment/{videosegment_id}/translation/{language}", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.UpdateVideoSegmentTranslation{
+								Logger:            logger,
+								ProjectStore:      projectStore,
+								VideoSegmentStore: videoSegmentsStore,
+							},
+						},
+					},
+				}).Methods("PUT", "DELETE")
+				s.Handle("/project/{project_id}/videosegment/{videosegment_id}/scriptrevision", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.GetScriptRevisions{
+							Logger:              logger,
+							ScriptRevisionStore: scriptRevisionStore,
+						},
+					},
 				}).Methods("GET")
-				s.Handle("/project/{project_id}/videosegment/{videosegment_id}:generate", h.StartVideoSegmentGeneration{
-					Logger:            logger,
-					VideoSegmentStore: videoSegmentsStore,
-					VideoGenerator:    videoGenerator,
+				s.Handle("/project/{project_id}/videosegment/{videosegment_id}/scriptrevision/{revision}:revert", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.EditScript,
+							NextHandler: h.RevertScriptRevision{
+								Logger:              logger,
+								VideoSegmentStore:   videoSegmentsStore,
+								ScriptRevisionStore: scriptRevisionStore,
+							},
+						},
+					},
+				}).Methods("POST")
+				s.Handle("/project/{project_id}/videosegment/{videosegment_id}:generate", h.RequireJWTAuth{
+					Auth:         auth,
+					Logger:       logger,
+					ProjectWrite: true,
+					NextHandler: h.RequireActiveProject{
+						Logger:       logger,
+						ProjectStore: projectStore,
+						NextHandler: h.RequireProjectACL{
+							Logger:     logger,
+							ACLStore:   aclStore,
+							Capability: acl.Generate,
+							NextHandler: h.StartVideoSegmentGeneration{
+								Logger:            logger,
+								ProjectStore:      projectStore,
+								VideoSegmentStore: videoSegmentsStore,
+								VideoGenerator:    videoGenerator,
+							},
+						},
+					},
 				}).Methods("POST")
 				// Asset retriver routes
 				s.Handle("/project/{project_id}/video/{video_id}", h.RequireJWTAuth{
 					Auth:   auth,
 					Logger: logger,
-					NextHandler: h.DownloadVideo{
-						Logger:        logger,
-						StorageClient: slideToVideoStorage,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.DownloadVideo{
+							Logger:        logger,
+							StorageClient: slideToVideoStorage,
+						},
 					},
 				}).Methods("GET")
 				s.Handle("/project/{project_id}/image/{image_id}", h.RequireJWTAuth{
 					Auth:   auth,
 					Logger: logger,
-					NextHandler: h.DownloadImage{
-						Logger:        logger,
-						StorageClient: slideToVideoStorage,
+					NextHandler: h.RequireProjectACL{
+						Logger:     logger,
+						ACLStore:   aclStore,
+						Capability: acl.View,
+						NextHandler: h.DownloadImage{
+							Logger:        logger,
+							StorageClient: slideToVideoStorage,
+						},
 					},
 				}).Methods("GET")
 
@@ -306,6 +1306,30 @@ var (
 					Logger:    logger,
 					UserStore: userStore,
 				}).Methods("GET")
+				s.Handle("/users/tokens", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.CreateAccessToken{
+						Logger:           logger,
+						AccessTokenStore: accessTokenStore,
+					},
+				}).Methods("POST")
+				s.Handle("/users/tokens", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.GetAccessTokens{
+						Logger:           logger,
+						AccessTokenStore: accessTokenStore,
+					},
+				}).Methods("GET")
+				s.Handle("/users/tokens/{token_id}", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.RevokeAccessToken{
+						Logger:           logger,
+						AccessTokenStore: accessTokenStore,
+					},
+				}).Methods("DELETE")
 				s.Handle("/users/register", h.CreateUser{
 					Logger:    logger,
 					UserStore: userStore,
@@ -319,14 +1343,36 @@ var (
 					UserStore: userStore,
 				}).Methods("POST")
 				s.Handle("/users/resetpassword", h.ResetPassword{
-					Logger:    logger,
-					UserStore: userStore,
+					Logger:       logger,
+					UserStore:    userStore,
+					SessionStore: sessionStore,
 				}).Methods("POST")
+				s.Handle("/users/password", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.ChangePassword{
+						Logger:       logger,
+						UserStore:    userStore,
+						SessionStore: sessionStore,
+					},
+				}).Methods("PUT")
 				s.Handle("/login", h.Login{
 					Logger:    logger,
 					UserStore: userStore,
 					Auth:      auth,
 				}).Methods("POST")
+				s.Handle("/token/refresh", h.RefreshToken{
+					Logger: logger,
+					Auth:   auth,
+				}).Methods("POST")
+				s.Handle("/logout", h.RequireJWTAuth{
+					Auth:   auth,
+					Logger: logger,
+					NextHandler: h.Logout{
+						Logger: logger,
+						Auth:   auth,
+					},
+				}).Methods("POST")
 				s.Handle("/connect/google", h.GoogleLogin{
 					Logger:      logger,
 					ClientID:    cfg.Server.ClientID,
@@ -342,6 +1388,19 @@ var (
 					Auth:         auth,
 					UserStore:    userStore,
 				})
+				s.Handle("/connect/oidc/{provider}", h.OIDCLogin{
+					Logger:          logger,
+					Auth:            auth,
+					Providers:       oidcProviders,
+					RedirectOrigins: cfg.Server.LoginRedirectOrigins,
+				}).Methods("GET")
+				s.Handle("/callback/oidc/{provider}", h.OIDCCallback{
+					Logger:        logger,
+					Auth:          auth,
+					Providers:     oidcProviders,
+					UserStore:     userStore,
+					IdentityStore: identityStore,
+				}).Methods("GET")
 
 				// cors := handlers.CORS(
 				// 	handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "Set-Cookie"}),
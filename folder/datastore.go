@@ -0,0 +1,110 @@
+package folder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger     logger.Logger
+	entityName string
+	client     *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:     logger,
+		client:     ds,
+		entityName: en,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e Folder) error {
+	newKey := datastore.NameKey(g.entityName, e.ID, nil)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) Get(ctx context.Context, ID string) (Folder, error) {
+	key := datastore.NameKey(g.entityName, ID, nil)
+	f := Folder{}
+	if err := g.client.Get(ctx, key, &f); err != nil {
+		return Folder{}, fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+	}
+	setIDs(&f, ID)
+	return f, nil
+}
+
+func (g *googleDatastore) GetChildren(ctx context.Context, parentID string) ([]Folder, error) {
+	query := datastore.NewQuery(g.entityName).Filter("ParentID =", parentID)
+	return g.getAll(ctx, query)
+}
+
+func (g *googleDatastore) GetShared(ctx context.Context, userID string) ([]Folder, error) {
+	query := datastore.NewQuery(g.entityName).Filter("Shares.UserID =", userID)
+	return g.getAll(ctx, query)
+}
+
+func (g *googleDatastore) getAll(ctx context.Context, query *datastore.Query) ([]Folder, error) {
+	folders := []Folder{}
+	keys, err := g.client.GetAll(ctx, query, &folders)
+	if err != nil {
+		return []Folder{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	for i, key := range keys {
+		setIDs(&folders[i], key.Name)
+	}
+	sort.SliceStable(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
+	return folders, nil
+}
+
+func (g *googleDatastore) Update(ctx context.Context, ID string, setters ...func(*Folder) error) (Folder, error) {
+	key := datastore.NameKey(g.entityName, ID, nil)
+	f := Folder{}
+	_, err := g.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(key, &f); err != nil {
+			return fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+		}
+		setIDs(&f, ID)
+		for _, setFunc := range setters {
+			err := setFunc(&f)
+			if err != nil {
+				return err
+			}
+		}
+		f.DateModified = time.Now()
+		_, err := tx.Put(key, &f)
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Folder{}, err
+	}
+	return f, nil
+}
+
+func (g *googleDatastore) Delete(ctx context.Context, ID string) error {
+	key := datastore.NameKey(g.entityName, ID, nil)
+	err := g.client.Delete(ctx, key)
+	if err != nil {
+		return fmt.Errorf("unable to delete folder. err: %v", err)
+	}
+	return nil
+}
+
+func setIDs(f *Folder, ID string) {
+	f.ID = ID
+	for i := range f.Shares {
+		f.Shares[i].FolderID = ID
+	}
+}
@@ -0,0 +1,210 @@
+// Package folder organises projects into a hierarchy of folders
+//
+// Folders can be shared with other users. Projects within a folder, or within any of its
+// subfolders, inherit the permissions granted by the shares of the folder. Where a user is
+// granted different permissions along the hierarchy, the strongest permission applies
+package folder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+)
+
+// MaxDepth is the maximum number of levels of nested folders
+const MaxDepth = 20
+
+// FolderShare grants a user access to the folder and everything within it
+type FolderShare struct {
+	FolderID    string    `json:"-" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	UserID      string    `json:"user_id" gorm:"type:varchar(40);primary_key"`
+	Permission  string    `json:"permission" gorm:"type:varchar(20)"`
+	DateCreated time.Time `json:"date_created"`
+}
+
+type Folder struct {
+	ID           string        `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	Name         string        `json:"name" gorm:"type:varchar(250)"`
+	ParentID     string        `json:"parent_id,omitempty" gorm:"type:varchar(40)"`
+	Shares       []FolderShare `json:"shares"`
+	DateCreated  time.Time     `json:"date_created"`
+	DateModified time.Time     `json:"date_modified"`
+}
+
+// New creates a folder that is owned by the user that created it
+func New(name, parentID, ownerID string) (Folder, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Folder{}, fmt.Errorf("folder name cannot be empty")
+	}
+	folderID, _ := uuid.NewV4()
+	currentTime := time.Now()
+	return Folder{
+		ID:       folderID.String(),
+		Name:     name,
+		ParentID: parentID,
+		Shares: []FolderShare{{
+			FolderID:    folderID.String(),
+			UserID:      ownerID,
+			Permission:  string(acl.Owner),
+			DateCreated: currentTime,
+		}},
+		DateCreated:  currentTime,
+		DateModified: currentTime,
+	}, nil
+}
+
+// Share retrieves the share of the user on this folder only
+func (f *Folder) Share(userID string) (FolderShare, bool) {
+	for _, s := range f.Shares {
+		if s.UserID == userID {
+			return s, true
+		}
+	}
+	return FolderShare{}, false
+}
+
+// InheritedShares computes the permission of each user across a chain of folders
+// The strongest permission is kept if a user is granted access at multiple levels. Roles that
+// do not include one another are compared by the number of capabilities they allow
+func InheritedShares(chain []Folder) map[string]FolderShare {
+	shares := map[string]FolderShare{}
+	for _, f := range chain {
+		for _, s := range f.Shares {
+			current, exists := shares[s.UserID]
+			if exists && isStronger(current.Permission, s.Permission) {
+				continue
+			}
+			s.FolderID = f.ID
+			shares[s.UserID] = s
+		}
+	}
+	return shares
+}
+
+// Permission is the access a user has on the first folder of the chain
+// The chain is the folder followed by its parent folders up to the top level folder
+func Permission(chain []Folder, userID string) (acl.ACL, error) {
+	s, exists := InheritedShares(chain)[userID]
+	if !exists {
+		return acl.ACL{}, fmt.Errorf("folder is not shared with user")
+	}
+	p, err := acl.ParsePermission(s.Permission)
+	if err != nil {
+		return acl.ACL{}, err
+	}
+	return acl.ACL{UserID: userID, Permission: p, InheritedFrom: s.FolderID}, nil
+}
+
+func SetName(name string) ([]func(*Folder) error, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("folder name cannot be empty")
+	}
+	var setters []func(*Folder) error
+	setters = append(setters, func(f *Folder) error {
+		f.Name = name
+		return nil
+	})
+	return setters, nil
+}
+
+// SetParent moves the folder under another folder. The parent chain is the new parent
+// followed by its parent folders and is used to prevent folders from being moved within themselves
+func SetParent(parentChain []Folder) ([]func(*Folder) error, error) {
+	if len(parentChain)+1 > MaxDepth {
+		return nil, fmt.Errorf("folders cannot be nested more than %v levels", MaxDepth)
+	}
+	parentID := ""
+	if len(parentChain) > 0 {
+		parentID = parentChain[0].ID
+	}
+	var setters []func(*Folder) error
+	setters = append(setters, func(f *Folder) error {
+		for _, p := range parentChain {
+			if p.ID == f.ID {
+				return fmt.Errorf("folder cannot be moved into itself or its subfolders")
+			}
+		}
+		if parentID == "" && f.ownerCount() == 0 {
+			return fmt.Errorf("folder needs to have an owner before it can be moved to the top level")
+		}
+		f.ParentID = parentID
+		return nil
+	})
+	return setters, nil
+}
+
+// SetShare grants or changes the permission of a user on the folder
+func SetShare(userID, permission string) ([]func(*Folder) error, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user is required to share folder")
+	}
+	_, err := acl.ParsePermission(permission)
+	if err != nil {
+		return nil, err
+	}
+	var setters []func(*Folder) error
+	setters = append(setters, func(f *Folder) error {
+		for i, s := range f.Shares {
+			if s.UserID == userID {
+				if s.Permission == string(acl.Owner) && permission != string(acl.Owner) && f.ownerCount() == 1 && f.ParentID == "" {
+					return fmt.Errorf("folder needs to have at least one owner")
+				}
+				f.Shares[i].Permission = permission
+				return nil
+			}
+		}
+		f.Shares = append(f.Shares, FolderShare{
+			FolderID:    f.ID,
+			UserID:      userID,
+			Permission:  permission,
+			DateCreated: time.Now(),
+		})
+		return nil
+	})
+	return setters, nil
+}
+
+// RemoveShare revokes the access of a user on the folder
+func RemoveShare(userID string) ([]func(*Folder) error, error) {
+	var setters []func(*Folder) error
+	setters = append(setters, func(f *Folder) error {
+		shares := []FolderShare{}
+		for _, s := range f.Shares {
+			if s.UserID != userID {
+				shares = append(shares, s)
+				continue
+			}
+			if s.Permission == string(acl.Owner) && f.ownerCount() == 1 && f.ParentID == "" {
+				return fmt.Errorf("folder needs to have at least one owner")
+			}
+		}
+		if len(shares) == len(f.Shares) {
+			return fmt.Errorf("folder is not shared with user")
+		}
+		f.Shares = shares
+		return nil
+	})
+	return setters, nil
+}
+
+// isStronger checks if the permission grants at least as much as the other permission
+func isStronger(raw, other string) bool {
+	return acl.Permission(raw).Stronger(acl.Permission(other))
+}
+
+// ownerCount is used to ensure that top level folders always have an owner
+// Subfolders are managed by the owners of their parent folders
+func (f *Folder) ownerCount() int {
+	count := 0
+	for _, s := range f.Shares {
+		if s.Permission == string(acl.Owner) {
+			count = count + 1
+		}
+	}
+	return count
+}
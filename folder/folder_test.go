@@ -0,0 +1,89 @@
+package folder
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+)
+
+func TestPermission(t *testing.T) {
+	parent, _ := New("Team", "", "owner")
+	child, _ := New("Reviews", parent.ID, "editor")
+	setters, _ := SetShare("reader", string(acl.Reader))
+	setters[0](&parent)
+	setters, _ = SetShare("editor", string(acl.Reader))
+	setters[0](&parent)
+	chain := []Folder{child, parent}
+
+	tests := []struct {
+		name          string
+		userID        string
+		want          string
+		inheritedFrom string
+		wantErr       bool
+	}{
+		{name: "inherited from parent", userID: "reader", want: string(acl.Reader), inheritedFrom: parent.ID},
+		{name: "owner of parent", userID: "owner", want: string(acl.Owner), inheritedFrom: parent.ID},
+		{name: "strongest permission wins", userID: "editor", want: string(acl.Owner), inheritedFrom: child.ID},
+		{name: "not shared", userID: "stranger", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Permission(chain, tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Permission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(got.Permission) != tt.want || got.InheritedFrom != tt.inheritedFrom {
+				t.Errorf("Permission() = %+v, want %v from %v", got, tt.want, tt.inheritedFrom)
+			}
+		})
+	}
+}
+
+func TestSetParent(t *testing.T) {
+	parent, _ := New("Team", "", "owner")
+	child, _ := New("Reviews", parent.ID, "owner")
+
+	setters, _ := SetParent([]Folder{child, parent})
+	if err := setters[0](&parent); err == nil {
+		t.Errorf("expected error when moving folder into its own subfolder")
+	}
+
+	setters, _ = SetParent([]Folder{})
+	if err := setters[0](&child); err != nil || child.ParentID != "" {
+		t.Errorf("expected folder to be moved to the top level. Err: %v", err)
+	}
+
+	orphan := Folder{ID: "orphan", ParentID: parent.ID}
+	if err := setters[0](&orphan); err == nil {
+		t.Errorf("expected error when moving folder without owners to the top level")
+	}
+}
+
+func TestRemoveShare(t *testing.T) {
+	f, _ := New("Team", "", "owner")
+	setters, _ := RemoveShare("owner")
+	if err := setters[0](&f); err == nil {
+		t.Errorf("expected error when removing the last owner of a top level folder")
+	}
+
+	add, _ := SetShare("reader", string(acl.Reader))
+	add[0](&f)
+	setters, _ = RemoveShare("reader")
+	if err := setters[0](&f); err != nil {
+		t.Fatalf("unexpected error when removing share. Err: %v", err)
+	}
+	if _, exists := f.Share("reader"); exists {
+		t.Errorf("expected share to be removed")
+	}
+	if err := setters[0](&f); err == nil {
+		t.Errorf("expected error when removing share that does not exist")
+	}
+
+	if _, err := SetShare("reader", "superuser"); err == nil {
+		t.Errorf("expected error for unknown permission")
+	}
+}
@@ -0,0 +1,122 @@
+package folder
+
+import (
+	"context"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e Folder) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, s := range e.Shares {
+		result = m.db.Save(&s)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, ID string) (Folder, error) {
+	f := Folder{}
+	result := m.db.Where("id = ?", ID).First(&f)
+	if result.Error != nil {
+		return f, result.Error
+	}
+	return f, m.loadShares(&f)
+}
+
+func (m mysql) loadShares(f *Folder) error {
+	s := []FolderShare{}
+	result := m.db.Where("folder_id = ?", f.ID).Find(&s)
+	if result.Error != nil {
+		return result.Error
+	}
+	f.Shares = s
+	return nil
+}
+
+func (m mysql) GetChildren(ctx context.Context, ParentID string) ([]Folder, error) {
+	var folders []Folder
+	result := m.db.Where("parent_id = ?", ParentID).Order("name").Find(&folders)
+	if result.Error != nil {
+		return []Folder{}, result.Error
+	}
+	for i := range folders {
+		err := m.loadShares(&folders[i])
+		if err != nil {
+			return []Folder{}, err
+		}
+	}
+	return folders, nil
+}
+
+func (m mysql) GetShared(ctx context.Context, UserID string) ([]Folder, error) {
+	var folders []Folder
+	result := m.db.Model(&Folder{}).Select("folders.*").Joins("inner join folder_shares on folder_shares.folder_id = folders.id").Where("folder_shares.user_id = ?", UserID).Order("folders.name").Find(&folders)
+	if result.Error != nil {
+		return []Folder{}, result.Error
+	}
+	for i := range folders {
+		err := m.loadShares(&folders[i])
+		if err != nil {
+			return []Folder{}, err
+		}
+	}
+	return folders, nil
+}
+
+func (m mysql) Update(ctx context.Context, ID string, setters ...func(*Folder) error) (Folder, error) {
+	f, err := m.Get(ctx, ID)
+	if err != nil {
+		return Folder{}, err
+	}
+	for _, s := range setters {
+		err := s(&f)
+		if err != nil {
+			return Folder{}, err
+		}
+	}
+	f.DateModified = time.Now()
+	// Shares are replaced as a whole as they could have been removed by the setters
+	result := m.db.Where("folder_id = ?", ID).Delete(FolderShare{})
+	if result.Error != nil {
+		return Folder{}, result.Error
+	}
+	result = m.db.Save(&f)
+	if result.Error != nil {
+		return Folder{}, result.Error
+	}
+	for _, s := range f.Shares {
+		result = m.db.Save(&s)
+		if result.Error != nil {
+			return Folder{}, result.Error
+		}
+	}
+	return f, nil
+}
+
+func (m mysql) Delete(ctx context.Context, ID string) error {
+	result := m.db.Where("id = ?", ID).Delete(Folder{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package folder
+
+import (
+	"context"
+	"fmt"
+)
+
+type Store interface {
+	Create(ctx context.Context, e Folder) error
+	Get(ctx context.Context, ID string) (Folder, error)
+	// GetChildren returns the folders directly under the parent folder
+	GetChildren(ctx context.Context, ParentID string) ([]Folder, error)
+	// GetShared returns the folders that are shared directly with the user
+	GetShared(ctx context.Context, UserID string) ([]Folder, error)
+	Update(ctx context.Context, ID string, setters ...func(*Folder) error) (Folder, error)
+	Delete(ctx context.Context, ID string) error
+}
+
+// Chain retrieves the folder followed by all of its parent folders up to the top level folder
+func Chain(ctx context.Context, store Store, ID string) ([]Folder, error) {
+	chain := []Folder{}
+	currentID := ID
+	for currentID != "" {
+		if len(chain) >= MaxDepth {
+			return []Folder{}, fmt.Errorf("folder is nested beyond the maximum depth of %v", MaxDepth)
+		}
+		f, err := store.Get(ctx, currentID)
+		if err != nil {
+			return []Folder{}, err
+		}
+		chain = append(chain, f)
+		currentID = f.ParentID
+	}
+	return chain, nil
+}
+
+// Subtree retrieves the folder along with all folders nested within it
+func Subtree(ctx context.Context, store Store, ID string) ([]Folder, error) {
+	f, err := store.Get(ctx, ID)
+	if err != nil {
+		return []Folder{}, err
+	}
+	folders := []Folder{f}
+	for i := 0; i < len(folders); i++ {
+		children, err := store.GetChildren(ctx, folders[i].ID)
+		if err != nil {
+			return []Folder{}, err
+		}
+		folders = append(folders, children...)
+	}
+	return folders, nil
+}
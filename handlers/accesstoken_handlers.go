@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/accesstoken"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type CreateAccessToken struct {
+	Logger           logger.Logger
+	AccessTokenStore accesstoken.Store
+}
+
+// ServeHTTP creates a personal access token for the user. The token is only shown in the
+// response of this request. Access tokens are not able to create further tokens so that
+// a leaked token cannot be used to outlive its own expiry
+func (h CreateAccessToken) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start CreateAccessToken API Handler")
+	defer h.Logger.Info("End CreateAccessToken API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+
+	if isAccessToken(ctx) {
+		errMsg := "Error - personal access tokens cannot be created with a personal access token"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type createAccessTokenReq struct {
+		Name      string    `json:"name"`
+		Scope     string    `json:"scope"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := createAccessTokenReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	item, token, err := accesstoken.New(userID, req.Name, req.Scope, req.ExpiresAt)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create access token. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	err = h.AccessTokenStore.Create(ctx, item)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to save access token. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type createAccessTokenResp struct {
+		accesstoken.AccessToken
+		Token string `json:"token"`
+	}
+	rawResp, _ := json.Marshal(createAccessTokenResp{AccessToken: item, Token: token})
+	w.WriteHeader(http.StatusCreated)
+	w.Write(rawResp)
+}
+
+type GetAccessTokens struct {
+	Logger           logger.Logger
+	AccessTokenStore accesstoken.Store
+}
+
+// ServeHTTP lists the personal access tokens of the user. Only the hint of each token is shown
+func (h GetAccessTokens) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetAccessTokens API Handler")
+	defer h.Logger.Info("End GetAccessTokens API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+
+	tokens, err := h.AccessTokenStore.GetAll(ctx, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve access tokens of user. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type getAccessTokensResp struct {
+		AccessTokens []accesstoken.AccessToken `json:"access_tokens"`
+	}
+	rawResp, _ := json.Marshal(getAccessTokensResp{AccessTokens: tokens})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type RevokeAccessToken struct {
+	Logger           logger.Logger
+	AccessTokenStore accesstoken.Store
+}
+
+// ServeHTTP disables the personal access token. Requests made with the token are rejected immediately
+func (h RevokeAccessToken) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start RevokeAccessToken API Handler")
+	defer h.Logger.Info("End RevokeAccessToken API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	tokenID := mux.Vars(r)["token_id"]
+
+	_, err := h.AccessTokenStore.Get(ctx, userID, tokenID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve access token. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	setters, _ := accesstoken.Revoke()
+	item, err := h.AccessTokenStore.Update(ctx, userID, tokenID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to revoke access token. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(item)
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
+)
+
+// collaborator is the acl of a user on a project along with the email of the user
+type collaborator struct {
+	acl.ACL
+	Email string `json:"email"`
+}
+
+type GetCollaborators struct {
+	Logger    logger.Logger
+	ACLStore  acl.Store
+	UserStore user.Store
+}
+
+// ServeHTTP lists the users that have access to the project
+func (h GetCollaborators) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetCollaborators API Handler")
+	defer h.Logger.Info("End GetCollaborators API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	acls, err := h.ACLStore.GetAll(ctx, projectID, maxProjectACLs, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve collaborators of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	collaborators := []collaborator{}
+	for _, a := range acls {
+		// Teams are listed with the teams of the project instead
+		if a.IsTeam() {
+			continue
+		}
+		// Users that can no longer be found are still listed so that their access can be revoked
+		u, _ := h.UserStore.GetUser(ctx, a.UserID)
+		collaborators = append(collaborators, collaborator{ACL: a, Email: u.Email})
+	}
+
+	type getCollaboratorsResp struct {
+		Collaborators []collaborator `json:"collaborators"`
+	}
+	rawResp, _ := json.Marshal(getCollaboratorsResp{Collaborators: collaborators})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type InviteCollaborator struct {
+	Logger    logger.Logger
+	ACLStore  acl.Store
+	UserStore user.Store
+}
+
+// ServeHTTP grants a user access to the project with the role provided
+// Users that have inherited access from a folder or a team are granted the role on the project directly
+func (h InviteCollaborator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start InviteCollaborator API Handler")
+	defer h.Logger.Info("End InviteCollaborator API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	obtainedACL := projectACL(ctx)
+
+	type inviteCollaboratorReq struct {
+		Email      string `json:"email"`
+		Permission string `json:"permission"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := inviteCollaboratorReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil || req.Email == "" {
+		errMsg := fmt.Sprintf("Error - email of user is required to invite collaborator. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	setters, err := acl.SetPermission(req.Permission)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid role. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	// Collaborators can only grant roles that do not allow more than their own
+	newPermission, _ := acl.ParsePermission(req.Permission)
+	if !obtainedACL.Permission.Includes(newPermission) {
+		errMsg := fmt.Sprintf("Error - role on the project does not allow granting role %v", newPermission)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	// Stores return an empty user rather than an error if there is no user with the email
+	invitedUser, err := h.UserStore.GetUserByEmail(ctx, req.Email)
+	if err != nil || invitedUser.ID == "" {
+		errMsg := fmt.Sprintf("Error - unable to find user to invite. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	existing, err := h.ACLStore.Get(ctx, projectID, invitedUser.ID)
+	if err == nil && existing.InheritedFrom == "" && existing.TeamID == "" {
+		errMsg := fmt.Sprintf("Error - user is already a collaborator of the project. Change the role of the user instead")
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	var item acl.ACL
+	if err == nil && existing.InheritedFrom != "" {
+		item, err = h.ACLStore.Update(ctx, projectID, invitedUser.ID, setters...)
+	} else {
+		item = acl.New(projectID, invitedUser.ID)
+		for _, s := range setters {
+			s(&item)
+		}
+		err = h.ACLStore.Create(ctx, item)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to invite collaborator. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(collaborator{ACL: item, Email: invitedUser.Email})
+	w.WriteHeader(http.StatusCreated)
+	w.Write(rawResp)
+}
+
+type UpdateCollaborator struct {
+	Logger   logger.Logger
+	ACLStore acl.Store
+}
+
+// ServeHTTP changes the role of a collaborator of the project
+// The role of the last owner of the project cannot be changed
+func (h UpdateCollaborator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateCollaborator API Handler")
+	defer h.Logger.Info("End UpdateCollaborator API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	collaboratorID := mux.Vars(r)["user_id"]
+
+	obtainedACL := projectACL(ctx)
+
+	type updateCollaboratorReq struct {
+		Permission string `json:"permission"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := updateCollaboratorReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	setters, err := acl.SetPermission(req.Permission)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid role. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	// Collaborators can only grant roles that do not allow more than their own
+	newPermission, _ := acl.ParsePermission(req.Permission)
+	if !obtainedACL.Permission.Includes(newPermission) {
+		errMsg := fmt.Sprintf("Error - role on the project does not allow granting role %v", newPermission)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	acls, err := h.ACLStore.GetAll(ctx, projectID, maxProjectACLs, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve collaborators of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	// Collaborators with a role that is not included in the role of the user are left alone so
+	// that roles with the share capability cannot demote users with stronger roles
+	for _, a := range acls {
+		if a.UserID == collaboratorID && !obtainedACL.Permission.Includes(a.Permission) {
+			errMsg := fmt.Sprintf("Error - role on the project does not allow changing collaborators with role %v", a.Permission)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+	err = acl.CheckOwnerRemains(acls, collaboratorID, newPermission)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to change role of collaborator. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	item, err := h.ACLStore.Update(ctx, projectID, collaboratorID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to change role of collaborator. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(item)
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type RemoveCollaborator struct {
+	Logger   logger.Logger
+	ACLStore acl.Store
+}
+
+// ServeHTTP revokes the access of a collaborator to the project
+// Access inherited from a folder is revoked by removing the share of the folder instead
+func (h RemoveCollaborator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start RemoveCollaborator API Handler")
+	defer h.Logger.Info("End RemoveCollaborator API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	collaboratorID := mux.Vars(r)["user_id"]
+
+	obtainedACL := projectACL(ctx)
+
+	acls, err := h.ACLStore.GetAll(ctx, projectID, maxProjectACLs, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve collaborators of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	var removed *acl.ACL
+	for i := range acls {
+		if acls[i].UserID == collaboratorID {
+			removed = &acls[i]
+		}
+	}
+	if removed == nil {
+		errMsg := fmt.Sprintf("Error - user is not a collaborator of the project")
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if !obtainedACL.Permission.Includes(removed.Permission) {
+		errMsg := fmt.Sprintf("Error - role on the project does not allow removing collaborators with role %v", removed.Permission)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if removed.InheritedFrom != "" {
+		errMsg := fmt.Sprintf("Error - access is inherited from folder %v. Remove the share of the folder instead", removed.InheritedFrom)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	err = acl.CheckOwnerRemains(acls, collaboratorID, "")
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to remove collaborator. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = h.ACLStore.Delete(ctx, projectID, collaboratorID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to remove collaborator. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
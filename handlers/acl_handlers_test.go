@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
+)
+
+func TestInviteCollaborator(t *testing.T) {
+	invited, _ := user.NewFromProvider("invited@example.com")
+	users := fakeUserStore{users: map[string]user.User{invited.ID: invited}}
+
+	tests := []struct {
+		name  string
+		email string
+		want  int
+	}{
+		{name: "existing user", email: "invited@example.com", want: http.StatusCreated},
+		{name: "unknown email", email: "unknown@example.com", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acls := fakeACLStore{acls: map[string]acl.ACL{"project/owner": acl.New("project", "owner")}}
+			h := RequireProjectACL{
+				Logger:      logger.LoggerForTests{Tester: t},
+				ACLStore:    acls,
+				Capability:  acl.Share,
+				NextHandler: InviteCollaborator{Logger: logger.LoggerForTests{Tester: t}, ACLStore: acls, UserStore: users},
+			}
+
+			body := `{"email": "` + tt.email + `", "permission": "editor"}`
+			req := httptest.NewRequest("POST", "/api/v1/project/project/collaborators", strings.NewReader(body))
+			req = mux.SetURLVars(req, map[string]string{"project_id": "project"})
+			req = req.WithContext(context.WithValue(req.Context(), userIDKey, "owner"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("unexpected status code. Expected: %v Actual: %v %v", tt.want, rec.Code, rec.Body.String())
+			}
+			if _, ok := acls.acls["project/"]; ok {
+				t.Errorf("expected no acl to be created without a user")
+			}
+		})
+	}
+}
+
+func TestManageCollaborators_ShareOnlyRole(t *testing.T) {
+	err := acl.RegisterRole("sharer", []string{"view", "share"})
+	if err != nil {
+		t.Fatalf("unable to register role. Err: %v", err)
+	}
+	sharer := acl.New("project", "sharer")
+	sharer.Permission = "sharer"
+	editor := acl.New("project", "editor")
+	editor.Permission = acl.Editor
+	reader := acl.New("project", "reader")
+	reader.Permission = acl.Reader
+
+	tests := []struct {
+		name         string
+		method       string
+		collaborator string
+		body         string
+		want         int
+	}{
+		{name: "demote owner", method: "PUT", collaborator: "owner", body: `{"permission": "reader"}`, want: http.StatusForbidden},
+		{name: "demote editor", method: "PUT", collaborator: "editor", body: `{"permission": "reader"}`, want: http.StatusForbidden},
+		{name: "promote reader to editor", method: "PUT", collaborator: "reader", body: `{"permission": "editor"}`, want: http.StatusForbidden},
+		{name: "change reader to sharer", method: "PUT", collaborator: "reader", body: `{"permission": "sharer"}`, want: http.StatusOK},
+		{name: "remove owner", method: "DELETE", collaborator: "owner", want: http.StatusForbidden},
+		{name: "remove editor", method: "DELETE", collaborator: "editor", want: http.StatusForbidden},
+		{name: "remove reader", method: "DELETE", collaborator: "reader", want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acls := fakeACLStore{acls: map[string]acl.ACL{
+				"project/owner":  acl.New("project", "owner"),
+				"project/sharer": sharer,
+				"project/editor": editor,
+				"project/reader": reader,
+			}}
+			var next http.Handler = UpdateCollaborator{Logger: logger.LoggerForTests{Tester: t}, ACLStore: acls}
+			if tt.method == "DELETE" {
+				next = RemoveCollaborator{Logger: logger.LoggerForTests{Tester: t}, ACLStore: acls}
+			}
+			h := RequireProjectACL{Logger: logger.LoggerForTests{Tester: t}, ACLStore: acls, Capability: acl.Share, NextHandler: next}
+
+			req := httptest.NewRequest(tt.method, "/api/v1/project/project/collaborators/"+tt.collaborator, strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"project_id": "project", "user_id": tt.collaborator})
+			req = req.WithContext(context.WithValue(req.Context(), userIDKey, "sharer"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("unexpected status code. Expected: %v Actual: %v %v", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
 )
 
 type Status struct {
@@ -22,3 +23,21 @@ func (h Status) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(raw)
 }
+
+type JWKS struct {
+	Logger logger.Logger
+	Auth   services.Auth
+}
+
+// ServeHTTP publishes the public keys that tokens issued by the manager can be verified with
+// The list is empty if tokens are signed with a shared secret
+func (h JWKS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start JWKS Handler")
+	defer h.Logger.Info("End JWKS Handler")
+
+	raw, _ := json.Marshal(h.Auth.Keys().JWKS())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(raw)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/projectarchive"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type ExportProject struct {
+	Logger           logger.Logger
+	ProjectStore     project.Store
+	Blobstorage      blobstorage.BlobStorage
+	BucketFolderName string
+}
+
+func (h ExportProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start ExportProject API Handler")
+	defer h.Logger.Info("End ExportProject API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	p, err := h.ProjectStore.Get(ctx, projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve the project entity. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	manifest := projectarchive.NewManifest(p, project.Folders{PDF: h.BucketFolderName, Images: imagesFolder})
+	rawArchive, err := projectarchive.Export(ctx, h.Blobstorage, manifest)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to export project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v.zip", projectID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawArchive)
+}
+
+type ImportProject struct {
+	Logger              logger.Logger
+	ProjectStore        project.Store
+	PDFSlideImagesStore pdfslideimages.Store
+	VideoSegmentStore   videosegment.Store
+	ACLStore            acl.Store
+	Blobstorage         blobstorage.BlobStorage
+	BucketFolderName    string
+}
+
+func (h ImportProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start ImportProject API Handler")
+	defer h.Logger.Info("End ImportProject API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+
+	r.Body = http.MaxBytesReader(w, r.Body, projectarchive.MaxArchiveSize)
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve parse multipart form data. Error: %+v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	file, _, err := r.FormFile("myfile")
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve form data. Error: %+v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	defer file.Close()
+	var b bytes.Buffer
+	bw := bufio.NewWriter(&b)
+	io.Copy(bw, file)
+	bw.Flush()
+
+	importedProject, err := projectarchive.Import(ctx, h.Blobstorage, b.Bytes(), project.Folders{PDF: h.BucketFolderName, Images: imagesFolder})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to import project archive. Error: %+v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	pdfSlideImages := importedProject.PDFSlideImages
+	videoSegments := importedProject.VideoSegments
+	importedProject.PDFSlideImages = nil
+	importedProject.VideoSegments = nil
+	err = h.ProjectStore.Create(ctx, importedProject)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create project in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = h.ACLStore.Create(ctx, acl.New(importedProject.ID, userID))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create acl control in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	for _, p := range pdfSlideImages {
+		err = h.PDFSlideImagesStore.Create(ctx, p)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to import pdf slide images. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	for _, v := range videoSegments {
+		err = h.VideoSegmentStore.Create(ctx, v)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to import video segment. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	importedProject, err = h.ProjectStore.Get(ctx, importedProject.ID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve the imported project entity. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	rawItem, _ := json.Marshal(importedProject)
+	w.Write(rawItem)
+}
@@ -3,32 +3,62 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/securecookie"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/accesstoken"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
 )
 
+// RequireJWTAuth ensures that the request is made by a user. Users authenticate with the cookie
+// of their session, a JWT or a personal access token with a scope that allows the request
+// Routes that workers report the progress of their jobs to set ServiceScope - the request is
+// then also accepted from a service account with a token of the scope that was issued for the
+// resource in the route
+// Routes that make changes to the content of projects set ProjectWrite so that they can be used
+// with personal access tokens of the project-write scope. Other changes, such as sharing projects
+// or managing the account, cannot be made with personal access tokens
 type RequireJWTAuth struct {
-	Auth        services.Auth
-	Logger      logger.Logger
-	NextHandler http.Handler
+	Auth         services.Auth
+	Logger       logger.Logger
+	ServiceScope services.Scope
+	ProjectWrite bool
+	NextHandler  http.Handler
 }
 
 var (
-	userIDKey = "userID"
+	userIDKey         = "userID"
+	shareLinkKey      = "shareLink"
+	serviceAccountKey = "serviceAccount"
+	accessTokenKey    = "accessToken"
+	sessionIDKey      = "sessionID"
+	projectACLKey     = "projectACL"
 )
 
+// serviceScopeResources maps the scope to the route variable of the resource that it allows
+// to be updated. Scopes on the project itself only need the {project_id} route variable
+var serviceScopeResources = map[services.Scope]string{
+	services.UpdatePDFSlideImagesStatus: "pdfslideimages_id",
+	services.UpdateVideoSegmentStatus:   "videosegment_id",
+	services.UpdateVideoOutputStatus:    "videooutput_id",
+}
+
 func (a RequireJWTAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	a.Logger.Info("RequireJWTAuth Exists Check")
 
 	ctx := r.Context()
 	copiedReq := r.Clone(ctx)
-	s := securecookie.New(a.Auth.HashKey, a.Auth.BlockKey)
 	value := make(map[string]string)
 	var userID string
-	var err error
+	var sessionID string
 
 	type failedResp struct {
 		Msg string `json:"msg"`
@@ -37,26 +67,81 @@ func (a RequireJWTAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	cookie, cookieErr := r.Cookie(a.Auth.CookieName)
 	if cookieErr == nil {
-		err := s.Decode(a.Auth.CookieName, cookie.Value, &value)
+		err := securecookie.DecodeMulti(a.Auth.CookieName, cookie.Value, &value, a.Auth.CookieCodecs()...)
 		if err != nil || value["user_id"] == "" {
 			w.WriteHeader(http.StatusUnauthorized)
 			w.Write(rawErrMsg)
 			return
 		}
 		userID = value["user_id"]
+		sessionID = value["session_id"]
 	} else {
 		a.Logger.Error(cookieErr)
 	}
 
+	if userID == "" && a.ServiceScope != "" {
+		claims, serviceErr := services.ExtractServiceToken(copiedReq.Header.Get("Authorization"), a.Auth.Keys())
+		if serviceErr == nil {
+			vars := mux.Vars(r)
+			if claims.Scope != a.ServiceScope || claims.ProjectID != vars["project_id"] || claims.ResourceID != vars[serviceScopeResources[a.ServiceScope]] {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(generateErrorResp("Service account token does not allow this operation")))
+				a.Logger.Errorf("Service account %v with scope %v on %v %v is not allowed for %v", claims.Account, claims.Scope, claims.ProjectID, claims.ResourceID, r.URL.Path)
+				return
+			}
+			ctx = context.WithValue(ctx, serviceAccountKey, claims)
+			a.NextHandler.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+	}
+
+	if userID == "" && a.Auth.AccessTokens != nil {
+		rawToken := strings.TrimPrefix(copiedReq.Header.Get("Authorization"), "Bearer ")
+		if accesstoken.IsAccessToken(rawToken) {
+			token, err := a.Auth.AccessTokens.GetByHash(ctx, accesstoken.Hash(rawToken))
+			if err == nil {
+				err = token.Active(time.Now())
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write(rawErrMsg)
+				a.Logger.Infof("Personal access token is not valid. Err: %v", err)
+				return
+			}
+			if !token.Allows(r.Method, a.ProjectWrite) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(generateErrorResp(fmt.Sprintf("Personal access token with %v scope does not allow this request", token.Scope))))
+				return
+			}
+			ctx = context.WithValue(ctx, accessTokenKey, token)
+			userID = token.UserID
+		}
+	}
+
 	if userID == "" {
 		rawAuthorizationToken := copiedReq.Header.Get("Authorization")
-		userID, err = services.ExtractToken(rawAuthorizationToken, a.Auth.Secret)
-		if err != nil || userID == "" {
+		claims, err := services.ExtractClaims(rawAuthorizationToken, a.Auth.Keys())
+		if err != nil || claims.ID == "" {
 			w.WriteHeader(http.StatusUnauthorized)
 			w.Write(rawErrMsg)
 			a.Logger.Info("Cookie is empty but header is also empty")
 			return
 		}
+		userID = claims.ID
+		sessionID = claims.SessionID
+	}
+
+	// Cookies and JWTs belong to a session of the user so that they stop working once the
+	// session is revoked. Personal access tokens are revoked on their own
+	if a.Auth.Sessions != nil && !isAccessToken(ctx) {
+		err := activeSession(ctx, a.Auth.Sessions, sessionID, userID)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write(rawErrMsg)
+			a.Logger.Infof("Session of user is not valid. Err: %v", err)
+			return
+		}
+		ctx = context.WithValue(ctx, sessionIDKey, sessionID)
 	}
 
 	if userID == "" {
@@ -70,3 +155,117 @@ func (a RequireJWTAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	a.NextHandler.ServeHTTP(w, r.WithContext(ctx))
 }
+
+// activeSession checks that the session has not ended and belongs to the user
+func activeSession(ctx context.Context, sessions session.Store, sessionID, userID string) error {
+	if sessionID == "" {
+		return fmt.Errorf("no session provided")
+	}
+	s, err := sessions.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if s.UserID != userID {
+		return fmt.Errorf("session does not belong to user")
+	}
+	return s.Active(time.Now())
+}
+
+// isAccessToken checks if the user made the request with a personal access token
+func isAccessToken(ctx context.Context) bool {
+	_, isToken := ctx.Value(accessTokenKey).(accesstoken.AccessToken)
+	return isToken
+}
+
+// isServiceAccount checks if the request was made by the service account of a worker
+func isServiceAccount(ctx context.Context) bool {
+	_, isService := ctx.Value(serviceAccountKey).(services.ServiceClaims)
+	return isService
+}
+
+// RequireProjectACL ensures that the role of the user on the project in the {project_id}
+// route variable allows the capability before the request is passed on. It is to be wrapped
+// by RequireJWTAuth so that the user is known. Service accounts are let through as RequireJWTAuth
+// has already checked that their token is for the project. The acl of the user is passed on
+// in the context for handlers that depend on the role of the user, see projectACL
+type RequireProjectACL struct {
+	Logger      logger.Logger
+	ACLStore    acl.Store
+	Capability  acl.Capability
+	NextHandler http.Handler
+}
+
+func (a RequireProjectACL) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(userIDKey).(string)
+	projectID := mux.Vars(r)["project_id"]
+
+	if isServiceAccount(ctx) {
+		a.NextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if userID == "" {
+		errMsg := fmt.Sprintf("Error - user is required to access project %v", projectID)
+		a.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	obtainedACL, err := a.ACLStore.Get(ctx, projectID, userID)
+	if err != nil || !obtainedACL.IsAuthorized(a.Capability) {
+		errMsg := fmt.Sprintf("Error - role on project does not allow %v. Error: %v", a.Capability, err)
+		a.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	ctx = context.WithValue(ctx, projectACLKey, obtainedACL)
+	a.NextHandler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// projectACL provides the acl of the user that was checked by RequireProjectACL
+// It is empty for service accounts
+func projectACL(ctx context.Context) acl.ACL {
+	a, _ := ctx.Value(projectACLKey).(acl.ACL)
+	return a
+}
+
+// RequireActiveProject rejects changes to projects that are in the trash. Trashed projects
+// need to be restored before they can be changed again. Workers can still report the progress
+// of jobs that were started before the project was moved to the trash
+type RequireActiveProject struct {
+	Logger       logger.Logger
+	ProjectStore project.Store
+	NextHandler  http.Handler
+}
+
+func (a RequireActiveProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	if isServiceAccount(ctx) {
+		a.NextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	p, err := a.ProjectStore.Get(ctx, projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve the project entity. Error: %v", err)
+		a.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if p.IsTrashed() {
+		errMsg := fmt.Sprintf("Error - project %v is in the trash. Restore the project before changing it", projectID)
+		a.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	a.NextHandler.ServeHTTP(w, r)
+}
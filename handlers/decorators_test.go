@@ -0,0 +1,556 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/securecookie"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/accesstoken"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
+)
+
+type fakeACLStore struct {
+	acls map[string]acl.ACL
+}
+
+func (f fakeACLStore) Create(ctx context.Context, e acl.ACL) error {
+	f.acls[e.ProjectID+"/"+e.UserID] = e
+	return nil
+}
+
+func (f fakeACLStore) Get(ctx context.Context, projectID, userID string) (acl.ACL, error) {
+	a, ok := f.acls[projectID+"/"+userID]
+	if !ok {
+		return acl.ACL{}, fmt.Errorf("acl not found")
+	}
+	return a, nil
+}
+
+func (f fakeACLStore) GetAll(ctx context.Context, projectID string, limit, after int) ([]acl.ACL, error) {
+	acls := []acl.ACL{}
+	for _, a := range f.acls {
+		if a.ProjectID == projectID {
+			acls = append(acls, a)
+		}
+	}
+	return acls, nil
+}
+
+func (f fakeACLStore) Update(ctx context.Context, projectID, userID string, setters ...func(*acl.ACL) error) (acl.ACL, error) {
+	a, err := f.Get(ctx, projectID, userID)
+	if err != nil {
+		return acl.ACL{}, err
+	}
+	for _, s := range setters {
+		err = s(&a)
+		if err != nil {
+			return acl.ACL{}, err
+		}
+	}
+	f.acls[projectID+"/"+userID] = a
+	return a, nil
+}
+
+func (f fakeACLStore) Delete(ctx context.Context, projectID, userID string) error {
+	delete(f.acls, projectID+"/"+userID)
+	return nil
+}
+
+func (f fakeACLStore) GetForTeams(ctx context.Context, projectID string, teamIDs []string) ([]acl.ACL, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f fakeACLStore) DeleteTeam(ctx context.Context, projectID, teamID string) error {
+	return fmt.Errorf("not implemented")
+}
+
+// withUser stands in for RequireJWTAuth by setting the user of the request
+type withUser struct {
+	userID      string
+	nextHandler http.Handler
+}
+
+func (h withUser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.userID != "" {
+		ctx = context.WithValue(ctx, userIDKey, h.userID)
+	}
+	h.nextHandler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// projectRoutes are the project routes in the manager along with the capability that
+// RequireProjectACL checks for each of them
+var projectRoutes = []struct {
+	name       string
+	method     string
+	path       string
+	url        string
+	capability acl.Capability
+}{
+	{name: "GetProject", method: "GET", path: "/project/{project_id}", url: "/project/p1", capability: acl.View},
+	{name: "UpdateProject", method: "PUT", path: "/project/{project_id}", url: "/project/p1", capability: acl.EditScript},
+	{name: "MoveProject", method: "POST", path: "/project/{project_id}:move", url: "/project/p1:move", capability: acl.Share},
+	{name: "UpdateProjectTags", method: "PUT", path: "/project/{project_id}/tags", url: "/project/p1/tags", capability: acl.EditScript},
+	{name: "UpdateProjectSettings", method: "PUT", path: "/project/{project_id}/settings", url: "/project/p1/settings", capability: acl.EditScript},
+	{name: "UploadBackgroundMusic", method: "POST", path: "/project/{project_id}/music", url: "/project/p1/music", capability: acl.EditScript},
+	{name: "UpdateBackgroundMusic", method: "PUT", path: "/project/{project_id}/music", url: "/project/p1/music", capability: acl.EditScript},
+	{name: "UpdateBackgroundMusic with DELETE", method: "DELETE", path: "/project/{project_id}/music", url: "/project/p1/music", capability: acl.EditScript},
+	{name: "UpdateProjectLanguages", method: "PUT", path: "/project/{project_id}/languages", url: "/project/p1/languages", capability: acl.EditScript},
+	{name: "GetCollaborators", method: "GET", path: "/project/{project_id}/collaborators", url: "/project/p1/collaborators", capability: acl.View},
+	{name: "InviteCollaborator", method: "POST", path: "/project/{project_id}/collaborators", url: "/project/p1/collaborators", capability: acl.Share},
+	{name: "UpdateCollaborator", method: "PUT", path: "/project/{project_id}/collaborators/{user_id}", url: "/project/p1/collaborators/u1", capability: acl.Share},
+	{name: "RemoveCollaborator", method: "DELETE", path: "/project/{project_id}/collaborators/{user_id}", url: "/project/p1/collaborators/u1", capability: acl.Share},
+	{name: "GetProjectTeams", method: "GET", path: "/project/{project_id}/teams", url: "/project/p1/teams", capability: acl.View},
+	{name: "ShareProjectWithTeam", method: "PUT", path: "/project/{project_id}/teams/{team_id}", url: "/project/p1/teams/t1", capability: acl.Share},
+	{name: "UnshareProjectWithTeam", method: "DELETE", path: "/project/{project_id}/teams/{team_id}", url: "/project/p1/teams/t1", capability: acl.Share},
+	{name: "CreateShareLink", method: "POST", path: "/project/{project_id}/sharelinks", url: "/project/p1/sharelinks", capability: acl.Share},
+	{name: "GetShareLinks", method: "GET", path: "/project/{project_id}/sharelinks", url: "/project/p1/sharelinks", capability: acl.Share},
+	{name: "RevokeShareLink", method: "DELETE", path: "/project/{project_id}/sharelinks/{sharelink_id}", url: "/project/p1/sharelinks/l1", capability: acl.Share},
+	{name: "DeleteProject", method: "DELETE", path: "/project/{project_id}", url: "/project/p1", capability: acl.Delete},
+	{name: "RestoreProject", method: "POST", path: "/project/{project_id}:restore", url: "/project/p1:restore", capability: acl.Delete},
+	{name: "StartVideoConcat", method: "POST", path: "/project/{project_id}:concat", url: "/project/p1:concat", capability: acl.Concat},
+	{name: "StartProjectGenerateVideo", method: "POST", path: "/project/{project_id}:generate-video", url: "/project/p1:generate-video", capability: acl.Generate},
+	{name: "CreateVideoOutput", method: "POST", path: "/project/{project_id}/videooutput", url: "/project/p1/videooutput", capability: acl.Concat},
+	{name: "GetVideoOutputs", method: "GET", path: "/project/{project_id}/videooutputs", url: "/project/p1/videooutputs", capability: acl.View},
+	{name: "UpdateVideoOutput", method: "PUT", path: "/project/{project_id}/videooutput/{videooutput_id}", url: "/project/p1/videooutput/o1", capability: acl.Concat},
+	{name: "UploadBackgroundMusic of video output", method: "POST", path: "/project/{project_id}/videooutput/{videooutput_id}/music", url: "/project/p1/videooutput/o1/music", capability: acl.EditScript},
+	{name: "UpdateBackgroundMusic of video output", method: "PUT", path: "/project/{project_id}/videooutput/{videooutput_id}/music", url: "/project/p1/videooutput/o1/music", capability: acl.EditScript},
+	{name: "UpdateBackgroundMusic of video output with DELETE", method: "DELETE", path: "/project/{project_id}/videooutput/{videooutput_id}/music", url: "/project/p1/videooutput/o1/music", capability: acl.EditScript},
+	{name: "StartVideoOutputGeneration", method: "POST", path: "/project/{project_id}/videooutput/{videooutput_id}:generate", url: "/project/p1/videooutput/o1:generate", capability: acl.Concat},
+	{name: "GetOutputVersions", method: "GET", path: "/project/{project_id}/outputversions", url: "/project/p1/outputversions", capability: acl.View},
+	{name: "DownloadOutputVersion", method: "GET", path: "/project/{project_id}/outputversion/{outputversion_id}:download", url: "/project/p1/outputversion/ov1:download", capability: acl.View},
+	{name: "DownloadSubtitles", method: "GET", path: "/project/{project_id}/subtitles/{format}", url: "/project/p1/subtitles/srt", capability: acl.View},
+	{name: "DownloadSubtitles of video output", method: "GET", path: "/project/{project_id}/videooutput/{videooutput_id}/subtitles/{format}", url: "/project/p1/videooutput/o1/subtitles/srt", capability: acl.View},
+	{name: "DownloadSubtitles of output version", method: "GET", path: "/project/{project_id}/outputversion/{outputversion_id}/subtitles/{format}", url: "/project/p1/outputversion/ov1/subtitles/srt", capability: acl.View},
+	{name: "PublishOutputVersion", method: "POST", path: "/project/{project_id}/outputversion/{outputversion_id}:publish", url: "/project/p1/outputversion/ov1:publish", capability: acl.Publish},
+	{name: "CloneProject", method: "POST", path: "/project/{project_id}:clone", url: "/project/p1:clone", capability: acl.View},
+	{name: "ExportProject", method: "GET", path: "/project/{project_id}:export", url: "/project/p1:export", capability: acl.View},
+	{name: "CreatePDFSlideImages", method: "POST", path: "/project/{project_id}/pdfslideimages", url: "/project/p1/pdfslideimages", capability: acl.EditScript},
+	{name: "UpdatePDFSlideImages", method: "PUT", path: "/project/{project_id}/pdfslideimages/{pdfslideimages_id}", url: "/project/p1/pdfslideimages/s1", capability: acl.EditScript},
+	{name: "GetPDFSlideImages", method: "GET", path: "/project/{project_id}/pdfslideimages/{pdfslideimages_id}", url: "/project/p1/pdfslideimages/s1", capability: acl.View},
+	{name: "CreateVideoSegment", method: "POST", path: "/project/{project_id}/videosegment", url: "/project/p1/videosegment", capability: acl.EditScript},
+	{name: "UpdateVideoSegment", method: "PUT", path: "/project/{project_id}/videosegment/{videosegment_id}", url: "/project/p1/videosegment/v1", capability: acl.EditScript},
+	{name: "GetVideoSegment", method: "GET", path: "/project/{project_id}/videosegment/{videosegment_id}", url: "/project/p1/videosegment/v1", capability: acl.View},
+	{name: "UpdateVideoSegmentVoice", method: "PUT", path: "/project/{project_id}/videosegment/{videosegment_id}/voice", url: "/project/p1/videosegment/v1/voice", capability: acl.EditScript},
+	{name: "UpdateVideoSegmentTranslation", method: "PUT", path: "/project/{project_id}/videosegment/{videosegment_id}/translation/{language}", url: "/project/p1/videosegment/v1/translation/fr", capability: acl.EditScript},
+	{name: "GetScriptRevisions", method: "GET", path: "/project/{project_id}/videosegment/{videosegment_id}/scriptrevision", url: "/project/p1/videosegment/v1/scriptrevision", capability: acl.View},
+	{name: "RevertScriptRevision", method: "POST", path: "/project/{project_id}/videosegment/{videosegment_id}/scriptrevision/{revision}:revert", url: "/project/p1/videosegment/v1/scriptrevision/2:revert", capability: acl.EditScript},
+	{name: "StartVideoSegmentGeneration", method: "POST", path: "/project/{project_id}/videosegment/{videosegment_id}:generate", url: "/project/p1/videosegment/v1:generate", capability: acl.Generate},
+	{name: "DownloadVideo", method: "GET", path: "/project/{project_id}/video/{video_id}", url: "/project/p1/video/abc.mp4", capability: acl.View},
+	{name: "DownloadImage", method: "GET", path: "/project/{project_id}/image/{image_id}", url: "/project/p1/image/abc.png", capability: acl.View},
+}
+
+func TestRequireProjectACL(t *testing.T) {
+	store := fakeACLStore{acls: map[string]acl.ACL{}}
+	for _, p := range []acl.Permission{acl.Anonymous, acl.Reader, acl.Editor, acl.Publisher, acl.Owner} {
+		a := acl.New("p1", string(p))
+		a.Permission = p
+		store.Create(context.Background(), a)
+	}
+	// Access to another project does not grant access to the project in the route
+	other := acl.New("p2", "other")
+	other.Permission = acl.Owner
+	store.Create(context.Background(), other)
+
+	users := []struct {
+		userID     string
+		permission acl.Permission
+	}{
+		{userID: "", permission: ""},
+		{userID: "other", permission: ""},
+		{userID: string(acl.Anonymous), permission: acl.Anonymous},
+		{userID: string(acl.Reader), permission: acl.Reader},
+		{userID: string(acl.Editor), permission: acl.Editor},
+		{userID: string(acl.Publisher), permission: acl.Publisher},
+		{userID: string(acl.Owner), permission: acl.Owner},
+	}
+
+	for _, route := range projectRoutes {
+		for _, u := range users {
+			want := http.StatusOK
+			switch {
+			case u.userID == "":
+				want = http.StatusUnauthorized
+			case !u.permission.Can(route.capability):
+				want = http.StatusForbidden
+			}
+			t.Run(fmt.Sprintf("%v as %v", route.name, u.userID), func(t *testing.T) {
+				reached := false
+				r := mux.NewRouter()
+				r.Handle(route.path, withUser{
+					userID: u.userID,
+					nextHandler: RequireProjectACL{
+						Logger:     logger.LoggerForTests{Tester: t},
+						ACLStore:   store,
+						Capability: route.capability,
+						NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							reached = true
+							w.WriteHeader(http.StatusOK)
+						}),
+					},
+				}).Methods(route.method)
+
+				req := httptest.NewRequest(route.method, route.url, nil)
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+
+				if rec.Code != want {
+					t.Errorf("unexpected status code. Expected: %v Actual: %v", want, rec.Code)
+				}
+				if reached != (want == http.StatusOK) {
+					t.Errorf("unexpected call to route handler. Called: %v", reached)
+				}
+			})
+		}
+	}
+}
+
+type fakeProjectStore struct {
+	project.Store
+	projects map[string]project.Project
+}
+
+func (f fakeProjectStore) Get(ctx context.Context, ID string) (project.Project, error) {
+	p, ok := f.projects[ID]
+	if !ok {
+		return project.Project{}, fmt.Errorf("project not found")
+	}
+	return p, nil
+}
+
+func (f fakeProjectStore) Create(ctx context.Context, p project.Project) error {
+	f.projects[p.ID] = p
+	return nil
+}
+
+func (f fakeProjectStore) Update(ctx context.Context, ID string, setters ...func(*project.Project) error) (project.Project, error) {
+	p, err := f.Get(ctx, ID)
+	if err != nil {
+		return project.Project{}, err
+	}
+	for _, s := range setters {
+		err = s(&p)
+		if err != nil {
+			return project.Project{}, err
+		}
+	}
+	f.projects[ID] = p
+	return p, nil
+}
+
+func TestRequireActiveProject(t *testing.T) {
+	deletedAt := time.Now()
+	active := project.New()
+	trashed := project.New()
+	trashed.DeletedAt = &deletedAt
+	store := fakeProjectStore{projects: map[string]project.Project{active.ID: active, trashed.ID: trashed}}
+
+	tests := []struct {
+		name      string
+		projectID string
+		ctx       context.Context
+		want      int
+	}{
+		{name: "active project", projectID: active.ID, ctx: context.Background(), want: http.StatusOK},
+		{name: "trashed project", projectID: trashed.ID, ctx: context.Background(), want: http.StatusConflict},
+		{name: "missing project", projectID: "missing", ctx: context.Background(), want: http.StatusNotFound},
+		{name: "worker reporting on trashed project", projectID: trashed.ID, ctx: context.WithValue(context.Background(), serviceAccountKey, services.ServiceClaims{}), want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			h := RequireActiveProject{
+				Logger:       logger.LoggerForTests{Tester: t},
+				ProjectStore: store,
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					reached = true
+					w.WriteHeader(http.StatusOK)
+				}),
+			}
+			req := httptest.NewRequest("PUT", "/project/"+tt.projectID, nil).WithContext(tt.ctx)
+			req = mux.SetURLVars(req, map[string]string{"project_id": tt.projectID})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("unexpected status code. Expected: %v Actual: %v", tt.want, rec.Code)
+			}
+			if reached != (tt.want == http.StatusOK) {
+				t.Errorf("unexpected call to route handler. Called: %v", reached)
+			}
+		})
+	}
+}
+
+func TestRequireJWTAuth_ServiceAccount(t *testing.T) {
+	auth := services.Auth{Secret: "manager", Issuer: "manager", ExpiryTime: 3600, CookieName: "manager"}
+	serviceToken := func(account services.ServiceAccount, scope services.Scope, projectID, resourceID string) string {
+		token, _ := services.NewServiceToken(account, scope, projectID, resourceID, "user", 3600, auth.Keys(), auth.Issuer)
+		return "Bearer " + token
+	}
+	userToken, _ := services.NewToken("user", 3600, auth.Secret, auth.Issuer)
+
+	tests := []struct {
+		name          string
+		serviceScope  services.Scope
+		path          string
+		url           string
+		authorization string
+		want          int
+		wantService   bool
+	}{
+		{
+			name:          "service token for the video segment",
+			serviceScope:  services.UpdateVideoSegmentStatus,
+			path:          "/project/{project_id}/videosegment/{videosegment_id}",
+			url:           "/project/p1/videosegment/v1",
+			authorization: serviceToken(services.ImageToVideo, services.UpdateVideoSegmentStatus, "p1", "v1"),
+			want:          http.StatusOK,
+			wantService:   true,
+		},
+		{
+			name:          "service token for the project",
+			serviceScope:  services.UpdateProjectStatus,
+			path:          "/project/{project_id}",
+			url:           "/project/p1",
+			authorization: serviceToken(services.ConcatenateVideo, services.UpdateProjectStatus, "p1", ""),
+			want:          http.StatusOK,
+			wantService:   true,
+		},
+		{
+			name:          "service token for another video segment",
+			serviceScope:  services.UpdateVideoSegmentStatus,
+			path:          "/project/{project_id}/videosegment/{videosegment_id}",
+			url:           "/project/p1/videosegment/v2",
+			authorization: serviceToken(services.ImageToVideo, services.UpdateVideoSegmentStatus, "p1", "v1"),
+			want:          http.StatusForbidden,
+		},
+		{
+			name:          "service token for another project",
+			serviceScope:  services.UpdateProjectStatus,
+			path:          "/project/{project_id}",
+			url:           "/project/p2",
+			authorization: serviceToken(services.ConcatenateVideo, services.UpdateProjectStatus, "p1", ""),
+			want:          http.StatusForbidden,
+		},
+		{
+			name:          "service token with another scope",
+			serviceScope:  services.UpdatePDFSlideImagesStatus,
+			path:          "/project/{project_id}/pdfslideimages/{pdfslideimages_id}",
+			url:           "/project/p1/pdfslideimages/v1",
+			authorization: serviceToken(services.ImageToVideo, services.UpdateVideoSegmentStatus, "p1", "v1"),
+			want:          http.StatusForbidden,
+		},
+		{
+			name:          "service token on route without service scope",
+			path:          "/project/{project_id}",
+			url:           "/project/p1",
+			authorization: serviceToken(services.ConcatenateVideo, services.UpdateProjectStatus, "p1", ""),
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "user token on route with service scope",
+			serviceScope:  services.UpdateProjectStatus,
+			path:          "/project/{project_id}",
+			url:           "/project/p1",
+			authorization: "Bearer " + userToken,
+			want:          http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isService := false
+			r := mux.NewRouter()
+			r.Handle(tt.path, RequireJWTAuth{
+				Auth:         auth,
+				Logger:       logger.LoggerForTests{Tester: t},
+				ServiceScope: tt.serviceScope,
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					isService = isServiceAccount(r.Context())
+					w.WriteHeader(http.StatusOK)
+				}),
+			})
+
+			req := httptest.NewRequest("PUT", tt.url, nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("unexpected status code. Expected: %v Actual: %v", tt.want, rec.Code)
+			}
+			if isService != tt.wantService {
+				t.Errorf("unexpected service account in request. Expected: %v Actual: %v", tt.wantService, isService)
+			}
+		})
+	}
+}
+
+type fakeAccessTokenStore struct {
+	accesstoken.Store
+	tokens []accesstoken.AccessToken
+}
+
+func (f fakeAccessTokenStore) GetByHash(ctx context.Context, hash string) (accesstoken.AccessToken, error) {
+	for _, t := range f.tokens {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+	return accesstoken.AccessToken{}, fmt.Errorf("access token not found")
+}
+
+func TestRequireJWTAuth_AccessToken(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	readOnly, readOnlyToken, _ := accesstoken.New("reader", "ci", string(accesstoken.ReadOnly), expiry)
+	projectWrite, projectWriteToken, _ := accesstoken.New("writer", "ci", string(accesstoken.ProjectWrite), expiry)
+	revoked, revokedToken, _ := accesstoken.New("writer", "ci", string(accesstoken.ProjectWrite), expiry)
+	setters, _ := accesstoken.Revoke()
+	setters[0](&revoked)
+	_, unknownToken, _ := accesstoken.New("writer", "ci", string(accesstoken.ProjectWrite), expiry)
+
+	auth := services.Auth{
+		Secret:       "manager",
+		CookieName:   "manager",
+		AccessTokens: fakeAccessTokenStore{tokens: []accesstoken.AccessToken{readOnly, projectWrite, revoked}},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		projectWrite bool
+		token        string
+		want         int
+		userID       string
+	}{
+		{name: "read only token on read", method: "GET", token: readOnlyToken, want: http.StatusOK, userID: "reader"},
+		{name: "read only token on write", method: "POST", projectWrite: true, token: readOnlyToken, want: http.StatusForbidden},
+		{name: "project write token on write", method: "POST", projectWrite: true, token: projectWriteToken, want: http.StatusOK, userID: "writer"},
+		{name: "project write token on write outside projects", method: "POST", token: projectWriteToken, want: http.StatusForbidden},
+		{name: "revoked token", method: "GET", token: revokedToken, want: http.StatusUnauthorized},
+		{name: "unknown token", method: "GET", token: unknownToken, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := ""
+			handler := RequireJWTAuth{
+				Auth:         auth,
+				Logger:       logger.LoggerForTests{Tester: t},
+				ProjectWrite: tt.projectWrite,
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					userID = r.Context().Value(userIDKey).(string)
+					w.WriteHeader(http.StatusOK)
+				}),
+			}
+
+			req := httptest.NewRequest(tt.method, "/projects", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("unexpected status code. Expected: %v Actual: %v", tt.want, rec.Code)
+			}
+			if userID != tt.userID {
+				t.Errorf("unexpected user of request. Expected: %v Actual: %v", tt.userID, userID)
+			}
+		})
+	}
+}
+
+type fakeSessionStore struct {
+	session.Store
+	sessions []session.Session
+}
+
+func (f fakeSessionStore) Get(ctx context.Context, ID string) (session.Session, error) {
+	for _, s := range f.sessions {
+		if s.ID == ID {
+			return s, nil
+		}
+	}
+	return session.Session{}, fmt.Errorf("session not found")
+}
+
+func TestRequireJWTAuth_Session(t *testing.T) {
+	active, _, _ := session.New("user", time.Hour)
+	revoked, _, _ := session.New("user", time.Hour)
+	setters, _ := session.Revoke()
+	setters[0](&revoked)
+
+	previousKeys := services.CookieKeys{HashKey: securecookie.GenerateRandomKey(64), BlockKey: securecookie.GenerateRandomKey(32)}
+	auth := services.Auth{
+		Secret:             "current",
+		PreviousSecrets:    []string{"previous"},
+		HashKey:            securecookie.GenerateRandomKey(64),
+		BlockKey:           securecookie.GenerateRandomKey(32),
+		PreviousCookieKeys: []services.CookieKeys{previousKeys},
+		CookieName:         "manager",
+		Sessions:           fakeSessionStore{sessions: []session.Session{active, revoked}},
+	}
+	bearer := func(userID, sessionID, secret string) string {
+		token, _ := services.NewSessionToken(userID, sessionID, 3600, services.SecretKeys(secret), "manager")
+		return "Bearer " + token
+	}
+	cookie := func(keys services.CookieKeys, sessionID string) string {
+		encoded, _ := securecookie.New(keys.HashKey, keys.BlockKey).Encode(auth.CookieName, map[string]string{"user_id": "user", "session_id": sessionID})
+		return encoded
+	}
+	currentKeys := services.CookieKeys{HashKey: auth.HashKey, BlockKey: auth.BlockKey}
+	otherKeys := services.CookieKeys{HashKey: securecookie.GenerateRandomKey(64), BlockKey: securecookie.GenerateRandomKey(32)}
+
+	tests := []struct {
+		name          string
+		authorization string
+		cookie        string
+		want          int
+	}{
+		{name: "token of active session", authorization: bearer("user", active.ID, "current"), want: http.StatusOK},
+		{name: "token signed with previous secret", authorization: bearer("user", active.ID, "previous"), want: http.StatusOK},
+		{name: "token signed with unknown secret", authorization: bearer("user", active.ID, "unknown"), want: http.StatusUnauthorized},
+		{name: "token of revoked session", authorization: bearer("user", revoked.ID, "current"), want: http.StatusUnauthorized},
+		{name: "token without session", authorization: bearer("user", "", "current"), want: http.StatusUnauthorized},
+		{name: "token of session of another user", authorization: bearer("other", active.ID, "current"), want: http.StatusUnauthorized},
+		{name: "cookie of active session", cookie: cookie(currentKeys, active.ID), want: http.StatusOK},
+		{name: "cookie encoded with previous keys", cookie: cookie(previousKeys, active.ID), want: http.StatusOK},
+		{name: "cookie encoded with unknown keys", cookie: cookie(otherKeys, active.ID), want: http.StatusUnauthorized},
+		{name: "cookie of revoked session", cookie: cookie(currentKeys, revoked.ID), want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequireJWTAuth{
+				Auth:   auth,
+				Logger: logger.LoggerForTests{Tester: t},
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Context().Value(sessionIDKey) != active.ID {
+						t.Errorf("expected session to be passed on to handler")
+					}
+					w.WriteHeader(http.StatusOK)
+				}),
+			}
+
+			req := httptest.NewRequest("GET", "/projects", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: auth.CookieName, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("unexpected status code. Expected: %v Actual: %v", tt.want, rec.Code)
+			}
+		})
+	}
+}
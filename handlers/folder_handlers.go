@@ -0,0 +1,672 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/folder"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
+)
+
+// maxProjectACLs is the maximum number of acls that is looked at when syncing inherited acls
+const maxProjectACLs = 1000
+
+// folderACL resolves the permission of the user on the folder through the shares of the folder and its parents
+func folderACL(ctx context.Context, store folder.Store, folderID, userID string) ([]folder.Folder, acl.ACL, error) {
+	chain, err := folder.Chain(ctx, store, folderID)
+	if err != nil {
+		return []folder.Folder{}, acl.ACL{}, err
+	}
+	a, err := folder.Permission(chain, userID)
+	return chain, a, err
+}
+
+// syncInheritedACLs brings the acls that a project inherits from the folder it is placed in
+// in line with the shares of the folder and its parents. Only acls that differ are changed so
+// that users whose inherited permission stays the same do not lose access while syncing
+// Permissions granted on the project directly take precedence over inherited permissions
+// The changes are not applied atomically - a sync that fails part way is completed by the next one
+func syncInheritedACLs(ctx context.Context, aclStore acl.Store, folderStore folder.Store, projectID, folderID string) error {
+	existing, err := aclStore.GetAll(ctx, projectID, maxProjectACLs, 0)
+	if err != nil {
+		return err
+	}
+	wanted := map[string]acl.ACL{}
+	if folderID != "" {
+		chain, err := folder.Chain(ctx, folderStore, folderID)
+		if err != nil {
+			return err
+		}
+		for userID, s := range folder.InheritedShares(chain) {
+			p, err := acl.ParsePermission(s.Permission)
+			if err != nil {
+				return err
+			}
+			wanted[userID] = acl.NewInherited(projectID, userID, s.FolderID, p)
+		}
+	}
+
+	for _, a := range existing {
+		if a.InheritedFrom == "" {
+			delete(wanted, a.UserID)
+		}
+	}
+	for _, a := range existing {
+		if a.InheritedFrom == "" {
+			continue
+		}
+		w, ok := wanted[a.UserID]
+		delete(wanted, a.UserID)
+		if !ok {
+			err = aclStore.Delete(ctx, projectID, a.UserID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if a.Permission == w.Permission && a.InheritedFrom == w.InheritedFrom {
+			continue
+		}
+		_, err = aclStore.Update(ctx, projectID, a.UserID, acl.SetInherited(w.InheritedFrom, w.Permission)...)
+		if err != nil {
+			return err
+		}
+	}
+	for _, w := range wanted {
+		err = aclStore.Create(ctx, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncFolderTree re-applies inherited acls to all projects within the folder and its subfolders
+func syncFolderTree(ctx context.Context, aclStore acl.Store, folderStore folder.Store, projectStore project.Store, folderID string) error {
+	folders, err := folder.Subtree(ctx, folderStore, folderID)
+	if err != nil {
+		return err
+	}
+	for _, f := range folders {
+		projects, err := projectStore.GetAllInFolder(ctx, f.ID)
+		if err != nil {
+			return err
+		}
+		for _, p := range projects {
+			err = syncInheritedACLs(ctx, aclStore, folderStore, p.ID, f.ID)
+			if err != nil {
+				return fmt.Errorf("unable to update acls of project %v. err: %v", p.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+type CreateFolder struct {
+	Logger      logger.Logger
+	FolderStore folder.Store
+}
+
+func (h CreateFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start CreateFolder API Handler")
+	defer h.Logger.Info("End CreateFolder API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+
+	type createFolderReq struct {
+		Name     string `json:"name"`
+		ParentID string `json:"parent_id"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := createFolderReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	if req.ParentID != "" {
+		chain, parentACL, err := folderACL(ctx, h.FolderStore, req.ParentID, userID)
+		if err != nil || !parentACL.IsAuthorized(acl.EditScript) {
+			errMsg := fmt.Sprintf("Error - unable to confirm acl for parent folder. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		if len(chain) >= folder.MaxDepth {
+			errMsg := fmt.Sprintf("Error - folders cannot be nested more than %v levels", folder.MaxDepth)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	item, err := folder.New(req.Name, req.ParentID, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	err = h.FolderStore.Create(ctx, item)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create folder in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type GetFolders struct {
+	Logger      logger.Logger
+	FolderStore folder.Store
+}
+
+// ServeHTTP lists the subfolders of the parent folder. The folders shared with the user
+// are listed instead if no parent folder is provided
+func (h GetFolders) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetFolders API Handler")
+	defer h.Logger.Info("End GetFolders API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	parentID := r.URL.Query().Get("parent_id")
+
+	var folders []folder.Folder
+	var err error
+	if parentID == "" {
+		folders, err = h.FolderStore.GetShared(ctx, userID)
+	} else {
+		_, parentACL, aclErr := folderACL(ctx, h.FolderStore, parentID, userID)
+		if aclErr != nil || !parentACL.IsAuthorized(acl.View) {
+			errMsg := fmt.Sprintf("Error - unable to confirm acl for folder. Error: %v", aclErr)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		folders, err = h.FolderStore.GetChildren(ctx, parentID)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve folders. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type getFoldersResp struct {
+		Folders []folder.Folder `json:"folders"`
+	}
+	rawResp, _ := json.Marshal(getFoldersResp{Folders: folders})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type GetFolder struct {
+	Logger      logger.Logger
+	FolderStore folder.Store
+}
+
+func (h GetFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetFolder API Handler")
+	defer h.Logger.Info("End GetFolder API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	folderID := mux.Vars(r)["folder_id"]
+
+	chain, folderPermission, err := folderACL(ctx, h.FolderStore, folderID, userID)
+	if err != nil || !folderPermission.IsAuthorized(acl.View) {
+		errMsg := fmt.Sprintf("Error - unable to confirm acl for folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(chain[0])
+	w.Write(rawItem)
+}
+
+type UpdateFolder struct {
+	Logger       logger.Logger
+	FolderStore  folder.Store
+	ProjectStore project.Store
+	ACLStore     acl.Store
+}
+
+// ServeHTTP renames the folder or moves it under another folder
+// An empty parent id moves the folder to the top level
+func (h UpdateFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateFolder API Handler")
+	defer h.Logger.Info("End UpdateFolder API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	folderID := mux.Vars(r)["folder_id"]
+
+	_, folderPermission, err := folderACL(ctx, h.FolderStore, folderID, userID)
+	if err != nil || !folderPermission.IsAuthorized(acl.EditScript) {
+		errMsg := fmt.Sprintf("Error - unable to confirm acl for folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type updateFolderReq struct {
+		Name     string  `json:"name"`
+		ParentID *string `json:"parent_id"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := updateFolderReq{}
+	err = json.Unmarshal(rawReq, &req)
+	if err != nil || (req.Name == "" && req.ParentID == nil) {
+		errMsg := fmt.Sprintf("Error - name or parent_id is required to update folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	var setters []func(*folder.Folder) error
+	if req.Name != "" {
+		nameSetters, err := folder.SetName(req.Name)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - invalid folder name. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		setters = append(setters, nameSetters...)
+	}
+	if req.ParentID != nil {
+		// Projects within the folder inherit access from the folders that it is moved under
+		if !folderPermission.IsAuthorized(acl.Share) {
+			errMsg := "Error - role on the folder does not allow moving it"
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		parentChain := []folder.Folder{}
+		if *req.ParentID != "" {
+			var parentACL acl.ACL
+			parentChain, parentACL, err = folderACL(ctx, h.FolderStore, *req.ParentID, userID)
+			if err != nil || !parentACL.IsAuthorized(acl.EditScript) {
+				errMsg := fmt.Sprintf("Error - unable to confirm acl for parent folder. Error: %v", err)
+				h.Logger.Error(errMsg)
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(generateErrorResp(errMsg)))
+				return
+			}
+		}
+		parentSetters, err := folder.SetParent(parentChain)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to move folder. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		setters = append(setters, parentSetters...)
+	}
+
+	item, err := h.FolderStore.Update(ctx, folderID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	if req.ParentID != nil {
+		err = syncFolderTree(ctx, h.ACLStore, h.FolderStore, h.ProjectStore, folderID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to update acls of projects in folder. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type DeleteFolder struct {
+	Logger       logger.Logger
+	FolderStore  folder.Store
+	ProjectStore project.Store
+}
+
+// ServeHTTP removes the folder. Only empty folders can be removed
+func (h DeleteFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start DeleteFolder API Handler")
+	defer h.Logger.Info("End DeleteFolder API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	folderID := mux.Vars(r)["folder_id"]
+
+	_, folderPermission, err := folderACL(ctx, h.FolderStore, folderID, userID)
+	if err != nil || !folderPermission.IsAuthorized(acl.Delete) {
+		errMsg := fmt.Sprintf("Error - role on the folder does not allow deleting it. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	children, err := h.FolderStore.GetChildren(ctx, folderID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve subfolders. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	projects, err := h.ProjectStore.GetAllInFolder(ctx, folderID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve projects in folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if len(children) > 0 || len(projects) > 0 {
+		errMsg := "Error - folder needs to be emptied before it can be deleted"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = h.FolderStore.Delete(ctx, folderID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to delete folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type ShareFolder struct {
+	Logger       logger.Logger
+	FolderStore  folder.Store
+	ProjectStore project.Store
+	ACLStore     acl.Store
+	UserStore    user.Store
+}
+
+func (h ShareFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start ShareFolder API Handler")
+	defer h.Logger.Info("End ShareFolder API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	folderID := mux.Vars(r)["folder_id"]
+
+	_, folderPermission, err := folderACL(ctx, h.FolderStore, folderID, userID)
+	if err != nil || !folderPermission.IsAuthorized(acl.Share) {
+		errMsg := fmt.Sprintf("Error - role on the folder does not allow sharing it. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type shareFolderReq struct {
+		Email      string `json:"email"`
+		Permission string `json:"permission"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := shareFolderReq{}
+	err = json.Unmarshal(rawReq, &req)
+	if err != nil || req.Email == "" {
+		errMsg := fmt.Sprintf("Error - email of user is required to share folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	sharedUser, err := h.UserStore.GetUserByEmail(ctx, req.Email)
+	if err != nil || sharedUser.ID == "" {
+		errMsg := fmt.Sprintf("Error - unable to find user to share folder with. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, err := folder.SetShare(sharedUser.ID, req.Permission)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid share. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	// The folder can only be shared with roles that do not allow more than the role of the user
+	newPermission, _ := acl.ParsePermission(req.Permission)
+	if !folderPermission.Permission.Includes(newPermission) {
+		errMsg := fmt.Sprintf("Error - role on the folder does not allow granting role %v", newPermission)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	item, err := h.FolderStore.Update(ctx, folderID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to share folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = syncFolderTree(ctx, h.ACLStore, h.FolderStore, h.ProjectStore, folderID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update acls of projects in folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type UnshareFolder struct {
+	Logger       logger.Logger
+	FolderStore  folder.Store
+	ProjectStore project.Store
+	ACLStore     acl.Store
+}
+
+func (h UnshareFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UnshareFolder API Handler")
+	defer h.Logger.Info("End UnshareFolder API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	folderID := mux.Vars(r)["folder_id"]
+	sharedUserID := mux.Vars(r)["user_id"]
+
+	_, folderPermission, err := folderACL(ctx, h.FolderStore, folderID, userID)
+	if err != nil || !folderPermission.IsAuthorized(acl.Share) {
+		errMsg := fmt.Sprintf("Error - role on the folder does not allow changing its shares. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, _ := folder.RemoveShare(sharedUserID)
+	item, err := h.FolderStore.Update(ctx, folderID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to remove share of folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = syncFolderTree(ctx, h.ACLStore, h.FolderStore, h.ProjectStore, folderID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update acls of projects in folder. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type MoveProject struct {
+	Logger       logger.Logger
+	ProjectStore project.Store
+	FolderStore  folder.Store
+	ACLStore     acl.Store
+}
+
+// ServeHTTP places the project in a folder. An empty folder id moves the project out of all folders
+func (h MoveProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start MoveProject API Handler")
+	defer h.Logger.Info("End MoveProject API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	projectID := mux.Vars(r)["project_id"]
+
+	type moveProjectReq struct {
+		FolderID string `json:"folder_id"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := moveProjectReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	if req.FolderID != "" {
+		_, folderPermission, err := folderACL(ctx, h.FolderStore, req.FolderID, userID)
+		if err != nil || !folderPermission.IsAuthorized(acl.EditScript) {
+			errMsg := fmt.Sprintf("Error - unable to confirm acl for folder. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	setters, _ := project.MoveToFolder(req.FolderID)
+	item, err := h.ProjectStore.Update(ctx, projectID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to move project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = syncInheritedACLs(ctx, h.ACLStore, h.FolderStore, projectID, req.FolderID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update acls of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type UpdateProjectTags struct {
+	Logger       logger.Logger
+	ProjectStore project.Store
+}
+
+// ServeHTTP replaces the tags of the project
+func (h UpdateProjectTags) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateProjectTags API Handler")
+	defer h.Logger.Info("End UpdateProjectTags API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	type updateProjectTagsReq struct {
+		Tags []string `json:"tags"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := updateProjectTagsReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, err := project.SetTags(req.Tags)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid tags. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	item, err := h.ProjectStore.Update(ctx, projectID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update tags of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
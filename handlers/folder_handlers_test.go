@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/folder"
+)
+
+type fakeFolderStore struct {
+	folder.Store
+	folders map[string]folder.Folder
+}
+
+func (f fakeFolderStore) Get(ctx context.Context, ID string) (folder.Folder, error) {
+	fo, ok := f.folders[ID]
+	if !ok {
+		return folder.Folder{}, fmt.Errorf("folder not found")
+	}
+	return fo, nil
+}
+
+func TestSyncInheritedACLs(t *testing.T) {
+	parent, _ := folder.New("parent", "", "owner")
+	parent.Shares = append(parent.Shares, folder.FolderShare{FolderID: parent.ID, UserID: "reader", Permission: string(acl.Reader)})
+	child, _ := folder.New("child", parent.ID, "owner")
+	child.Shares = append(child.Shares, folder.FolderShare{FolderID: child.ID, UserID: "direct", Permission: string(acl.Reader)})
+	folderStore := fakeFolderStore{folders: map[string]folder.Folder{parent.ID: parent, child.ID: child}}
+
+	unchanged := acl.NewInherited("project", "owner", child.ID, acl.Owner)
+	changed := acl.NewInherited("project", "reader", child.ID, acl.Editor)
+	direct := acl.New("project", "direct")
+	direct.Permission = acl.Editor
+	aclStore := fakeACLStore{acls: map[string]acl.ACL{
+		"project/owner":   unchanged,
+		"project/reader":  changed,
+		"project/direct":  direct,
+		"project/removed": acl.NewInherited("project", "removed", parent.ID, acl.Reader),
+	}}
+
+	err := syncInheritedACLs(context.TODO(), aclStore, folderStore, "project", child.ID)
+	if err != nil {
+		t.Fatalf("unexpected error when syncing acls. Err: %v", err)
+	}
+	if a := aclStore.acls["project/owner"]; a != unchanged {
+		t.Errorf("expected unchanged inherited acl to be kept as it is. %+v", a)
+	}
+	if a := aclStore.acls["project/reader"]; a.ID != changed.ID || a.Permission != acl.Reader || a.InheritedFrom != parent.ID {
+		t.Errorf("expected inherited acl to be updated to the share of the parent folder. %+v", a)
+	}
+	if a := aclStore.acls["project/direct"]; a != direct {
+		t.Errorf("expected direct acl to take precedence over folder share. %+v", a)
+	}
+	if _, ok := aclStore.acls["project/removed"]; ok {
+		t.Errorf("expected acl of share that no longer exists to be removed")
+	}
+	if len(aclStore.acls) != 3 {
+		t.Errorf("expected 3 acls on the project. %+v", aclStore.acls)
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
 )
 
+// imagesFolder is the folder in blob storage that the pdf splitter saves the slide images to
+const imagesFolder = "images"
+
 type DownloadImage struct {
 	Logger        logger.Logger
 	StorageClient blobstorage.BlobStorage
@@ -28,7 +31,7 @@ func (h DownloadImage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	content, err := h.StorageClient.Load(context.Background(), "images/"+filename)
+	content, err := h.StorageClient.Load(context.Background(), imagesFolder+"/"+filename)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - Unable to download file from blob storage. Err: %v", err)
 		h.Logger.Error(errMsg)
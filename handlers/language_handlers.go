@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type UpdateProjectLanguages struct {
+	Logger       logger.Logger
+	ProjectStore project.Store
+}
+
+// ServeHTTP replaces the additional languages of the project
+// Scripts of video segments in languages that are removed are kept but are no longer generated
+func (h UpdateProjectLanguages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateProjectLanguages API Handler")
+	defer h.Logger.Info("End UpdateProjectLanguages API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	type updateProjectLanguagesReq struct {
+		Languages []project.ProjectLanguage `json:"languages"`
+	}
+	req := updateProjectLanguagesReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, err := project.SetLanguages(req.Languages)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid languages. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	item, err := h.ProjectStore.Update(ctx, projectID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update languages of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type updateProjectLanguagesResp struct {
+		PrimaryLanguage string                    `json:"primary_language"`
+		Languages       []project.ProjectLanguage `json:"languages"`
+	}
+	languages := item.Languages
+	if languages == nil {
+		languages = []project.ProjectLanguage{}
+	}
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(updateProjectLanguagesResp{
+		PrimaryLanguage: item.PrimaryLanguage(),
+		Languages:       languages,
+	})
+	w.Write(rawItem)
+}
+
+type UpdateVideoSegmentTranslation struct {
+	Logger            logger.Logger
+	ProjectStore      project.Store
+	VideoSegmentStore videosegment.Store
+}
+
+// ServeHTTP sets the script of the video segment in one of the additional languages of the project
+func (h UpdateVideoSegmentTranslation) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateVideoSegmentTranslation API Handler")
+	defer h.Logger.Info("End UpdateVideoSegmentTranslation API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoSegmentID := mux.Vars(r)["videosegment_id"]
+	language := mux.Vars(r)["language"]
+
+	singleProject, err := h.ProjectStore.Get(ctx, projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	l, ok := singleProject.Language(language)
+	if !ok {
+		errMsg := fmt.Sprintf("Error - language %v is not an additional language of project. Scripts in the primary language are set on the video segment", language)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	var setters []func(*videosegment.VideoSegment) error
+	if r.Method == http.MethodDelete {
+		setters, err = videosegment.RemoveTranslation(l.Code)
+	} else {
+		rawReq, _ := ioutil.ReadAll(r.Body)
+		type updateVideoSegmentTranslationReq struct {
+			Script string `json:"script"`
+		}
+		req := updateVideoSegmentTranslationReq{}
+		err = json.Unmarshal(rawReq, &req)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		setters, err = videosegment.SetTranslationScript(l.Code, req.Script)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid translation. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	item, err := h.VideoSegmentStore.Update(ctx, projectID, videoSegmentID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update translation of video segment. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+)
+
+type UploadBackgroundMusic struct {
+	Logger           logger.Logger
+	ProjectStore     project.Store
+	VideoOutputStore videooutput.Store
+	Blobstorage      blobstorage.BlobStorage
+}
+
+// ServeHTTP uploads the background music of the project or of a video output of the project
+// The music file is sent as the myfile form field alongside the music settings as form values
+func (h UploadBackgroundMusic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UploadBackgroundMusic API Handler")
+	defer h.Logger.Info("End UploadBackgroundMusic API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoOutputID := mux.Vars(r)["videooutput_id"]
+
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve parse multipart form data. Error: %+v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	file, fileHeader, err := r.FormFile("myfile")
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve form data. Error: %+v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	defer file.Close()
+	if !settings.IsMusicFormatSupported(fileHeader.Filename) {
+		errMsg := fmt.Sprintf("Error - unsupported music format %v. Only mp3, m4a, aac, wav and ogg files are supported", filepath.Ext(fileHeader.Filename))
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	music, err := parseMusicForm(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse music settings. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	musicID, _ := uuid.NewV4()
+	music.File = projectID + "-music-" + musicID.String() + strings.ToLower(filepath.Ext(fileHeader.Filename))
+	err = music.Validate()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid music settings. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	var b bytes.Buffer
+	bw := bufio.NewWriter(&b)
+	io.Copy(bw, file)
+	bw.Flush()
+	err = h.Blobstorage.Save(ctx, music.File, b.Bytes())
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to save music file. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawItem, statusCode, err := setBackgroundMusic(ctx, h.ProjectStore, h.VideoOutputStore, projectID, videoOutputID, music)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to set background music. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(statusCode)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(rawItem)
+}
+
+type UpdateBackgroundMusic struct {
+	Logger           logger.Logger
+	ProjectStore     project.Store
+	VideoOutputStore videooutput.Store
+}
+
+// ServeHTTP changes the volume, ducking and fades of the background music without uploading
+// the music file again. A DELETE request removes the background music
+func (h UpdateBackgroundMusic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateBackgroundMusic API Handler")
+	defer h.Logger.Info("End UpdateBackgroundMusic API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoOutputID := mux.Vars(r)["videooutput_id"]
+
+	music := settings.Music{}
+	if r.Method != http.MethodDelete {
+		var current settings.Music
+		if videoOutputID == "" {
+			singleProject, err := h.ProjectStore.Get(ctx, projectID)
+			if err != nil {
+				errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+				h.Logger.Error(errMsg)
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(generateErrorResp(errMsg)))
+				return
+			}
+			current = singleProject.Music
+		} else {
+			videoOutput, err := h.VideoOutputStore.Get(ctx, projectID, videoOutputID)
+			if err != nil {
+				errMsg := fmt.Sprintf("Error - unable to retrieve video output. Error: %v", err)
+				h.Logger.Error(errMsg)
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(generateErrorResp(errMsg)))
+				return
+			}
+			current = videoOutput.Music
+		}
+		if current.File == "" {
+			errMsg := fmt.Sprintf("Error - no background music uploaded. Upload a music file first")
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+
+		rawReq, _ := ioutil.ReadAll(r.Body)
+		err := json.Unmarshal(rawReq, &music)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		music.File = current.File
+	}
+
+	rawItem, statusCode, err := setBackgroundMusic(ctx, h.ProjectStore, h.VideoOutputStore, projectID, videoOutputID, music)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to set background music. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(statusCode)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawItem)
+}
+
+// setBackgroundMusic sets the music of the project or of the video output if a video output id
+// is provided. The updated project or video output is returned as json
+func setBackgroundMusic(ctx context.Context, projectStore project.Store, videoOutputStore videooutput.Store, projectID, videoOutputID string, music settings.Music) ([]byte, int, error) {
+	if videoOutputID == "" {
+		setters, err := project.SetMusic(music)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		item, err := projectStore.Update(ctx, projectID, setters...)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		rawItem, _ := json.Marshal(item)
+		return rawItem, http.StatusOK, nil
+	}
+	setters, err := videooutput.SetMusic(music)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	item, err := videoOutputStore.Update(ctx, projectID, videoOutputID, setters...)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	rawItem, _ := json.Marshal(item)
+	return rawItem, http.StatusOK, nil
+}
+
+func parseMusicForm(r *http.Request) (settings.Music, error) {
+	music := settings.Music{
+		Ducking: r.FormValue("ducking") == "true",
+	}
+	values := map[string]*float64{
+		"volume":        &music.Volume,
+		"ducking_ratio": &music.DuckingRatio,
+		"fade_in":       &music.FadeIn,
+		"fade_out":      &music.FadeOut,
+	}
+	for name, value := range values {
+		raw := r.FormValue(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return settings.Music{}, fmt.Errorf("invalid %v value %v", name, raw)
+		}
+		*value = parsed
+	}
+	return music, nil
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/securecookie"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/identity"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/oidc"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
+)
+
+// oidcCookieName keeps the sign in request on the browser while the user is at the provider
+const oidcCookieName = "slidestovideo_oidc"
+
+// oidcRequestLifetime is the time that the user has to sign in at the provider
+const oidcRequestLifetime = 10 * time.Minute
+
+var errEmailNotVerified = errors.New("Email has not been verified by the provider")
+
+// allowedRedirect checks that the user can be sent to the url after signing in. Paths on the
+// manager are always allowed while other sites need to be one of the origins
+func allowedRedirect(sourceURL string, origins []string) bool {
+	u, err := url.Parse(sourceURL)
+	if err != nil || strings.Contains(sourceURL, "\\") {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(sourceURL, "/") && !strings.HasPrefix(sourceURL, "//")
+	}
+	for _, origin := range origins {
+		if u.Scheme+"://"+u.Host == strings.TrimSuffix(origin, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+type OIDCLogin struct {
+	Logger          logger.Logger
+	Auth            services.Auth
+	Providers       map[string]*oidc.Provider
+	RedirectOrigins []string
+}
+
+// ServeHTTP sends the user to the provider to sign in. The state, nonce and PKCE verifier of
+// the request are kept in a cookie so that they can be checked once the user returns
+func (h OIDCLogin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start OIDCLogin Handler")
+	defer h.Logger.Info("End OIDCLogin Handler")
+
+	providerName := mux.Vars(r)["provider"]
+	provider, ok := h.Providers[providerName]
+	if !ok {
+		errMsg := fmt.Sprintf("Error - provider %v is not configured", providerName)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	sourceURL := r.URL.Query().Get("source_url")
+	if sourceURL != "" && !allowedRedirect(sourceURL, h.RedirectOrigins) {
+		errMsg := fmt.Sprintf("Error - users cannot be sent to %v after signing in", sourceURL)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	authReq, err := oidc.NewAuthRequest()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create sign in request. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	authURL, err := provider.AuthCodeURL(r.Context(), authReq)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to reach provider. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	value := map[string]string{
+		"provider":      providerName,
+		"state":         authReq.State,
+		"nonce":         authReq.Nonce,
+		"code_verifier": authReq.CodeVerifier,
+		"source_url":    sourceURL,
+		"issued_at":     strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	encoded, err := securecookie.EncodeMulti(oidcCookieName, value, h.Auth.CookieCodecs()...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create sign in request. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	// The cookie has to be sent along when the provider redirects the user back
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    encoded,
+		Path:     "/",
+		MaxAge:   int(oidcRequestLifetime.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
+}
+
+type OIDCCallback struct {
+	Logger        logger.Logger
+	Auth          services.Auth
+	Providers     map[string]*oidc.Provider
+	UserStore     user.Store
+	IdentityStore identity.Store
+}
+
+// ServeHTTP signs the user in once the user returns from the provider. The user is found by the
+// identity at the provider, or by the email if the provider has verified it. Users are created
+// if there is no user with the email
+func (h OIDCCallback) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start OIDCCallback Handler")
+	defer h.Logger.Info("End OIDCCallback Handler")
+
+	ctx := r.Context()
+	providerName := mux.Vars(r)["provider"]
+	provider, ok := h.Providers[providerName]
+	if !ok {
+		errMsg := fmt.Sprintf("Error - provider %v is not configured", providerName)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("error") != "" {
+		errMsg := fmt.Sprintf("Error - provider did not sign user in. Error: %v %v", q.Get("error"), q.Get("error_description"))
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	// The sign in request can only be used once
+	authReq := map[string]string{}
+	cookie, err := r.Cookie(oidcCookieName)
+	if err == nil {
+		err = securecookie.DecodeMulti(oidcCookieName, cookie.Value, &authReq, h.Auth.CookieCodecs()...)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - sign in request not found. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	issuedAt, _ := strconv.ParseInt(authReq["issued_at"], 10, 64)
+	if authReq["provider"] != providerName || time.Since(time.Unix(issuedAt, 0)) > oidcRequestLifetime ||
+		q.Get("state") == "" || subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(authReq["state"])) != 1 {
+		errMsg := "Error - sign in request does not match the response of the provider"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	token, err := provider.Exchange(ctx, q.Get("code"), authReq["code_verifier"])
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to redeem code with provider. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, authReq["nonce"])
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid ID token. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	userID, err := h.linkUser(ctx, providerName, claims)
+	if errors.Is(err, errEmailNotVerified) {
+		errMsg := fmt.Sprintf("Error - unable to link account at provider. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to link account at provider. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	s, tokens, err := startSession(ctx, h.Auth, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to start session. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	err = setSessionCookie(w, h.Auth, s)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to set authorization token. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	if authReq["source_url"] != "" {
+		redirectURL, _ := url.Parse(authReq["source_url"])
+		params := redirectURL.Query()
+		params.Set("token", tokens.Token)
+		redirectURL.RawQuery = params.Encode()
+		http.Redirect(w, r, redirectURL.String(), http.StatusTemporaryRedirect)
+		return
+	}
+
+	rawTokens, _ := json.Marshal(tokens)
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawTokens)
+}
+
+// linkUser finds the user of the account at the provider. Accounts are linked to the user with
+// the same email the first time they are used, provided that the email has been verified
+func (h OIDCCallback) linkUser(ctx context.Context, providerName string, claims oidc.Claims) (string, error) {
+	i, err := h.IdentityStore.GetBySubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		return i.UserID, nil
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return "", errEmailNotVerified
+	}
+	u, err := h.UserStore.GetUserByEmail(ctx, claims.Email)
+	if err != nil {
+		return "", err
+	}
+	if u.ID == "" {
+		u, err = user.NewFromProvider(claims.Email)
+		if err != nil {
+			return "", err
+		}
+		err = h.UserStore.Create(ctx, u)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	i, err = identity.New(u.ID, providerName, claims.Subject, claims.Email)
+	if err != nil {
+		return "", err
+	}
+	err = h.IdentityStore.Create(ctx, i)
+	if err != nil {
+		return "", err
+	}
+	return u.ID, nil
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/securecookie"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/identity"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/oidc"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/oidc/oidctest"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
+)
+
+type fakeUserStore struct {
+	user.Store
+	users map[string]user.User
+}
+
+func (f fakeUserStore) Create(ctx context.Context, u user.User) error {
+	f.users[u.ID] = u
+	return nil
+}
+
+func (f fakeUserStore) GetUserByEmail(ctx context.Context, email string) (user.User, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return user.User{}, nil
+}
+
+type fakeIdentityStore struct {
+	identities map[string]identity.Identity
+}
+
+func (f fakeIdentityStore) Create(ctx context.Context, i identity.Identity) error {
+	f.identities[i.Provider+"/"+i.Subject] = i
+	return nil
+}
+
+func (f fakeIdentityStore) GetBySubject(ctx context.Context, provider, subject string) (identity.Identity, error) {
+	i, ok := f.identities[provider+"/"+subject]
+	if !ok {
+		return identity.Identity{}, fmt.Errorf("identity not found")
+	}
+	return i, nil
+}
+
+type createOnlySessionStore struct {
+	session.Store
+}
+
+func (f createOnlySessionStore) Create(ctx context.Context, s session.Session) error {
+	return nil
+}
+
+func TestOIDCCallback(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	provider, _ := oidc.NewProvider(server.Client(), oidc.Config{
+		Name:         "mock",
+		Issuer:       server.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURI:  "http://localhost/api/v1/callback/oidc/mock",
+	})
+
+	existing, _ := user.NewFromProvider("existing@example.com")
+	users := fakeUserStore{users: map[string]user.User{existing.ID: existing}}
+	identities := fakeIdentityStore{identities: map[string]identity.Identity{}}
+	auth := services.Auth{
+		Secret:     "manager",
+		Issuer:     "manager",
+		ExpiryTime: 3600,
+		HashKey:    securecookie.GenerateRandomKey(64),
+		BlockKey:   securecookie.GenerateRandomKey(32),
+		CookieName: "manager",
+		Sessions:   createOnlySessionStore{},
+	}
+	providers := map[string]*oidc.Provider{"mock": provider}
+	login := OIDCLogin{Logger: logger.LoggerForTests{Tester: t}, Auth: auth, Providers: providers}
+	callback := OIDCCallback{Logger: logger.LoggerForTests{Tester: t}, Auth: auth, Providers: providers, UserStore: users, IdentityStore: identities}
+
+	// signIn goes through the login with the provider and returns the response of the callback
+	signIn := func(tamper func(q url.Values)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/connect/oidc/mock?source_url=/projects", nil)
+		req = mux.SetURLVars(req, map[string]string{"provider": "mock"})
+		resp := httptest.NewRecorder()
+		login.ServeHTTP(resp, req)
+		if resp.Code != http.StatusTemporaryRedirect {
+			t.Fatalf("expected user to be sent to provider. Got %v %v", resp.Code, resp.Body.String())
+		}
+
+		client := server.Client()
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+		providerResp, err := client.Get(resp.Header().Get("Location"))
+		if err != nil || providerResp.StatusCode != http.StatusFound {
+			t.Fatalf("unable to sign in at provider. %v %v", providerResp, err)
+		}
+		returned, _ := url.Parse(providerResp.Header.Get("Location"))
+		q := returned.Query()
+		if tamper != nil {
+			tamper(q)
+		}
+
+		req = httptest.NewRequest("GET", "/api/v1/callback/oidc/mock?"+q.Encode(), nil)
+		req = mux.SetURLVars(req, map[string]string{"provider": "mock"})
+		for _, c := range resp.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		resp = httptest.NewRecorder()
+		callback.ServeHTTP(resp, req)
+		return resp
+	}
+	userOfToken := func(resp *httptest.ResponseRecorder) string {
+		location, _ := url.Parse(resp.Header().Get("Location"))
+		userID, _ := services.ExtractToken("Bearer "+location.Query().Get("token"), auth.Secret)
+		return userID
+	}
+
+	server.SetUser(oidctest.User{Subject: "unverified", Email: "existing@example.com"})
+	if resp := signIn(nil); resp.Code != http.StatusForbidden {
+		t.Errorf("expected unverified email to be rejected. Got %v %v", resp.Code, resp.Body.String())
+	}
+
+	server.SetUser(oidctest.User{Subject: "1234", Email: "existing@example.com", EmailVerified: true})
+	if resp := signIn(func(q url.Values) { q.Set("state", "other") }); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected state of other request to be rejected. Got %v %v", resp.Code, resp.Body.String())
+	}
+	resp := signIn(nil)
+	if resp.Code != http.StatusTemporaryRedirect || userOfToken(resp) != existing.ID {
+		t.Fatalf("expected account to be linked to existing user. Got %v %v %v", resp.Code, resp.Header(), resp.Body.String())
+	}
+
+	// Linked accounts stay with the user even if the email at the provider changes
+	server.SetUser(oidctest.User{Subject: "1234", Email: "renamed@example.com"})
+	if resp := signIn(nil); userOfToken(resp) != existing.ID {
+		t.Errorf("expected linked account to sign in as existing user. Got %v %v", resp.Code, resp.Body.String())
+	}
+
+	server.SetUser(oidctest.User{Subject: "5678", Email: "new@example.com", EmailVerified: true})
+	resp = signIn(nil)
+	newUserID := userOfToken(resp)
+	if newUserID == "" || newUserID == existing.ID || users.users[newUserID].Email != "new@example.com" {
+		t.Errorf("expected user to be created for new email. Got %v %v", resp.Code, resp.Body.String())
+	}
+}
+
+func TestAllowedRedirect(t *testing.T) {
+	origins := []string{"https://slides.example.com"}
+	tests := []struct {
+		sourceURL string
+		want      bool
+	}{
+		{sourceURL: "/projects", want: true},
+		{sourceURL: "https://slides.example.com/projects", want: true},
+		{sourceURL: "https://other.example.com/projects", want: false},
+		{sourceURL: "//other.example.com/projects", want: false},
+		{sourceURL: "/\\other.example.com", want: false},
+		{sourceURL: "projects", want: false},
+	}
+	for _, tt := range tests {
+		if got := allowedRedirect(tt.sourceURL, origins); got != tt.want {
+			t.Errorf("allowedRedirect(%v) = %v, want %v", tt.sourceURL, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/subtitles"
+)
+
+// maxOutputVersions is the maximum number of versions that is looked at when
+// checking versions for cleanup
+const maxOutputVersions = 1000
+
+// recordOutputVersion stores the completed render as the next version of the video
+// Versions that are beyond the retention of the project are removed along with their videos
+// Videos that other projects still refer to are kept
+func recordOutputVersion(ctx context.Context, l logger.Logger, store outputversion.Store, projectStore project.Store, storage blobstorage.BlobStorage, folders project.Folders, projectID, videoOutputID, blobID string, videoFiles []string, versionsToKeep int) error {
+	latest, err := store.GetAll(ctx, projectID, videoOutputID, 1, 0)
+	if err != nil {
+		return err
+	}
+	versionNo := 1
+	if len(latest) > 0 {
+		versionNo = latest[0].Version + 1
+	}
+	err = store.Create(ctx, outputversion.New(projectID, videoOutputID, versionNo, blobID, videoFiles))
+	if err != nil {
+		return err
+	}
+
+	versions, err := store.GetAll(ctx, projectID, videoOutputID, maxOutputVersions, 0)
+	if err != nil {
+		return err
+	}
+	expired := outputversion.Expired(versions, versionsToKeep)
+	if len(expired) == 0 {
+		return nil
+	}
+	p, err := projectStore.Get(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	shared, err := project.SharedBlobs(ctx, projectStore, p, folders)
+	if err != nil {
+		return err
+	}
+	for _, v := range expired {
+		if !shared[v.BlobID] {
+			err = storage.Delete(ctx, v.BlobID)
+			if err != nil {
+				// Video might have already been removed; the version record is still removed
+				l.Errorf("unable to delete video of expired version. ProjectID: %v :: Version: %v :: Err: %v", projectID, v.Version, err)
+			}
+			// Subtitles are not created for videos without scripts so missing files are expected
+			for format := range subtitles.Formats {
+				storage.Delete(ctx, subtitles.FileName(v.BlobID, format))
+			}
+		}
+		err = store.Delete(ctx, projectID, v.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type GetOutputVersions struct {
+	Logger             logger.Logger
+	OutputVersionStore outputversion.Store
+}
+
+func (h GetOutputVersions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetOutputVersions API Handler")
+	defer h.Logger.Info("End GetOutputVersions API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	rawOffset := r.URL.Query().Get("offset")
+	offset := 0
+	if rawOffset != "" {
+		offset, _ = strconv.Atoi(rawOffset)
+	}
+	rawLimit := r.URL.Query().Get("limit")
+	limit := 10
+	if rawLimit != "" {
+		limit, _ = strconv.Atoi(rawLimit)
+	}
+	videoOutputID := r.URL.Query().Get("video_output_id")
+
+	versions, err := h.OutputVersionStore.GetAll(ctx, projectID, videoOutputID, limit, offset)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve output versions. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type getOutputVersionsResp struct {
+		Versions []outputversion.OutputVersion `json:"versions"`
+		Limit    int                           `json:"limit"`
+		Offset   int                           `json:"offset"`
+	}
+	rawResp, _ := json.Marshal(getOutputVersionsResp{
+		Versions: versions,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type DownloadOutputVersion struct {
+	Logger             logger.Logger
+	OutputVersionStore outputversion.Store
+	StorageClient      blobstorage.BlobStorage
+}
+
+func (h DownloadOutputVersion) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start DownloadOutputVersion API Handler")
+	defer h.Logger.Info("End DownloadOutputVersion API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	outputVersionID := mux.Vars(r)["outputversion_id"]
+
+	version, err := h.OutputVersionStore.Get(ctx, projectID, outputVersionID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve output version. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	content, err := h.StorageClient.Load(ctx, version.BlobID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - Unable to download file from blob storage. Err: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v-v%v.mp4", projectID, version.Version))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+type PublishOutputVersion struct {
+	Logger             logger.Logger
+	OutputVersionStore outputversion.Store
+}
+
+func (h PublishOutputVersion) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start PublishOutputVersion API Handler")
+	defer h.Logger.Info("End PublishOutputVersion API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	outputVersionID := mux.Vars(r)["outputversion_id"]
+
+	version, err := h.OutputVersionStore.Get(ctx, projectID, outputVersionID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve output version. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	// Only a single version of the video is published at any time
+	versions, err := h.OutputVersionStore.GetAll(ctx, projectID, version.VideoOutputID, maxOutputVersions, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve output versions. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	for _, v := range versions {
+		if !v.Published || v.ID == version.ID {
+			continue
+		}
+		updaters, _ := outputversion.SetPublished(false)
+		_, err = h.OutputVersionStore.Update(ctx, projectID, v.ID, updaters...)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to unpublish previous version. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	updaters, _ := outputversion.SetPublished(true)
+	version, err = h.OutputVersionStore.Update(ctx, projectID, version.ID, updaters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to publish version. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(version)
+	w.Write(rawItem)
+}
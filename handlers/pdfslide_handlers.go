@@ -9,17 +9,22 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"path/filepath"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/imageimporter"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/scriptimporter"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 )
 
 type CreatePDFSlideImages struct {
 	Logger              logger.Logger
+	ProjectStore        project.Store
 	PDFSlideImagesStore pdfslideimages.Store
 	Blobstorage         blobstorage.BlobStorage
 	BucketFolderName    string
@@ -32,6 +37,7 @@ func (h CreatePDFSlideImages) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 	projectID := mux.Vars(r)["project_id"]
 	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
 	err := r.ParseMultipartForm(32 << 20)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to retrieve parse multipart form data. Error: %+v", err)
@@ -54,6 +60,33 @@ func (h CreatePDFSlideImages) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	io.Copy(bw, file)
 
 	slideImages := pdfslideimages.New(projectID)
+	slideImages.ExtractScripts = r.FormValue("extract_scripts") == "true"
+
+	// Script file is optional - it is used to fill up the scripts of the video segments
+	// that would be created once the pdf is split into images
+	var scriptContent []byte
+	scriptFile, scriptFileHeader, err := r.FormFile("scriptfile")
+	if err == nil {
+		defer scriptFile.Close()
+		if !scriptimporter.IsSupported(scriptFileHeader.Filename) {
+			errMsg := fmt.Sprintf("Error - %v", scriptimporter.ErrUnsupportedFormat)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		scriptContent, _ = ioutil.ReadAll(scriptFile)
+		_, err = scriptimporter.Parse(scriptFileHeader.Filename, scriptContent)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to parse script file. Error: %+v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		slideImages.ScriptFile = slideImages.ID + "-script" + strings.ToLower(filepath.Ext(scriptFileHeader.Filename))
+	}
+
 	err = h.PDFSlideImagesStore.Create(ctx, slideImages)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to save pdf slide images. Error: %+v", err)
@@ -64,8 +97,27 @@ func (h CreatePDFSlideImages) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	}
 
 	h.Blobstorage.Save(ctx, h.BucketFolderName+"/"+slideImages.PDFFile, b.Bytes())
+	if slideImages.ScriptFile != "" {
+		err = h.Blobstorage.Save(ctx, h.BucketFolderName+"/"+slideImages.ScriptFile, scriptContent)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to save script file. Error: %+v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	singleProject, err := h.ProjectStore.Get(ctx, projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %+v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
 
-	err = h.PDFSlideImporter.Start(ctx, slideImages)
+	err = h.PDFSlideImporter.Start(ctx, slideImages, userID, singleProject.Settings)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to send job. Error: %+v", err)
 		h.Logger.Error(errMsg)
@@ -83,6 +135,8 @@ type UpdatePDFSlideImages struct {
 	Logger              logger.Logger
 	PDFSlideImagesStore pdfslideimages.Store
 	VideoSegmentStore   videosegment.Store
+	Blobstorage         blobstorage.BlobStorage
+	BucketFolderName    string
 }
 
 func (h UpdatePDFSlideImages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -130,8 +184,23 @@ func (h UpdatePDFSlideImages) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	}
 
 	if item.IsComplete() {
+		scripts := map[int]string{}
+		if item.ScriptFile != "" {
+			content, err := h.Blobstorage.Load(context.Background(), h.BucketFolderName+"/"+item.ScriptFile)
+			if err == nil {
+				scripts, err = scriptimporter.Parse(item.ScriptFile, content)
+			}
+			if err != nil {
+				// Segments are still created; scripts can be filled in manually
+				h.Logger.Errorf("Unable to import script file %v. Error: %+v", item.ScriptFile, err)
+			}
+		}
 		for _, s := range item.SlideAssets {
 			videoSegment := videosegment.New(projectID, s.ImageID, s.Order)
+			videoSegment.Script = s.Script
+			if script := scriptimporter.ForOrder(scripts, s.Order); script != "" {
+				videoSegment.Script = script
+			}
 			err := h.VideoSegmentStore.Create(context.Background(), videoSegment)
 			if err != nil {
 				errMsg := fmt.Sprintf("Error - unable to update video segment. Error: %+v", err)
@@ -6,14 +6,21 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/job"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/team"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videoconcater"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videogenerator"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
@@ -23,8 +30,11 @@ type CreateProject struct {
 	Logger       logger.Logger
 	ProjectStore project.Store
 	ACLStore     acl.Store
+	TeamStore    team.Store
 }
 
+// ServeHTTP creates a project that is owned by the user. Owners of a team are able to create
+// projects that are owned by the team by providing the team_id
 func (h CreateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("Start Create Parent Item Handler")
 	defer h.Logger.Info("End Create Parent Item Handler")
@@ -32,7 +42,37 @@ func (h CreateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := ctx.Value(userIDKey).(string)
 
+	type createProjectReq struct {
+		TeamID string `json:"team_id"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := createProjectReq{}
+	if len(rawReq) > 0 {
+		err := json.Unmarshal(rawReq, &req)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
 	item := project.New()
+	ownerACL := acl.New(item.ID, userID)
+	if req.TeamID != "" {
+		owningTeam, err := h.TeamStore.Get(ctx, req.TeamID)
+		if err != nil || !owningTeam.IsOwner(userID) {
+			errMsg := fmt.Sprintf("Error - only owners of the team are able to create projects for the team. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		item.TeamID = owningTeam.ID
+		ownerACL = acl.NewTeam(item.ID, owningTeam.ID, acl.Owner)
+	}
+
 	err := h.ProjectStore.Create(context.Background(), item)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to create project in datastore. Error: %v", err)
@@ -42,8 +82,7 @@ func (h CreateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acl := acl.New(item.ID, userID)
-	err = h.ACLStore.Create(context.Background(), acl)
+	err = h.ACLStore.Create(context.Background(), ownerACL)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to create acl control in datastore. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -58,9 +97,12 @@ func (h CreateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 type UpdateProject struct {
-	Logger       logger.Logger
-	ProjectStore project.Store
-	ACLStore     acl.Store
+	Logger             logger.Logger
+	ProjectStore       project.Store
+	OutputVersionStore outputversion.Store
+	Blobstorage        blobstorage.BlobStorage
+	BucketFolderName   string
+	VersionsToKeep     int
 }
 
 func (h UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -68,7 +110,7 @@ func (h UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer h.Logger.Info("End Update Project API Handler")
 
 	ctx := r.Context()
-	userID := ctx.Value(userIDKey).(string)
+	isService := isServiceAccount(ctx)
 
 	projectID := mux.Vars(r)["project_id"]
 	rawReq, err := ioutil.ReadAll(r.Body)
@@ -80,21 +122,13 @@ func (h UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	obtainedACL, err := h.ACLStore.Get(ctx, projectID, userID)
-	if err != nil || !obtainedACL.IsAuthorized(acl.Editor) {
-		errMsg := fmt.Sprintf("Error - unable to confirm acl for project. Error: %v", err)
-		h.Logger.Error(errMsg)
-		w.WriteHeader(500)
-		w.Write([]byte(generateErrorResp(errMsg)))
-		return
-	}
-
 	type updateProjectReq struct {
-		Status             string `json:"status"`
-		VideoOutputID      string `json:"video_output_id"`
-		SetRunningIdemKey  string `json:"idem_key_running"`
-		CompleteRecIdemKey string `json:"idem_key_complete_rec"`
-		Name               string `json:"name"`
+		Status             string   `json:"status"`
+		VideoOutputID      string   `json:"video_output_id"`
+		VideoSegments      []string `json:"video_segments"`
+		SetRunningIdemKey  string   `json:"idem_key_running"`
+		CompleteRecIdemKey string   `json:"idem_key_complete_rec"`
+		Name               string   `json:"name"`
 	}
 
 	req := updateProjectReq{}
@@ -107,6 +141,14 @@ func (h UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isService && req.Name != "" {
+		errMsg := "Error - service accounts are only able to update the status of the project"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
 	updaters, err := project.GetUpdaters(req.Name, req.SetRunningIdemKey, req.CompleteRecIdemKey, req.Status, req.VideoOutputID)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to create the required updaters to update project. Error: %v", err)
@@ -116,7 +158,7 @@ func (h UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedProject, err := h.ProjectStore.Update(context.Background(), projectID, userID, updaters...)
+	updatedProject, err := h.ProjectStore.Update(context.Background(), projectID, updaters...)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to update project item. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -125,6 +167,17 @@ func (h UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Status == "completed" {
+		err = recordOutputVersion(ctx, h.Logger, h.OutputVersionStore, h.ProjectStore, h.Blobstorage, project.Folders{PDF: h.BucketFolderName, Images: imagesFolder}, projectID, "", req.VideoOutputID, req.VideoSegments, h.VersionsToKeep)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to record output version. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(500)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
 	rawUpdateProject, _ := json.Marshal(updatedProject)
 	w.WriteHeader(200)
 	w.Write(rawUpdateProject)
@@ -133,32 +186,26 @@ func (h UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 type GetProject struct {
 	Logger       logger.Logger
 	ProjectStore project.Store
-	ACLStore     acl.Store
 }
 
 func (h GetProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("Start Get Project API Handler")
 	defer h.Logger.Info("End Get Project API Handler")
 
-	ctx := r.Context()
-	userID := ctx.Value(userIDKey).(string)
-
 	projectID := mux.Vars(r)["project_id"]
 
-	obtainedACL, err := h.ACLStore.Get(ctx, projectID, userID)
-	if err != nil || !obtainedACL.IsAuthorized(acl.Reader) {
-		errMsg := fmt.Sprintf("Error - unable to confirm acl for project. Error: %v", err)
+	project, err := h.ProjectStore.Get(context.Background(), projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to view all parent jobs. Error: %v", err)
 		h.Logger.Error(errMsg)
 		w.WriteHeader(500)
 		w.Write([]byte(generateErrorResp(errMsg)))
 		return
 	}
-
-	project, err := h.ProjectStore.Get(context.Background(), projectID, userID)
-	if err != nil {
-		errMsg := fmt.Sprintf("Error - unable to view all parent jobs. Error: %v", err)
+	if project.IsTrashed() {
+		errMsg := "Error - project is in the trash and needs to be restored before it can be viewed"
 		h.Logger.Error(errMsg)
-		w.WriteHeader(500)
+		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(generateErrorResp(errMsg)))
 		return
 	}
@@ -172,8 +219,11 @@ func (h GetProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 type GetAllProjects struct {
 	Logger       logger.Logger
 	ProjectStore project.Store
+	TeamStore    team.Store
 }
 
+// ServeHTTP lists the projects that the user has access to, including the projects of the
+// teams that the user is a member of
 func (h GetAllProjects) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("Start View All Parent Jobs API Handler")
 	defer h.Logger.Info("End View All Parent Jobs API Handler")
@@ -181,26 +231,33 @@ func (h GetAllProjects) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := ctx.Value(userIDKey).(string)
 
-	rawOffset := r.URL.Query().Get("offset")
-	offset := 0
-	if rawOffset != "" {
-		offset, _ = strconv.Atoi(rawOffset)
+	opts, err := parseProjectListOptions(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid project search parameters. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
 	}
-
-	rawLimit := r.URL.Query().Get("limit")
-	limit := 10
-	if rawLimit != "" {
-		limit, _ = strconv.Atoi(rawLimit)
+	teams, err := h.TeamStore.GetByMember(ctx, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve teams of user. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(500)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
 	}
+	opts.TeamIDs = team.IDs(teams)
 
 	type getAllProjectsResp struct {
-		Projects []project.Project `json:"projects"`
-		Offset   int               `json:"offset"`
-		Total    int               `json:"total"`
-		Limit    int               `json:"limit"`
+		Projects   []project.Project `json:"projects"`
+		Offset     int               `json:"offset"`
+		Total      int               `json:"total"`
+		Limit      int               `json:"limit"`
+		NextCursor string            `json:"next_cursor,omitempty"`
 	}
 
-	projects, err := h.ProjectStore.GetAll(ctx, userID, limit, offset)
+	projects, nextCursor, err := h.ProjectStore.GetAll(ctx, userID, opts)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to view all parent jobs. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -209,11 +266,21 @@ func (h GetAllProjects) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	total, err := h.ProjectStore.Count(ctx, userID, opts.Filter)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to count projects. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(500)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
 	projectResp := getAllProjectsResp{
-		Projects: projects,
-		Offset:   offset,
-		Limit:    limit,
-		Total:    1000,
+		Projects:   projects,
+		Offset:     opts.Offset,
+		Limit:      opts.Limit,
+		Total:      total,
+		NextCursor: nextCursor,
 	}
 
 	rawProjectResp, err := json.Marshal(projectResp)
@@ -229,31 +296,149 @@ func (h GetAllProjects) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Write(rawProjectResp)
 }
 
-type StartVideoConcat struct {
-	Logger        logger.Logger
-	ProjectStore  project.Store
-	ACLStore      acl.Store
-	VideoConcater videoconcater.VideoConcater
+type DeleteProject struct {
+	Logger         logger.Logger
+	ProjectStore   project.Store
+	TrashRetention time.Duration
 }
 
-func (h StartVideoConcat) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.Logger.Info("Start StartVideoConcat API Handler")
-	defer h.Logger.Info("End StartVideoConcat API Handler")
+func (h DeleteProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start DeleteProject API Handler")
+	defer h.Logger.Info("End DeleteProject API Handler")
 
 	ctx := r.Context()
 	projectID := mux.Vars(r)["project_id"]
-	userID := ctx.Value(userIDKey).(string)
 
-	obtainedACL, err := h.ACLStore.Get(ctx, projectID, userID)
-	if err != nil || !obtainedACL.IsAuthorized(acl.Editor) {
-		errMsg := fmt.Sprintf("Error - unable to confirm acl for project. Error: %v", err)
+	setters, _ := project.MoveToTrash()
+	trashedProject, err := h.ProjectStore.Update(ctx, projectID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to move project to trash. Error: %v", err)
 		h.Logger.Error(errMsg)
-		w.WriteHeader(500)
+		w.WriteHeader(http.StatusConflict)
 		w.Write([]byte(generateErrorResp(errMsg)))
 		return
 	}
 
-	project, err := h.ProjectStore.Get(ctx, projectID, userID)
+	type deleteProjectResp struct {
+		project.Project
+		PurgeAt time.Time `json:"purge_at"`
+	}
+	rawResp, _ := json.Marshal(deleteProjectResp{
+		Project: trashedProject,
+		PurgeAt: trashedProject.PurgeAt(h.TrashRetention),
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type RestoreProject struct {
+	Logger         logger.Logger
+	ProjectStore   project.Store
+	TrashRetention time.Duration
+}
+
+func (h RestoreProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start RestoreProject API Handler")
+	defer h.Logger.Info("End RestoreProject API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	setters, _ := project.RestoreFromTrash(h.TrashRetention)
+	restoredProject, err := h.ProjectStore.Update(ctx, projectID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to restore project from trash. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(restoredProject)
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+// parseProjectListOptions reads the filters, sorting and pagination for listing projects
+// Dates are expected in RFC3339 format, e.g. 2020-01-02T15:04:05Z
+func parseProjectListOptions(r *http.Request) (project.ListOptions, error) {
+	query := r.URL.Query()
+	opts := project.DefaultListOptions()
+	var err error
+
+	if rawOffset := query.Get("offset"); rawOffset != "" {
+		opts.Offset, err = strconv.Atoi(rawOffset)
+		if err != nil {
+			return opts, fmt.Errorf("invalid offset")
+		}
+	}
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		opts.Limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return opts, fmt.Errorf("invalid limit")
+		}
+		if opts.Limit > project.MaxListLimit {
+			opts.Limit = project.MaxListLimit
+		}
+	}
+	if sortBy := query.Get("sort_by"); sortBy != "" {
+		opts.SortBy = sortBy
+	}
+	switch query.Get("order") {
+	case "":
+	case "asc":
+		opts.Descending = false
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, fmt.Errorf("invalid order. Only asc and desc are supported")
+	}
+	opts.Cursor = query.Get("cursor")
+	if opts.Cursor != "" {
+		opts.Offset = 0
+	}
+	opts.Trashed = query.Get("trashed") == "true"
+	opts.FolderID = query.Get("folder_id")
+	opts.Tag = query.Get("tag")
+	opts.Status = query.Get("status")
+	opts.Name = query.Get("name")
+
+	dates := map[string]*time.Time{
+		"created_after":   &opts.CreatedAfter,
+		"created_before":  &opts.CreatedBefore,
+		"modified_after":  &opts.ModifiedAfter,
+		"modified_before": &opts.ModifiedBefore,
+	}
+	for param, value := range dates {
+		raw := query.Get(param)
+		if raw == "" {
+			continue
+		}
+		*value, err = time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %v date. Expected RFC3339 format", param)
+		}
+	}
+
+	return opts, opts.Validate()
+}
+
+type StartVideoConcat struct {
+	Logger           logger.Logger
+	ProjectStore     project.Store
+	VideoOutputStore videooutput.Store
+	VideoConcater    videoconcater.VideoConcater
+}
+
+func (h StartVideoConcat) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start StartVideoConcat API Handler")
+	defer h.Logger.Info("End StartVideoConcat API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	userID := ctx.Value(userIDKey).(string)
+
+	project, err := h.ProjectStore.Get(ctx, projectID)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to retrieve the project entity. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -262,6 +447,54 @@ func (h StartVideoConcat) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Videos in the additional languages of the project are produced as video outputs of the
+	// project so that each language has its own output and versions
+	language := r.URL.Query().Get("language")
+	if !project.IsPrimaryLanguage(language) {
+		l, ok := project.Language(language)
+		if !ok {
+			errMsg := fmt.Sprintf("Error - language %v is not part of project", language)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		videoOutput, err := videooutput.GetLanguageOutput(ctx, h.VideoOutputStore, projectID, l.Code)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to retrieve video output for language. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		videoSegments := project.VisibleVideoSegments()
+		sort.Sort(videosegment.ByOrder(videoSegments))
+		videoSegmentList, err := videoOutput.VideoFiles(videoSegments)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to concatenate due to missing video file record. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		err = h.VideoConcater.StartOutput(ctx, videoOutput, userID, videoSegmentList)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to start async video generation. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		resp := map[string]string{
+			"status":          "successfully sent",
+			"video_output_id": videoOutput.ID,
+		}
+		rawResp, _ := json.Marshal(resp)
+		w.WriteHeader(http.StatusOK)
+		w.Write(rawResp)
+		return
+	}
+
 	videoSegmentIDs, err := project.GetVideoSegmentList()
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to retrieve the project entity. Error: %v", err)
@@ -293,7 +526,6 @@ type StartProjectGenerateVideo struct {
 	Logger             logger.Logger
 	JobStore           job.Store
 	ProjectStore       project.Store
-	ACLStore           acl.Store
 	VideoSegmentsStore videosegment.Store
 	VideoGenerator     videogenerator.VideoGenerator
 }
@@ -306,31 +538,78 @@ func (h StartProjectGenerateVideo) ServeHTTP(w http.ResponseWriter, r *http.Requ
 	projectID := mux.Vars(r)["project_id"]
 	userID := ctx.Value(userIDKey).(string)
 
-	obtainedACL, err := h.ACLStore.Get(ctx, projectID, userID)
-	if err != nil || !obtainedACL.IsAuthorized(acl.Editor) {
-		errMsg := fmt.Sprintf("Error - unable to confirm acl for project. Error: %v", err)
+	singleProject, err := h.ProjectStore.Get(context.TODO(), projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
 		h.Logger.Error(errMsg)
-		w.WriteHeader(500)
+		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(generateErrorResp(errMsg)))
 		return
 	}
 
-	singleProject, err := h.ProjectStore.Get(context.TODO(), projectID, userID)
+	// Generation modes
+	// all - regenerate all video segments (default)
+	// stale - only regenerate video segments whose inputs changed since their last render
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != "all" && mode != "stale" {
+		errMsg := fmt.Sprintf("Error - unsupported generation mode %v. Only all and stale are supported", mode)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	// Video segments are generated in the primary language of the project unless
+	// one of the additional languages of the project is requested
+	language := r.URL.Query().Get("language")
+	primary := singleProject.IsPrimaryLanguage(language)
+	languageSettings, err := singleProject.LanguageSettings(language)
 	if err != nil {
-		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+		errMsg := fmt.Sprintf("Error - unable to generate video in language. Error: %v", err)
 		h.Logger.Error(errMsg)
-		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	toGenerate := map[string]bool{}
+	for _, v := range singleProject.VisibleVideoSegments() {
+		if primary {
+			if mode != "stale" || v.IsStale(singleProject.Settings) {
+				toGenerate[v.ID] = true
+			}
+			continue
+		}
+		if _, ok := v.Translation(language); !ok {
+			errMsg := fmt.Sprintf("Error - video segment %v has no script for language %v", v.ID, language)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		if mode != "stale" || v.IsTranslationStale(language, languageSettings) {
+			toGenerate[v.ID] = true
+		}
+	}
+	if len(singleProject.VisibleVideoSegments()) == 0 {
+		errMsg := fmt.Sprintf("Error - no visible video segments available to generate video")
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(generateErrorResp(errMsg)))
 		return
 	}
 
 	var updateVideoSegmentErr error
 	for _, v := range singleProject.VideoSegments {
+		if !toGenerate[v.ID] {
+			continue
+		}
 		updateVideoSegmentErr = nil
 		updaters, _ := videosegment.ResetStatus()
+		if !primary {
+			updaters, _ = videosegment.ResetTranslationStatus(language)
+		}
 		_, updateVideoSegmentErr = h.VideoSegmentsStore.Update(context.TODO(), projectID, v.ID, updaters...)
 		if updateVideoSegmentErr != nil {
-			errMsg := fmt.Sprintf("Error - unable to update video segment. ProjectID: %v :: VideoSegmentID: %v :: Error: %v", err)
+			errMsg := fmt.Sprintf("Error - unable to update video segment. ProjectID: %v :: VideoSegmentID: %v :: Error: %v", projectID, v.ID, updateVideoSegmentErr)
 			h.Logger.Error(errMsg)
 		}
 	}
@@ -342,7 +621,7 @@ func (h StartProjectGenerateVideo) ServeHTTP(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	singleProject, err = h.ProjectStore.Get(context.TODO(), projectID, userID)
+	singleProject, err = h.ProjectStore.Get(context.TODO(), projectID)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -353,9 +632,16 @@ func (h StartProjectGenerateVideo) ServeHTTP(w http.ResponseWriter, r *http.Requ
 
 	var generateVideoErr error
 	for _, v := range singleProject.VideoSegments {
-		generateVideoErr = h.VideoGenerator.Start(context.TODO(), v)
+		if !toGenerate[v.ID] {
+			continue
+		}
+		if primary {
+			generateVideoErr = h.VideoGenerator.Start(context.TODO(), v, userID, singleProject.Settings)
+		} else {
+			generateVideoErr = h.VideoGenerator.StartTranslation(context.TODO(), v, userID, language, languageSettings)
+		}
 		if generateVideoErr != nil {
-			errMsg := fmt.Sprintf("Error - unable to generate video segment. ProjectID: %v :: VideoSegmentID: %v :: Error: %v", err)
+			errMsg := fmt.Sprintf("Error - unable to generate video segment. ProjectID: %v :: VideoSegmentID: %v :: Error: %v", projectID, v.ID, generateVideoErr)
 			h.Logger.Error(errMsg)
 		}
 	}
@@ -367,7 +653,11 @@ func (h StartProjectGenerateVideo) ServeHTTP(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	newJob := job.New(projectID, userID)
+	jobLanguage := ""
+	if !primary {
+		jobLanguage = languageSettings.Voice.LanguageCode
+	}
+	newJob := job.New(projectID, userID, jobLanguage)
 	err = h.JobStore.Create(context.TODO(), newJob)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to set job to generate video. Error: %v", err)
@@ -377,11 +667,173 @@ func (h StartProjectGenerateVideo) ServeHTTP(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	resp := map[string]string{
-		"status": "successfully set job",
+	resp := map[string]interface{}{
+		"status":              "successfully set job",
+		"generated_segments":  len(toGenerate),
+		"up_to_date_segments": len(singleProject.VisibleVideoSegments()) - len(toGenerate),
 	}
 	rawResp, _ := json.Marshal(resp)
 
 	w.WriteHeader(http.StatusOK)
 	w.Write(rawResp)
 }
+
+type CloneProject struct {
+	Logger              logger.Logger
+	ProjectStore        project.Store
+	PDFSlideImagesStore pdfslideimages.Store
+	VideoSegmentStore   videosegment.Store
+	ACLStore            acl.Store
+}
+
+func (h CloneProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start CloneProject API Handler")
+	defer h.Logger.Info("End CloneProject API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	userID := ctx.Value(userIDKey).(string)
+
+	rawReq, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to read json body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type cloneProjectReq struct {
+		Name        string `json:"name"`
+		IncludeACLs bool   `json:"include_acls"`
+	}
+	req := cloneProjectReq{}
+	if len(rawReq) > 0 {
+		err = json.Unmarshal(rawReq, &req)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to parse clone project request. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	obtainedACL := projectACL(ctx)
+
+	sourceProject, err := h.ProjectStore.Get(ctx, projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve the project entity. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	var sourceACLs []acl.ACL
+	if req.IncludeACLs {
+		if !obtainedACL.IsAuthorized(acl.Share) {
+			errMsg := "Error - role on the project does not allow copying over its acls"
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		sourceACLs, err = h.ACLStore.GetAll(ctx, projectID, 1000, 0)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to retrieve acls of project. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	// The cloned project refers to the files of the source project rather than copies of them
+	// The source project is marked as sharing its files so that they are kept until neither
+	// project refers to them
+	if sourceProject.BlobSourceID == "" {
+		setters, _ := project.ShareBlobs()
+		_, err = h.ProjectStore.Update(ctx, projectID, setters...)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to share files of project. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+	clonedProject := sourceProject.Reference(req.Name)
+
+	pdfSlideImages := clonedProject.PDFSlideImages
+	videoSegments := clonedProject.VideoSegments
+	clonedProject.PDFSlideImages = nil
+	clonedProject.VideoSegments = nil
+	err = h.ProjectStore.Create(ctx, clonedProject)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create project in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = h.ACLStore.Create(ctx, acl.New(clonedProject.ID, userID))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create acl control in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	for _, a := range sourceACLs {
+		// Access granted through folders is not copied as the cloned project is not placed in the folder
+		if a.UserID == userID || a.InheritedFrom != "" {
+			continue
+		}
+		err = h.ACLStore.Create(ctx, a.Clone(clonedProject.ID))
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to copy acl control in datastore. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	for _, p := range pdfSlideImages {
+		err = h.PDFSlideImagesStore.Create(ctx, p)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to copy pdf slide images. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	for _, v := range videoSegments {
+		err = h.VideoSegmentStore.Create(ctx, v)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to copy video segment. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	clonedProject, err = h.ProjectStore.Get(ctx, clonedProject.ID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve the cloned project entity. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	rawItem, _ := json.Marshal(clonedProject)
+	w.Write(rawItem)
+}
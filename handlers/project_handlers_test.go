@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type fakePDFSlideImagesStore struct {
+	pdfslideimages.Store
+	created *[]pdfslideimages.PDFSlideImages
+}
+
+func (f fakePDFSlideImagesStore) Create(ctx context.Context, p pdfslideimages.PDFSlideImages) error {
+	*f.created = append(*f.created, p)
+	return nil
+}
+
+type fakeVideoSegmentStore struct {
+	videosegment.Store
+	created *[]videosegment.VideoSegment
+}
+
+func (f fakeVideoSegmentStore) Create(ctx context.Context, v videosegment.VideoSegment) error {
+	*f.created = append(*f.created, v)
+	return nil
+}
+
+type memoryStorage map[string][]byte
+
+func (m memoryStorage) Save(ctx context.Context, fileName string, content []byte) error {
+	m[fileName] = content
+	return nil
+}
+
+func (m memoryStorage) Load(ctx context.Context, fileName string) ([]byte, error) {
+	content, ok := m[fileName]
+	if !ok {
+		return nil, fmt.Errorf("file %v not found", fileName)
+	}
+	return content, nil
+}
+
+func (m memoryStorage) Delete(ctx context.Context, fileName string) error {
+	delete(m, fileName)
+	return nil
+}
+
+func TestCloneProject(t *testing.T) {
+	source := project.New()
+	source.Name = "demo"
+	slideImages := pdfslideimages.New(source.ID)
+	slideImages.SlideAssets = []pdfslideimages.SlideAsset{{ImageID: slideImages.ID + "-0.png", Order: 0, PDFSlideImageID: slideImages.ID}}
+	segment := videosegment.New(source.ID, slideImages.ID+"-0.png", 0)
+	segment.Script = "hello"
+	segment.VideoFile = segment.ID + ".mp4"
+	source.PDFSlideImages = []pdfslideimages.PDFSlideImages{slideImages}
+	source.VideoSegments = []videosegment.VideoSegment{segment}
+
+	projects := fakeProjectStore{projects: map[string]project.Project{source.ID: source}}
+	acls := fakeACLStore{acls: map[string]acl.ACL{
+		source.ID + "/owner":  acl.New(source.ID, "owner"),
+		source.ID + "/reader": acl.NewInherited(source.ID, "reader", "", acl.Reader),
+	}}
+	storage := memoryStorage{
+		"pdf/" + slideImages.PDFFile:          []byte("pdf"),
+		"images/" + slideImages.ID + "-0.png": []byte("image"),
+		segment.VideoFile:                     []byte("video"),
+	}
+	createdSlideImages := []pdfslideimages.PDFSlideImages{}
+	createdSegments := []videosegment.VideoSegment{}
+	h := RequireProjectACL{
+		Logger:     logger.LoggerForTests{Tester: t},
+		ACLStore:   acls,
+		Capability: acl.View,
+		NextHandler: CloneProject{
+			Logger:              logger.LoggerForTests{Tester: t},
+			ProjectStore:        projects,
+			PDFSlideImagesStore: fakePDFSlideImagesStore{created: &createdSlideImages},
+			VideoSegmentStore:   fakeVideoSegmentStore{created: &createdSegments},
+			ACLStore:            acls,
+		},
+	}
+	clone := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/project/"+source.ID+":clone", strings.NewReader(`{"name": "copy"}`))
+		req = mux.SetURLVars(req, map[string]string{"project_id": source.ID})
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, userID))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := clone("stranger"); rec.Code == http.StatusCreated {
+		t.Fatalf("expected users without access to the project to be unable to clone it")
+	}
+
+	rec := clone("reader")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("unexpected status code. Expected: %v Actual: %v %v", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	cloned := project.Project{}
+	json.Unmarshal(rec.Body.Bytes(), &cloned)
+	if cloned.ID == "" || cloned.ID == source.ID || cloned.Name != "copy" {
+		t.Fatalf("expected a new project to be created. Project: %+v", cloned)
+	}
+	if a, err := acls.Get(context.Background(), cloned.ID, "reader"); err != nil || a.Permission != acl.Owner {
+		t.Errorf("expected user that cloned the project to own the clone. Acl: %+v Err: %v", a, err)
+	}
+
+	if len(createdSlideImages) != 1 || len(createdSegments) != 1 {
+		t.Fatalf("expected pdf slide images and video segments to be copied. Pdf slide images: %+v Video segments: %+v", createdSlideImages, createdSegments)
+	}
+	clonedSlideImages, clonedSegment := createdSlideImages[0], createdSegments[0]
+	if clonedSlideImages.ProjectID != cloned.ID || clonedSegment.ProjectID != cloned.ID || clonedSegment.Script != "hello" {
+		t.Errorf("expected copies to belong to the clone. Pdf slide images: %+v Video segment: %+v", clonedSlideImages, clonedSegment)
+	}
+	if clonedSegment.VideoFile != "" {
+		t.Errorf("expected video of video segment to not be copied. Video segment: %+v", clonedSegment)
+	}
+	if clonedSlideImages.PDFFile != slideImages.PDFFile || clonedSlideImages.SlideAssets[0].ImageID != slideImages.SlideAssets[0].ImageID || clonedSegment.ImageID != segment.ImageID {
+		t.Errorf("expected clone to refer to the files of the source project. Pdf slide images: %+v Video segment: %+v", clonedSlideImages, clonedSegment)
+	}
+	if projects.projects[source.ID].BlobSourceID != source.ID || projects.projects[cloned.ID].BlobSourceID != source.ID {
+		t.Errorf("expected source project and clone to be marked as sharing files")
+	}
+	if len(storage) != 3 {
+		t.Errorf("expected no files to be copied. Files: %v", len(storage))
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/scriptrevision"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+// recordScriptRevision saves the script revision that was created by updating the script of the video segment
+// The script that the video segment had before the first edit is kept as revision 0 so that it can be reverted to
+func recordScriptRevision(ctx context.Context, store scriptrevision.Store, before, after videosegment.VideoSegment, author string) error {
+	if before.ScriptRevision == after.ScriptRevision {
+		return nil
+	}
+	if before.ScriptRevision == 0 && before.Script != "" {
+		original := scriptrevision.New(before.ProjectID, before.ID, 0, "", before.Script, "")
+		original.DateCreated = before.DateCreated
+		err := store.Create(ctx, original)
+		if err != nil {
+			return err
+		}
+	}
+	revision := scriptrevision.New(after.ProjectID, after.ID, after.ScriptRevision, before.Script, after.Script, author)
+	return store.Create(ctx, revision)
+}
+
+type GetScriptRevisions struct {
+	Logger              logger.Logger
+	ScriptRevisionStore scriptrevision.Store
+}
+
+func (h GetScriptRevisions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetScriptRevisions API Handler")
+	defer h.Logger.Info("End GetScriptRevisions API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoSegmentID := mux.Vars(r)["videosegment_id"]
+
+	rawOffset := r.URL.Query().Get("offset")
+	offset := 0
+	if rawOffset != "" {
+		offset, _ = strconv.Atoi(rawOffset)
+	}
+	rawLimit := r.URL.Query().Get("limit")
+	limit := 10
+	if rawLimit != "" {
+		limit, _ = strconv.Atoi(rawLimit)
+	}
+
+	revisions, err := h.ScriptRevisionStore.GetAll(ctx, projectID, videoSegmentID, limit, offset)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve script revisions. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type getScriptRevisionsResp struct {
+		Revisions []scriptrevision.ScriptRevision `json:"revisions"`
+		Limit     int                             `json:"limit"`
+		Offset    int                             `json:"offset"`
+	}
+	rawResp, _ := json.Marshal(getScriptRevisionsResp{
+		Revisions: revisions,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type RevertScriptRevision struct {
+	Logger              logger.Logger
+	VideoSegmentStore   videosegment.Store
+	ScriptRevisionStore scriptrevision.Store
+}
+
+func (h RevertScriptRevision) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start RevertScriptRevision API Handler")
+	defer h.Logger.Info("End RevertScriptRevision API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	projectID := mux.Vars(r)["project_id"]
+	videoSegmentID := mux.Vars(r)["videosegment_id"]
+
+	revisionNo, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid revision number. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	revision, err := h.ScriptRevisionStore.Get(ctx, projectID, videoSegmentID, revisionNo)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve script revision. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	existing, err := h.VideoSegmentStore.Get(ctx, projectID, videoSegmentID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to get video segment in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	updaters, _ := videosegment.RevertScript(revision.Script)
+	item, err := h.VideoSegmentStore.Update(ctx, projectID, videoSegmentID, updaters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update video segment in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = recordScriptRevision(ctx, h.ScriptRevisionStore, existing, item, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to save script revision. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
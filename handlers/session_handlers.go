@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
+)
+
+// sessionTokens are handed to the user when a session is started or refreshed
+type sessionTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// startSession creates a session for the user that has just signed in along with the tokens
+// of the session
+func startSession(ctx context.Context, auth services.Auth, userID string) (session.Session, sessionTokens, error) {
+	s, refreshToken, err := session.New(userID, time.Duration(auth.SessionExpiryTime)*time.Second)
+	if err != nil {
+		return session.Session{}, sessionTokens{}, err
+	}
+	err = auth.Sessions.Create(ctx, s)
+	if err != nil {
+		return session.Session{}, sessionTokens{}, err
+	}
+	token, err := services.NewSessionToken(userID, s.ID, auth.ExpiryTime, auth.Keys(), auth.Issuer)
+	if err != nil {
+		return session.Session{}, sessionTokens{}, err
+	}
+	return s, sessionTokens{Token: token, RefreshToken: refreshToken, ExpiresIn: auth.ExpiryTime}, nil
+}
+
+// setSessionCookie signs the user in on the browser for as long as the session lasts
+func setSessionCookie(w http.ResponseWriter, auth services.Auth, s session.Session) error {
+	value := map[string]string{
+		"user_id":    s.UserID,
+		"session_id": s.ID,
+	}
+	encoded, err := securecookie.EncodeMulti(auth.CookieName, value, auth.CookieCodecs()...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CookieName,
+		Value:    encoded,
+		Path:     "/",
+		Expires:  s.ExpiresAt,
+		Secure:   false,
+		HttpOnly: true,
+		SameSite: http.SameSiteDefaultMode,
+	})
+	return nil
+}
+
+type RefreshToken struct {
+	Logger logger.Logger
+	Auth   services.Auth
+}
+
+// ServeHTTP issues a new token for the session of the refresh token. The refresh token is
+// replaced so that it can only be used once
+func (h RefreshToken) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start RefreshToken Handler")
+	defer h.Logger.Info("End RefreshToken Handler")
+
+	ctx := r.Context()
+
+	type refreshTokenReq struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := refreshTokenReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil || req.RefreshToken == "" {
+		errMsg := fmt.Sprintf("Error - refresh token is required. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, refreshToken, err := session.Refresh(req.RefreshToken)
+	if errors.Is(err, session.ErrRefreshTokenInvalid) {
+		errMsg := fmt.Sprintf("Error - refresh token is not valid. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to refresh session. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	sessionID, _, _ := session.ParseRefreshToken(req.RefreshToken)
+	s, err := h.Auth.Sessions.Update(ctx, sessionID, setters...)
+	if errors.Is(err, session.ErrRefreshTokenReused) {
+		// The refresh token was stolen if it was used again so neither copy can be trusted
+		revokers, _ := session.Revoke()
+		_, revokeErr := h.Auth.Sessions.Update(ctx, sessionID, revokers...)
+		errMsg := fmt.Sprintf("Error - refresh token has already been used. Session is revoked. Error: %v", revokeErr)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to refresh session. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	token, err := services.NewSessionToken(s.UserID, s.ID, h.Auth.ExpiryTime, h.Auth.Keys(), h.Auth.Issuer)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create token. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(sessionTokens{Token: token, RefreshToken: refreshToken, ExpiresIn: h.Auth.ExpiryTime})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type Logout struct {
+	Logger logger.Logger
+	Auth   services.Auth
+}
+
+// ServeHTTP ends the session that the request was made with. The cookie, tokens and refresh
+// token of the session stop working
+func (h Logout) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start Logout Handler")
+	defer h.Logger.Info("End Logout Handler")
+
+	ctx := r.Context()
+	sessionID, _ := ctx.Value(sessionIDKey).(string)
+	if sessionID == "" {
+		errMsg := "Error - request was not made with a session"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, _ := session.Revoke()
+	_, err := h.Auth.Sessions.Update(ctx, sessionID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to end session. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.Auth.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(""))
+}
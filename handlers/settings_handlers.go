@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type UpdateProjectSettings struct {
+	Logger       logger.Logger
+	ProjectStore project.Store
+}
+
+// ServeHTTP replaces the voice and render settings of the project
+// Video segments generated with earlier settings are marked as stale
+func (h UpdateProjectSettings) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateProjectSettings API Handler")
+	defer h.Logger.Info("End UpdateProjectSettings API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := settings.Settings{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, err := project.SetSettings(req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid settings. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	item, err := h.ProjectStore.Update(ctx, projectID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update settings of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type updateProjectSettingsResp struct {
+		Settings  settings.Settings `json:"settings"`
+		Effective settings.Settings `json:"effective"`
+	}
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(updateProjectSettingsResp{
+		Settings:  item.Settings,
+		Effective: settings.Effective(item.Settings, settings.Voice{}),
+	})
+	w.Write(rawItem)
+}
+
+type UpdateVideoSegmentVoice struct {
+	Logger            logger.Logger
+	ProjectStore      project.Store
+	VideoSegmentStore videosegment.Store
+}
+
+// ServeHTTP replaces the voice override of the video segment
+// An empty body clears the override so that the voice settings of the project are used
+func (h UpdateVideoSegmentVoice) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateVideoSegmentVoice API Handler")
+	defer h.Logger.Info("End UpdateVideoSegmentVoice API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoSegmentID := mux.Vars(r)["videosegment_id"]
+
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := settings.Voice{}
+	if len(rawReq) > 0 {
+		err := json.Unmarshal(rawReq, &req)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	setters, err := videosegment.SetVoiceOverride(req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid voice settings. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	item, err := h.VideoSegmentStore.Update(ctx, projectID, videoSegmentID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update voice of video segment. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	singleProject, err := h.ProjectStore.Get(ctx, projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type updateVideoSegmentVoiceResp struct {
+		videosegment.VideoSegment
+		Effective settings.Settings `json:"effective_settings"`
+	}
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(updateVideoSegmentVoiceResp{
+		VideoSegment: item,
+		Effective:    item.Settings(singleProject.Settings),
+	})
+	w.Write(rawItem)
+}
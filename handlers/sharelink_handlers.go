@@ -0,0 +1,487 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/securecookie"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/sharelink"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/subtitles"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+)
+
+// maxShareLinks is the maximum number of share links listed for a project
+const maxShareLinks = 100
+
+// shareViewerMaxAge is the longest that a viewer stays signed in to a password protected
+// share link before the password needs to be entered again
+const shareViewerMaxAge = 24 * time.Hour
+
+type CreateShareLink struct {
+	Logger           logger.Logger
+	VideoOutputStore videooutput.Store
+	ShareLinkStore   sharelink.Store
+}
+
+// ServeHTTP creates a public link to the main video of the project or to a video output
+// The token of the link is what is handed out to viewers
+func (h CreateShareLink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start CreateShareLink API Handler")
+	defer h.Logger.Info("End CreateShareLink API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	projectID := mux.Vars(r)["project_id"]
+
+	type createShareLinkReq struct {
+		VideoOutputID string     `json:"video_output_id"`
+		Password      string     `json:"password"`
+		ExpiresAt     *time.Time `json:"expires_at"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := createShareLinkReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	if req.VideoOutputID != "" {
+		_, err = h.VideoOutputStore.Get(ctx, projectID, req.VideoOutputID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to retrieve video output. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	link, err := sharelink.New(projectID, req.VideoOutputID, userID, req.Password, req.ExpiresAt)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create share link. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	err = h.ShareLinkStore.Create(ctx, link)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to save share link. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(link)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(rawResp)
+}
+
+type GetShareLinks struct {
+	Logger         logger.Logger
+	ShareLinkStore sharelink.Store
+}
+
+// ServeHTTP lists the share links of the project along with their views
+func (h GetShareLinks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetShareLinks API Handler")
+	defer h.Logger.Info("End GetShareLinks API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	links, err := h.ShareLinkStore.GetAll(ctx, projectID, maxShareLinks, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve share links of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type getShareLinksResp struct {
+		ShareLinks []sharelink.ShareLink `json:"share_links"`
+	}
+	rawResp, _ := json.Marshal(getShareLinksResp{ShareLinks: links})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type RevokeShareLink struct {
+	Logger         logger.Logger
+	ShareLinkStore sharelink.Store
+}
+
+// ServeHTTP disables the share link. Viewers that have opened the link lose access immediately
+func (h RevokeShareLink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start RevokeShareLink API Handler")
+	defer h.Logger.Info("End RevokeShareLink API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	shareLinkID := mux.Vars(r)["sharelink_id"]
+
+	_, err := h.ShareLinkStore.Get(ctx, projectID, shareLinkID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve share link. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	setters, _ := sharelink.Revoke()
+	link, err := h.ShareLinkStore.Update(ctx, projectID, shareLinkID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to revoke share link. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(link)
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type OpenShareLink struct {
+	Logger           logger.Logger
+	Auth             services.Auth
+	ProjectStore     project.Store
+	VideoOutputStore videooutput.Store
+	ShareLinkStore   sharelink.Store
+}
+
+// ServeHTTP opens a share link for a viewer without an account and counts the view
+// Password protected links are opened with a POST request with the password. The viewer is
+// then remembered with a cookie so that the video and subtitles of the link can be loaded
+func (h OpenShareLink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start OpenShareLink API Handler")
+	defer h.Logger.Info("End OpenShareLink API Handler")
+
+	ctx := r.Context()
+	token := mux.Vars(r)["token"]
+
+	link, err := h.ShareLinkStore.GetByToken(ctx, token)
+	if err == nil {
+		err = link.Active(time.Now())
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - share link is not available. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type openShareLinkReq struct {
+		Password string `json:"password"`
+	}
+	req := openShareLinkReq{}
+	if r.Method == http.MethodPost {
+		rawReq, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(rawReq, &req)
+	}
+	if !isShareViewer(r, h.Auth, link) {
+		err = link.CheckPassword(req.Password)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to open share link. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	singleProject, err := h.ProjectStore.Get(ctx, link.ProjectID)
+	if err != nil || singleProject.IsTrashed() {
+		errMsg := fmt.Sprintf("Error - project of share link is not available. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	title := singleProject.Name
+	if link.VideoOutputID != "" {
+		videoOutput, err := h.VideoOutputStore.Get(ctx, link.ProjectID, link.VideoOutputID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - video of share link is not available. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		title = singleProject.Name + " - " + videoOutput.Name
+	}
+
+	setters, _ := sharelink.RecordView()
+	link, err = h.ShareLinkStore.Update(ctx, link.ProjectID, link.ID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to open share link. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	err = setShareViewerCookie(w, h.Auth, link)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to open share link. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type openShareLinkResp struct {
+		Title     string            `json:"title"`
+		ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+		Video     string            `json:"video"`
+		Download  string            `json:"download"`
+		Subtitles map[string]string `json:"subtitles"`
+	}
+	resp := openShareLinkResp{
+		Title:     title,
+		ExpiresAt: link.ExpiresAt,
+		Video:     "/api/v1/share/" + token + "/video",
+		Download:  "/api/v1/share/" + token + "/video?download=true",
+		Subtitles: map[string]string{},
+	}
+	for format := range subtitles.Formats {
+		resp.Subtitles[format] = "/api/v1/share/" + token + "/subtitles/" + format
+	}
+	rawResp, _ := json.Marshal(resp)
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+// shareViewerCookieName is unique for each share link so that viewers can open multiple links
+func shareViewerCookieName(auth services.Auth, link sharelink.ShareLink) string {
+	return auth.CookieName + "-share-" + link.ID
+}
+
+func shareViewerCodecs(auth services.Auth) []securecookie.Codec {
+	codecs := auth.CookieCodecs()
+	for _, c := range codecs {
+		c.(*securecookie.SecureCookie).MaxAge(int(shareViewerMaxAge.Seconds()))
+	}
+	return codecs
+}
+
+// setShareViewerCookie remembers that the viewer has opened the share link. The cookie does
+// not outlive the share link
+func setShareViewerCookie(w http.ResponseWriter, auth services.Auth, link sharelink.ShareLink) error {
+	name := shareViewerCookieName(auth, link)
+	encoded, err := securecookie.EncodeMulti(name, map[string]string{"share_link_id": link.ID}, shareViewerCodecs(auth)...)
+	if err != nil {
+		return err
+	}
+	expiry := time.Now().Add(shareViewerMaxAge)
+	if link.ExpiresAt != nil && link.ExpiresAt.Before(expiry) {
+		expiry = *link.ExpiresAt
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    encoded,
+		Path:     "/",
+		Expires:  expiry,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// isShareViewer checks if the viewer has already opened the share link
+func isShareViewer(r *http.Request, auth services.Auth, link sharelink.ShareLink) bool {
+	name := shareViewerCookieName(auth, link)
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return false
+	}
+	value := make(map[string]string)
+	err = securecookie.DecodeMulti(name, cookie.Value, &value, shareViewerCodecs(auth)...)
+	return err == nil && value["share_link_id"] == link.ID
+}
+
+// RequireShareLink ensures that the share link in the {token} route variable is active and
+// has been opened by the viewer if it is password protected. Viewers are given anonymous
+// access to the project of the share link
+type RequireShareLink struct {
+	Logger         logger.Logger
+	Auth           services.Auth
+	ShareLinkStore sharelink.Store
+	NextHandler    http.Handler
+}
+
+func (a RequireShareLink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token := mux.Vars(r)["token"]
+
+	link, err := a.ShareLinkStore.GetByToken(ctx, token)
+	if err == nil {
+		err = link.Active(time.Now())
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - share link is not available. Error: %v", err)
+		a.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if link.PasswordProtected && !isShareViewer(r, a.Auth, link) {
+		errMsg := fmt.Sprintf("Error - open the share link with its password first. Error: %v", sharelink.ErrPasswordRequired)
+		a.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if !acl.NewAnonymous(link.ProjectID).IsAuthorized(acl.View) {
+		errMsg := fmt.Sprintf("Error - anonymous viewers are not allowed to view the project")
+		a.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	ctx = context.WithValue(ctx, shareLinkKey, link)
+	a.NextHandler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+type DownloadSharedVideo struct {
+	Logger             logger.Logger
+	ProjectStore       project.Store
+	VideoOutputStore   videooutput.Store
+	OutputVersionStore outputversion.Store
+	StorageClient      blobstorage.BlobStorage
+}
+
+// ServeHTTP streams the video of the share link to the player of the viewer. The video is
+// downloaded as a file instead if the download query parameter is set to true
+func (h DownloadSharedVideo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start DownloadSharedVideo API Handler")
+	defer h.Logger.Info("End DownloadSharedVideo API Handler")
+
+	ctx := r.Context()
+	link := ctx.Value(shareLinkKey).(sharelink.ShareLink)
+
+	videoFile, statusCode, err := sharedVideoFile(ctx, h.ProjectStore, h.VideoOutputStore, h.OutputVersionStore, link)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - video of share link is not available. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(statusCode)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	content, err := h.StorageClient.Load(ctx, videoFile)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - Unable to download file from blob storage. Err: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	if r.URL.Query().Get("download") == "true" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v.mp4", link.ProjectID))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+type DownloadSharedSubtitles struct {
+	Logger             logger.Logger
+	ProjectStore       project.Store
+	VideoOutputStore   videooutput.Store
+	OutputVersionStore outputversion.Store
+	StorageClient      blobstorage.BlobStorage
+}
+
+// ServeHTTP downloads the subtitles of the video of the share link
+func (h DownloadSharedSubtitles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start DownloadSharedSubtitles API Handler")
+	defer h.Logger.Info("End DownloadSharedSubtitles API Handler")
+
+	ctx := r.Context()
+	link := ctx.Value(shareLinkKey).(sharelink.ShareLink)
+	format := mux.Vars(r)["format"]
+
+	contentType, ok := subtitles.Formats[format]
+	if !ok {
+		errMsg := fmt.Sprintf("Error - unsupported subtitle format %v. Only vtt and srt are supported", format)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	videoFile, statusCode, err := sharedVideoFile(ctx, h.ProjectStore, h.VideoOutputStore, h.OutputVersionStore, link)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - video of share link is not available. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(statusCode)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	content, err := h.StorageClient.Load(ctx, subtitles.FileName(videoFile, format))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - subtitles are not available for the video. Err: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+// sharedVideoFile resolves the video of the share link. The published version of the video
+// is shared if there is one, otherwise the latest video is shared
+func sharedVideoFile(ctx context.Context, projectStore project.Store, videoOutputStore videooutput.Store, outputVersionStore outputversion.Store, link sharelink.ShareLink) (string, int, error) {
+	singleProject, err := projectStore.Get(ctx, link.ProjectID)
+	if err != nil || singleProject.IsTrashed() {
+		return "", http.StatusNotFound, fmt.Errorf("project is not available. %v", err)
+	}
+
+	versions, err := outputVersionStore.GetAll(ctx, link.ProjectID, link.VideoOutputID, maxOutputVersions, 0)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	for _, v := range versions {
+		if v.Published {
+			return v.BlobID, http.StatusOK, nil
+		}
+	}
+
+	videoFile := singleProject.VideoOutputID
+	if link.VideoOutputID != "" {
+		videoOutput, err := videoOutputStore.Get(ctx, link.ProjectID, link.VideoOutputID)
+		if err != nil {
+			return "", http.StatusNotFound, err
+		}
+		videoFile = videoOutput.OutputID
+	}
+	if videoFile == "" {
+		return "", http.StatusNotFound, fmt.Errorf("video has not been generated yet")
+	}
+	return videoFile, http.StatusOK, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/subtitles"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+)
+
+type DownloadSubtitles struct {
+	Logger             logger.Logger
+	ProjectStore       project.Store
+	VideoOutputStore   videooutput.Store
+	OutputVersionStore outputversion.Store
+	StorageClient      blobstorage.BlobStorage
+}
+
+// ServeHTTP downloads the subtitles of the latest video of the project. The subtitles of the
+// latest video of a video output or of a specific output version are downloaded instead if
+// the video output or output version is provided in the route
+func (h DownloadSubtitles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start DownloadSubtitles API Handler")
+	defer h.Logger.Info("End DownloadSubtitles API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoOutputID := mux.Vars(r)["videooutput_id"]
+	outputVersionID := mux.Vars(r)["outputversion_id"]
+	format := mux.Vars(r)["format"]
+
+	contentType, ok := subtitles.Formats[format]
+	if !ok {
+		errMsg := fmt.Sprintf("Error - unsupported subtitle format %v. Only vtt and srt are supported", format)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	var videoFile string
+	switch {
+	case outputVersionID != "":
+		version, err := h.OutputVersionStore.Get(ctx, projectID, outputVersionID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to retrieve output version. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		videoFile = version.BlobID
+	case videoOutputID != "":
+		videoOutput, err := h.VideoOutputStore.Get(ctx, projectID, videoOutputID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to retrieve video output. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		videoFile = videoOutput.OutputID
+	default:
+		singleProject, err := h.ProjectStore.Get(ctx, projectID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		videoFile = singleProject.VideoOutputID
+	}
+	if videoFile == "" {
+		errMsg := fmt.Sprintf("Error - video has not been generated yet")
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	content, err := h.StorageClient.Load(ctx, subtitles.FileName(videoFile, format))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - subtitles are not available for the video. Err: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v", subtitles.FileName(projectID+".mp4", format)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
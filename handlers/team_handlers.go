@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/team"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
+)
+
+type CreateTeam struct {
+	Logger    logger.Logger
+	TeamStore team.Store
+}
+
+func (h CreateTeam) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start CreateTeam API Handler")
+	defer h.Logger.Info("End CreateTeam API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+
+	type createTeamReq struct {
+		Name string `json:"name"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := createTeamReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	item, err := team.New(req.Name, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	err = h.TeamStore.Create(ctx, item)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create team in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type GetTeams struct {
+	Logger    logger.Logger
+	TeamStore team.Store
+}
+
+// ServeHTTP lists the teams that the user is a member of
+func (h GetTeams) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetTeams API Handler")
+	defer h.Logger.Info("End GetTeams API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+
+	teams, err := h.TeamStore.GetByMember(ctx, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve teams. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type getTeamsResp struct {
+		Teams []team.Team `json:"teams"`
+	}
+	rawResp, _ := json.Marshal(getTeamsResp{Teams: teams})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type GetTeam struct {
+	Logger    logger.Logger
+	TeamStore team.Store
+}
+
+func (h GetTeam) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetTeam API Handler")
+	defer h.Logger.Info("End GetTeam API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	teamID := mux.Vars(r)["team_id"]
+
+	item, err := h.TeamStore.Get(ctx, teamID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if _, isMember := item.Member(userID); !isMember {
+		errMsg := "Error - only members of the team are able to view it"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type UpdateTeam struct {
+	Logger    logger.Logger
+	TeamStore team.Store
+}
+
+// ServeHTTP renames the team
+func (h UpdateTeam) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateTeam API Handler")
+	defer h.Logger.Info("End UpdateTeam API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	teamID := mux.Vars(r)["team_id"]
+
+	existing, err := h.TeamStore.Get(ctx, teamID)
+	if err != nil || !existing.IsOwner(userID) {
+		errMsg := fmt.Sprintf("Error - only owners of the team are able to change it. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type updateTeamReq struct {
+		Name string `json:"name"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := updateTeamReq{}
+	json.Unmarshal(rawReq, &req)
+	setters, err := team.SetName(req.Name)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid team name. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	item, err := h.TeamStore.Update(ctx, teamID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type SetTeamMember struct {
+	Logger    logger.Logger
+	TeamStore team.Store
+	UserStore user.Store
+}
+
+// ServeHTTP adds a user to the team or changes the role of a member of the team
+func (h SetTeamMember) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start SetTeamMember API Handler")
+	defer h.Logger.Info("End SetTeamMember API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	teamID := mux.Vars(r)["team_id"]
+
+	existing, err := h.TeamStore.Get(ctx, teamID)
+	if err != nil || !existing.IsOwner(userID) {
+		errMsg := fmt.Sprintf("Error - only owners of the team are able to manage its members. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type setTeamMemberReq struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := setTeamMemberReq{}
+	err = json.Unmarshal(rawReq, &req)
+	if err != nil || req.Email == "" {
+		errMsg := fmt.Sprintf("Error - email of user is required to add member to team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	member, err := h.UserStore.GetUserByEmail(ctx, req.Email)
+	if err != nil || member.ID == "" {
+		errMsg := fmt.Sprintf("Error - unable to find user to add to team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, err := team.SetMember(member.ID, req.Role)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid member. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	item, err := h.TeamStore.Update(ctx, teamID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to add member to team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type RemoveTeamMember struct {
+	Logger    logger.Logger
+	TeamStore team.Store
+}
+
+// ServeHTTP removes a member from the team. Members are able to leave the team on their own
+func (h RemoveTeamMember) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start RemoveTeamMember API Handler")
+	defer h.Logger.Info("End RemoveTeamMember API Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+	teamID := mux.Vars(r)["team_id"]
+	memberID := mux.Vars(r)["user_id"]
+
+	existing, err := h.TeamStore.Get(ctx, teamID)
+	if err != nil || (!existing.IsOwner(userID) && userID != memberID) {
+		errMsg := fmt.Sprintf("Error - only owners of the team are able to manage its members. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	setters, _ := team.RemoveMember(memberID)
+	item, err := h.TeamStore.Update(ctx, teamID, setters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to remove member from team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+// projectTeam is the acl of a team on a project along with the name of the team
+type projectTeam struct {
+	acl.ACL
+	Name string `json:"name"`
+}
+
+type GetProjectTeams struct {
+	Logger    logger.Logger
+	ACLStore  acl.Store
+	TeamStore team.Store
+}
+
+// ServeHTTP lists the teams that have access to the project
+func (h GetProjectTeams) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetProjectTeams API Handler")
+	defer h.Logger.Info("End GetProjectTeams API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	acls, err := h.ACLStore.GetAll(ctx, projectID, maxProjectACLs, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve teams of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	teams := []projectTeam{}
+	for _, a := range acls {
+		if !a.IsTeam() {
+			continue
+		}
+		// Teams that can no longer be found are still listed so that their access can be revoked
+		t, _ := h.TeamStore.Get(ctx, a.TeamID)
+		teams = append(teams, projectTeam{ACL: a, Name: t.Name})
+	}
+
+	type getProjectTeamsResp struct {
+		Teams []projectTeam `json:"teams"`
+	}
+	rawResp, _ := json.Marshal(getProjectTeamsResp{Teams: teams})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type ShareProjectWithTeam struct {
+	Logger    logger.Logger
+	ACLStore  acl.Store
+	TeamStore team.Store
+}
+
+// ServeHTTP grants a team access to the project with the role provided or changes the role of the
+// team. Members of the team inherit the role. The role of the last owner of the project cannot be changed
+func (h ShareProjectWithTeam) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start ShareProjectWithTeam API Handler")
+	defer h.Logger.Info("End ShareProjectWithTeam API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	teamID := mux.Vars(r)["team_id"]
+
+	obtainedACL := projectACL(ctx)
+
+	type shareProjectWithTeamReq struct {
+		Permission string `json:"permission"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := shareProjectWithTeamReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse request body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	setters, err := acl.SetPermission(req.Permission)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid role. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	// Teams can only be granted roles that do not allow more than the role of the user
+	newPermission, _ := acl.ParsePermission(req.Permission)
+	if !obtainedACL.Permission.Includes(newPermission) {
+		errMsg := fmt.Sprintf("Error - role on the project does not allow granting role %v", newPermission)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	sharedTeam, err := h.TeamStore.Get(ctx, teamID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to find team to share project with. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	acls, err := h.ACLStore.GetAll(ctx, projectID, maxProjectACLs, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve collaborators of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	for _, a := range acls {
+		if a.IsTeam() && a.TeamID == teamID && !obtainedACL.Permission.Includes(a.Permission) {
+			errMsg := fmt.Sprintf("Error - role on the project does not allow changing teams with role %v", a.Permission)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+	err = acl.CheckTeamOwnerRemains(acls, teamID, newPermission)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to change role of team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	// The acl of the team is recreated as acls are updated by the user that they are held by
+	err = h.ACLStore.DeleteTeam(ctx, projectID, teamID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to share project with team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	item := acl.NewTeam(projectID, teamID, acl.Reader)
+	for _, s := range setters {
+		s(&item)
+	}
+	err = h.ACLStore.Create(ctx, item)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to share project with team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	rawResp, _ := json.Marshal(projectTeam{ACL: item, Name: sharedTeam.Name})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type UnshareProjectWithTeam struct {
+	Logger   logger.Logger
+	ACLStore acl.Store
+}
+
+// ServeHTTP revokes the access of a team to the project. Members of the team keep the access that
+// is granted to them directly
+func (h UnshareProjectWithTeam) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UnshareProjectWithTeam API Handler")
+	defer h.Logger.Info("End UnshareProjectWithTeam API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	teamID := mux.Vars(r)["team_id"]
+
+	obtainedACL := projectACL(ctx)
+
+	teamACLs, err := h.ACLStore.GetForTeams(ctx, projectID, []string{teamID})
+	if err != nil || len(teamACLs) == 0 {
+		errMsg := fmt.Sprintf("Error - team does not have access to the project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	acls, err := h.ACLStore.GetAll(ctx, projectID, maxProjectACLs, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve collaborators of project. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	for _, a := range teamACLs {
+		if !obtainedACL.Permission.Includes(a.Permission) {
+			errMsg := fmt.Sprintf("Error - role on the project does not allow removing teams with role %v", a.Permission)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+	err = acl.CheckTeamOwnerRemains(acls, teamID, "")
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to remove team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = h.ACLStore.DeleteTeam(ctx, projectID, teamID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to remove team. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -13,9 +13,9 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
-	"github.com/gorilla/securecookie"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/user"
 )
 
@@ -179,7 +179,7 @@ func (h Authenticate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.UserStore.Create(context.Background(), newUser)
 	}
 
-	token, err := services.NewToken(retrievedUser.ID, h.Auth.ExpiryTime, h.Auth.Secret, h.Auth.Issuer)
+	_, tokens, err := startSession(r.Context(), h.Auth, retrievedUser.ID)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to create token. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -188,14 +188,10 @@ func (h Authenticate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	type tokenResponse struct {
-		Token string `json:"token"`
-	}
-
-	rawRespTokenResp, _ := json.Marshal(tokenResponse{Token: token})
+	rawRespTokenResp, _ := json.Marshal(tokens)
 
 	if redirectURL != "" {
-		http.Redirect(w, r, redirectURL+fmt.Sprintf("?token=%v", token), http.StatusTemporaryRedirect)
+		http.Redirect(w, r, redirectURL+fmt.Sprintf("?token=%v", tokens.Token), http.StatusTemporaryRedirect)
 		return
 	}
 
@@ -257,26 +253,17 @@ func (h Login) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// token, err := services.NewToken(u.ID, h.Auth.ExpiryTime, h.Auth.Secret, h.Auth.Issuer)
-	// if err != nil {
-	// 	errMsg := fmt.Sprintf("Error - unable to create token. Error: %v", err)
-	// 	h.Logger.Error(errMsg)
-	// 	w.WriteHeader(500)
-	// 	w.Write([]byte(generateErrorResp(errMsg)))
-	// 	return
-	// }
-
-	// type tokenResponse struct {
-	// 	Token string `json:"token"`
-	// }
-
-	// rawRespTokenResp, _ := json.Marshal(tokenResponse{Token: token})
-
-	value := map[string]string{
-		"user_id": u.ID,
+	// The session is signed in with the cookie on browsers. Scripts use the token along with
+	// the refresh token to obtain new tokens as they expire
+	s, tokens, err := startSession(r.Context(), h.Auth, u.ID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to start session. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
 	}
-	s := securecookie.New(h.Auth.HashKey, h.Auth.BlockKey)
-	encoded, err := s.Encode(h.Auth.CookieName, value)
+	err = setSessionCookie(w, h.Auth, s)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to set authorization token. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -284,15 +271,6 @@ func (h Login) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(generateErrorResp(errMsg)))
 		return
 	}
-	cookie := &http.Cookie{
-		Name:     h.Auth.CookieName,
-		Value:    encoded,
-		Path:     "/",
-		Secure:   false,
-		HttpOnly: true,
-		SameSite: http.SameSiteDefaultMode,
-	}
-	http.SetCookie(w, cookie)
 
 	// TODO: Handle case of logging in from another page
 	if h.RedirectURI != "" {
@@ -300,8 +278,9 @@ func (h Login) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rawTokens, _ := json.Marshal(tokens)
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(""))
+	w.Write(rawTokens)
 }
 
 // ActivateUser - Handles the sign up url
@@ -477,9 +456,12 @@ func (h ForgetPassword) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // ResetPassword - Handles situation where user forget password and needs to reset it
 // Reset link will have 2 query params - user id as well as the forget password token
+// ResetPassword sets the password of the user that forgot it. All sessions of the user are
+// ended as they might have been started by whoever knew the previous password
 type ResetPassword struct {
-	Logger    logger.Logger
-	UserStore user.Store
+	Logger       logger.Logger
+	UserStore    user.Store
+	SessionStore session.Store
 }
 
 func (h ResetPassword) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -535,6 +517,91 @@ func (h ResetPassword) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(generateErrorResp(errMsg)))
 		return
 	}
+
+	err = h.SessionStore.RevokeAll(context.TODO(), u.ID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - Unable to end sessions of user. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(500)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+}
+
+// ChangePassword sets a new password for the signed in user. All sessions of the user,
+// including the current one, are ended and the user needs to log in again
+type ChangePassword struct {
+	Logger       logger.Logger
+	UserStore    user.Store
+	SessionStore session.Store
+}
+
+func (h ChangePassword) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start ChangePassword Handler")
+	defer h.Logger.Info("End ChangePassword Handler")
+
+	ctx := r.Context()
+	userID := ctx.Value(userIDKey).(string)
+
+	type changePasswordReq struct {
+		CurrentPassword string `json:"current_password"`
+		Password        string `json:"password"`
+	}
+	rawReq, _ := ioutil.ReadAll(r.Body)
+	req := changePasswordReq{}
+	err := json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse change password body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	u, err := h.UserStore.GetUser(ctx, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to find user. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	if !u.IsPasswordCorrect(req.CurrentPassword) {
+		errMsg := "Error - current password is incorrect"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	updateSetters, err := u.ChangePassword(req.Password)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to change password. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+	_, err = h.UserStore.Update(ctx, u.ID, updateSetters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update user's password. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = h.SessionStore.RevokeAll(ctx, u.ID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to end sessions of user. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(""))
 }
 
 type GetUser struct {
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videoconcater"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type CreateVideoOutput struct {
+	Logger            logger.Logger
+	ProjectStore      project.Store
+	VideoOutputStore  videooutput.Store
+	VideoSegmentStore videosegment.Store
+}
+
+func (h CreateVideoOutput) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start CreateVideoOutput API Handler")
+	defer h.Logger.Info("End CreateVideoOutput API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	rawReq, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to read json body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type createVideoOutputReq struct {
+		Name            string   `json:"name"`
+		VideoSegmentIDs []string `json:"video_segment_ids"`
+		Resolution      string   `json:"resolution"`
+		Profile         string   `json:"profile"`
+		Language        string   `json:"language"`
+		BurnSubtitles   bool     `json:"burn_subtitles"`
+	}
+	req := createVideoOutputReq{}
+	err = json.Unmarshal(rawReq, &req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to parse json body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	for _, videoSegmentID := range req.VideoSegmentIDs {
+		_, err := h.VideoSegmentStore.Get(ctx, projectID, videoSegmentID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - video segment %v is not part of project. Error: %v", videoSegmentID, err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	item, err := videooutput.New(projectID, req.Name, req.VideoSegmentIDs, req.Resolution, req.Profile)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - invalid video output. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	if req.Language != "" {
+		singleProject, err := h.ProjectStore.Get(ctx, projectID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		if !singleProject.IsPrimaryLanguage(req.Language) {
+			l, ok := singleProject.Language(req.Language)
+			if !ok {
+				errMsg := fmt.Sprintf("Error - language %v is not part of project", req.Language)
+				h.Logger.Error(errMsg)
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(generateErrorResp(errMsg)))
+				return
+			}
+			item.Language = l.Code
+		}
+	}
+	item.BurnSubtitles = req.BurnSubtitles
+
+	err = h.VideoOutputStore.Create(ctx, item)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to create video output in datastore. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type GetVideoOutputs struct {
+	Logger           logger.Logger
+	VideoOutputStore videooutput.Store
+}
+
+func (h GetVideoOutputs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start GetVideoOutputs API Handler")
+	defer h.Logger.Info("End GetVideoOutputs API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+
+	rawOffset := r.URL.Query().Get("offset")
+	offset := 0
+	if rawOffset != "" {
+		offset, _ = strconv.Atoi(rawOffset)
+	}
+	rawLimit := r.URL.Query().Get("limit")
+	limit := 10
+	if rawLimit != "" {
+		limit, _ = strconv.Atoi(rawLimit)
+	}
+
+	videoOutputs, err := h.VideoOutputStore.GetAll(ctx, projectID, limit, offset)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve video outputs. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type getVideoOutputsResp struct {
+		VideoOutputs []videooutput.VideoOutput `json:"video_outputs"`
+		Limit        int                       `json:"limit"`
+		Offset       int                       `json:"offset"`
+	}
+	rawResp, _ := json.Marshal(getVideoOutputsResp{
+		VideoOutputs: videoOutputs,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
+
+type UpdateVideoOutput struct {
+	Logger             logger.Logger
+	ProjectStore       project.Store
+	VideoOutputStore   videooutput.Store
+	OutputVersionStore outputversion.Store
+	Blobstorage        blobstorage.BlobStorage
+	BucketFolderName   string
+	VersionsToKeep     int
+}
+
+func (h UpdateVideoOutput) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start UpdateVideoOutput API Handler")
+	defer h.Logger.Info("End UpdateVideoOutput API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoOutputID := mux.Vars(r)["videooutput_id"]
+
+	if !isServiceAccount(ctx) {
+	}
+
+	rawReq, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to read json body. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	type updateVideoOutputReq struct {
+		Status             string   `json:"status"`
+		OutputID           string   `json:"output_id"`
+		VideoSegments      []string `json:"video_segments"`
+		SetRunningIdemKey  string   `json:"idem_key_running"`
+		CompleteRecIdemKey string   `json:"idem_key_complete_rec"`
+	}
+	req := updateVideoOutputReq{}
+	json.Unmarshal(rawReq, &req)
+
+	updaters, err := videooutput.GetUpdaters(req.SetRunningIdemKey, req.CompleteRecIdemKey, req.Status, req.OutputID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - issue with updating; pre-update check. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	item, err := h.VideoOutputStore.Update(ctx, projectID, videoOutputID, updaters...)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to update video output. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	if item.OutputID != "" && req.Status == "completed" {
+		err = recordOutputVersion(ctx, h.Logger, h.OutputVersionStore, h.ProjectStore, h.Blobstorage, project.Folders{PDF: h.BucketFolderName, Images: imagesFolder}, projectID, videoOutputID, item.OutputID, req.VideoSegments, h.VersionsToKeep)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to record output version. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	rawItem, _ := json.Marshal(item)
+	w.Write(rawItem)
+}
+
+type StartVideoOutputGeneration struct {
+	Logger           logger.Logger
+	ProjectStore     project.Store
+	VideoOutputStore videooutput.Store
+	VideoConcater    videoconcater.VideoConcater
+}
+
+func (h StartVideoOutputGeneration) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Start StartVideoOutputGeneration API Handler")
+	defer h.Logger.Info("End StartVideoOutputGeneration API Handler")
+
+	ctx := r.Context()
+	projectID := mux.Vars(r)["project_id"]
+	videoOutputID := mux.Vars(r)["videooutput_id"]
+	userID := ctx.Value(userIDKey).(string)
+
+	videoOutput, err := h.VideoOutputStore.Get(ctx, projectID, videoOutputID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve video output. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	singleProject, err := h.ProjectStore.Get(context.TODO(), projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	// Video segments that are explicitly selected are used even if they are hidden
+	// Without a selection, the visible video segments of the project are used
+	videoSegments := singleProject.VisibleVideoSegments()
+	if len(videoOutput.Segments) > 0 {
+		videoSegments = singleProject.VideoSegments
+	}
+	sort.Sort(videosegment.ByOrder(videoSegments))
+	videoSegmentList, err := videoOutput.VideoFiles(videoSegments)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - %v. Generate the video segments before the video output", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	err = h.VideoConcater.StartOutput(ctx, videoOutput, userID, videoSegmentList)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to start video output generation. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	resp := map[string]string{
+		"status": "successfully sent",
+	}
+	rawResp, _ := json.Marshal(resp)
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawResp)
+}
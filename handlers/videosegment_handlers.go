@@ -9,6 +9,8 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/scriptrevision"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videogenerator"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 )
@@ -55,8 +57,9 @@ func (h CreateVideoSegment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 type UpdateVideoSegment struct {
-	Logger            logger.Logger
-	VideoSegmentStore videosegment.Store
+	Logger              logger.Logger
+	VideoSegmentStore   videosegment.Store
+	ScriptRevisionStore scriptrevision.Store
 }
 
 func (h UpdateVideoSegment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -79,12 +82,54 @@ func (h UpdateVideoSegment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Hidden             *bool  `json:"hidden"`
 		Script             string `json:"script"`
 		Status             string `json:"status"`
+		Language           string `json:"language"`
 		SetRunningIdemKey  string `json:"idem_key_running"`
 		CompleteRecIdemKey string `json:"idem_key_complete_rec"`
 	}
 	req := updateVideoSegmentReq{}
 	json.Unmarshal(rawReq, &req)
 
+	// Service accounts of workers are only able to update the status of the video segment
+	if isServiceAccount(r.Context()) && (req.Script != "" || req.Hidden != nil) {
+		errMsg := "Error - service accounts are only able to update the status of the video segment"
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	// Workers report the progress of videos in additional languages with the language
+	// Scripts of additional languages are edited via the translation endpoint instead
+	if req.Language != "" {
+		if req.Script != "" || req.Hidden != nil {
+			errMsg := fmt.Sprintf("Error - only the status can be updated for a language of the video segment")
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		updaters, err := videosegment.GetTranslationUpdaters(req.Language, req.SetRunningIdemKey, req.CompleteRecIdemKey, req.Status, req.VideoFile)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - issue with updating; pre-update check. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		item, err := h.VideoSegmentStore.Update(context.Background(), projectID, videoSegmentID, updaters...)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to update video segment in datastore. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		rawItem, _ := json.Marshal(item)
+		w.Write(rawItem)
+		return
+	}
+
 	updaters, err := videosegment.GetUpdaters(req.SetRunningIdemKey, req.CompleteRecIdemKey, req.Status, req.VideoFile, req.Script, req.Hidden)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - issue with updating; pre-update check. Error: %v", err)
@@ -94,6 +139,21 @@ func (h UpdateVideoSegment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Script edits are made by users (the workers only update the status) and are
+	// recorded with the user as the author of the revision
+	author, _ := r.Context().Value(userIDKey).(string)
+	var existing videosegment.VideoSegment
+	if req.Script != "" {
+		existing, err = h.VideoSegmentStore.Get(context.Background(), projectID, videoSegmentID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to get video segment in datastore. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
 	item, err := h.VideoSegmentStore.Update(context.Background(), projectID, videoSegmentID, updaters...)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to create video segment in datastore. Error: %v", err)
@@ -103,6 +163,17 @@ func (h UpdateVideoSegment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Script != "" {
+		err = recordScriptRevision(context.Background(), h.ScriptRevisionStore, existing, item, author)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error - unable to save script revision. Error: %v", err)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 	rawItem, _ := json.Marshal(item)
 	w.Write(rawItem)
@@ -136,6 +207,7 @@ func (h GetVideoSegment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 type StartVideoSegmentGeneration struct {
 	Logger            logger.Logger
+	ProjectStore      project.Store
 	VideoSegmentStore videosegment.Store
 	VideoGenerator    videogenerator.VideoGenerator
 }
@@ -144,6 +216,7 @@ func (h StartVideoSegmentGeneration) ServeHTTP(w http.ResponseWriter, r *http.Re
 	h.Logger.Info("Start VideoSegmentGeneration API Handler")
 	defer h.Logger.Info("End VideoSegmentGeneration API Handler")
 
+	userID := r.Context().Value(userIDKey).(string)
 	projectID := mux.Vars(r)["project_id"]
 	videosegmentID := mux.Vars(r)["videosegment_id"]
 
@@ -156,7 +229,37 @@ func (h StartVideoSegmentGeneration) ServeHTTP(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	err = h.VideoGenerator.Start(context.Background(), videosegment)
+	if videosegment.Hidden {
+		errMsg := fmt.Sprintf("Error - video segment is hidden. Unhide the video segment before generating its video")
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	singleProject, err := h.ProjectStore.Get(context.Background(), projectID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error - unable to retrieve project details. Error: %v", err)
+		h.Logger.Error(errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(generateErrorResp(errMsg)))
+		return
+	}
+
+	language := r.URL.Query().Get("language")
+	if singleProject.IsPrimaryLanguage(language) {
+		err = h.VideoGenerator.Start(context.Background(), videosegment, userID, singleProject.Settings)
+	} else {
+		languageSettings, langErr := singleProject.LanguageSettings(language)
+		if langErr != nil {
+			errMsg := fmt.Sprintf("Error - unable to generate video in language. Error: %v", langErr)
+			h.Logger.Error(errMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(generateErrorResp(errMsg)))
+			return
+		}
+		err = h.VideoGenerator.StartTranslation(context.Background(), videosegment, userID, language, languageSettings)
+	}
 	if err != nil {
 		errMsg := fmt.Sprintf("Error - unable to start async video generation. Error: %v", err)
 		h.Logger.Error(errMsg)
@@ -0,0 +1,46 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger     logger.Logger
+	entityName string
+	client     *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:     logger,
+		client:     ds,
+		entityName: en,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e Identity) error {
+	newKey := datastore.NameKey(g.entityName, e.ID, nil)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) GetBySubject(ctx context.Context, provider, subject string) (Identity, error) {
+	query := datastore.NewQuery(g.entityName).Filter("Provider =", provider).Filter("Subject =", subject).Limit(1)
+	identities := []Identity{}
+	keys, err := g.client.GetAll(ctx, query, &identities)
+	if err != nil {
+		return Identity{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	if len(keys) == 0 {
+		return Identity{}, fmt.Errorf("identity not found")
+	}
+	identities[0].ID = keys[0].Name
+	return identities[0], nil
+}
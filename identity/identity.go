@@ -0,0 +1,53 @@
+// Package identity links the accounts of users at OpenID Connect providers to users
+//
+// An identity is looked up by the provider and the subject of the ID token so that users stay
+// linked even if their email at the provider changes. New identities are linked to the user
+// with the same email, which is only trusted once the provider has verified it
+package identity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+var (
+	ErrUserIDEmpty   = errors.New("User of identity cannot be empty")
+	ErrProviderEmpty = errors.New("Provider of identity cannot be empty")
+	ErrSubjectEmpty  = errors.New("Subject of identity cannot be empty")
+)
+
+type Identity struct {
+	ID       string `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	UserID   string `json:"user_id" gorm:"type:varchar(40);index"`
+	Provider string `json:"provider" gorm:"type:varchar(100);unique_index:idx_identity_subject"`
+	Subject  string `json:"subject" gorm:"type:varchar(250);unique_index:idx_identity_subject"`
+	// Email is the email of the user at the provider when the identity was linked
+	Email       string    `json:"email" gorm:"type:varchar(250)"`
+	DateCreated time.Time `json:"date_created"`
+}
+
+// New links the account of the user at the provider
+func New(userID, provider, subject, email string) (Identity, error) {
+	if userID == "" {
+		return Identity{}, ErrUserIDEmpty
+	}
+	provider = strings.TrimSpace(provider)
+	if provider == "" {
+		return Identity{}, ErrProviderEmpty
+	}
+	if subject == "" {
+		return Identity{}, ErrSubjectEmpty
+	}
+	identityID, _ := uuid.NewV4()
+	return Identity{
+		ID:          identityID.String(),
+		UserID:      userID,
+		Provider:    provider,
+		Subject:     subject,
+		Email:       email,
+		DateCreated: time.Now(),
+	}, nil
+}
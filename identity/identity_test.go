@@ -0,0 +1,29 @@
+package identity
+
+import "testing"
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		provider string
+		subject  string
+		wantErr  error
+	}{
+		{name: "successful case", userID: "user", provider: "keycloak", subject: "1234"},
+		{name: "missing user", provider: "keycloak", subject: "1234", wantErr: ErrUserIDEmpty},
+		{name: "blank provider", userID: "user", provider: " ", subject: "1234", wantErr: ErrProviderEmpty},
+		{name: "missing subject", userID: "user", provider: "keycloak", wantErr: ErrSubjectEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.userID, tt.provider, tt.subject, "user@example.com")
+			if err != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.ID == "" || got.UserID != tt.userID || got.Subject != tt.subject) {
+				t.Errorf("New() = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e Identity) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (m mysql) GetBySubject(ctx context.Context, provider, subject string) (Identity, error) {
+	i := Identity{}
+	result := m.db.Where("provider = ? AND subject = ?", provider, subject).First(&i)
+	if result.Error != nil {
+		return i, result.Error
+	}
+	return i, nil
+}
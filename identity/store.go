@@ -0,0 +1,9 @@
+package identity
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e Identity) error
+	// GetBySubject retrieves the identity of the account at the provider
+	GetBySubject(ctx context.Context, provider, subject string) (Identity, error)
+}
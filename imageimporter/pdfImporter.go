@@ -6,34 +6,49 @@ import (
 
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/queue"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 )
 
+// PDFImporter splits the pdf into slide images with the project settings
+// The worker reports the progress of the import with a service account token that only allows
+// the status of the pdf slide images to be updated
 type PDFImporter interface {
-	Start(context.Context, pdfslideimages.PDFSlideImages) error
+	Start(ctx context.Context, s pdfslideimages.PDFSlideImages, userID string, projectSettings settings.Settings) error
 }
 
 // PDFImporter servers to be the holding struct to handle importing pdf to slide images
 type basicPDFImporter struct {
-	queue queue.Queue
+	queue     queue.Queue
+	authStore services.Auth
 }
 
-func NewBasicPDFImporter(q queue.Queue) basicPDFImporter {
+func NewBasicPDFImporter(q queue.Queue, a services.Auth) basicPDFImporter {
 	return basicPDFImporter{
-		queue: q,
+		queue:     q,
+		authStore: a,
 	}
 }
 
-func (p basicPDFImporter) Start(ctx context.Context, s pdfslideimages.PDFSlideImages) error {
-	values := map[string]string{
+func (p basicPDFImporter) Start(ctx context.Context, s pdfslideimages.PDFSlideImages, userID string, projectSettings settings.Settings) error {
+	token, err := services.NewServiceToken(services.PDFSplitter, services.UpdatePDFSlideImagesStatus, s.ProjectID, s.ID, userID, p.authStore.ExpiryTime, p.authStore.Keys(), p.authStore.Issuer)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
 		"id":                    s.ID,
 		"project_id":            s.ProjectID,
 		"pdf_filename":          s.PDFFile,
+		"auth_token":            "Bearer " + token,
 		"running_idem_key":      s.SetRunningIdemKey,
 		"complete_rec_idem_key": s.CompleteRecIdemKey,
+		"extract_scripts":       s.ExtractScripts,
+		"settings":              settings.Effective(projectSettings, settings.Voice{}),
 	}
 	jsonValue, _ := json.Marshal(values)
 
-	err := p.queue.Add(ctx, jsonValue)
+	err = p.queue.Add(ctx, jsonValue)
 	if err != nil {
 		return err
 	}
@@ -4,6 +4,7 @@ package job
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videoconcater"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 )
 
 type jobtype string
@@ -26,38 +29,44 @@ type Job struct {
 	JobType    jobtype   `json:"job_type" gorm:"type:varchar(200)"`
 	StartTime  time.Time `json:"start_time"`
 	ExpiryTime time.Time `json:"expiry_time"`
+	// Language is the additional language of the project that the videos are concatenated in
+	// An empty language concatenates the videos of the primary language
+	Language string `json:"language" gorm:"type:varchar(20)"`
 }
 
-func New(projectID, userID string) Job {
+func New(projectID, userID, language string) Job {
 	jobID, _ := uuid.NewV4()
 	return Job{
 		ID:         jobID.String(),
 		ProjectID:  projectID,
 		UserID:     userID,
+		Language:   language,
 		JobType:    TriggerVideoConcat,
 		StartTime:  time.Now(),
 		ExpiryTime: time.Now().Add(1 * time.Hour),
 	}
 }
 
-func NewProcessor(logger logger.Logger, jobStore Store, projectStore project.Store, videoconcater videoconcater.VideoConcater) (Processor, error) {
-	if logger == nil || jobStore == nil || projectStore == nil || videoconcater == nil {
+func NewProcessor(logger logger.Logger, jobStore Store, projectStore project.Store, videoOutputStore videooutput.Store, videoconcater videoconcater.VideoConcater) (Processor, error) {
+	if logger == nil || jobStore == nil || projectStore == nil || videoOutputStore == nil || videoconcater == nil {
 		return Processor{}, fmt.Errorf("cannot start processor as one of the inputs to processor is nil")
 	}
 
 	return Processor{
-		logger:        logger,
-		jobsStore:     jobStore,
-		projectStore:  projectStore,
-		videoconcater: videoconcater,
+		logger:           logger,
+		jobsStore:        jobStore,
+		projectStore:     projectStore,
+		videoOutputStore: videoOutputStore,
+		videoconcater:    videoconcater,
 	}, nil
 }
 
 type Processor struct {
-	logger        logger.Logger
-	jobsStore     Store
-	projectStore  project.Store
-	videoconcater videoconcater.VideoConcater
+	logger           logger.Logger
+	jobsStore        Store
+	projectStore     project.Store
+	videoOutputStore videooutput.Store
+	videoconcater    videoconcater.VideoConcater
 }
 
 func (p Processor) Start() {
@@ -107,35 +116,49 @@ func (p Processor) processTriggerVideoConcat(j Job) {
 		p.jobsStore.Delete(context.TODO(), j.ID)
 	}
 
-	project, err := p.projectStore.Get(context.TODO(), j.ProjectID, j.UserID)
+	project, err := p.projectStore.Get(context.TODO(), j.ProjectID)
 	if err != nil {
 		p.logger.Errorf("unable to get project details. will retry. ProjectID - %v :: Error - %v", j.ProjectID, err)
 		return
 	}
 
-	if len(project.VideoSegments) == 0 {
-		p.logger.Errorf("no video segments created - job will never need to trigger video concatenation")
+	// Hidden video segments are not generated and would not be part of the concatenated video
+	visibleVideoSegments := project.VisibleVideoSegments()
+	if len(visibleVideoSegments) == 0 {
+		p.logger.Errorf("no visible video segments - job will never need to trigger video concatenation")
 		p.jobsStore.Delete(context.TODO(), j.ID)
 		return
 	}
 
+	// Videos in an additional language are tracked by the translations of the video segments
 	completedStatusCount := 0
-	for _, v := range project.VideoSegments {
-		if v.Status == "completed" {
+	for _, v := range visibleVideoSegments {
+		if j.Language == "" && v.Status == "completed" {
+			completedStatusCount = completedStatusCount + 1
+		}
+		if t, ok := v.Translation(j.Language); j.Language != "" && ok && t.IsReady() {
 			completedStatusCount = completedStatusCount + 1
 		}
 	}
 
-	if completedStatusCount < len(project.VideoSegments) {
+	if completedStatusCount < len(visibleVideoSegments) {
 		p.logger.Infof("still processing - ProjectID - %v", j.ProjectID)
 		return
-	} else if completedStatusCount > len(project.VideoSegments) {
-		p.logger.Errorf("unexpected count of video segments. ProjectID - %v :: VideoSegmentCount - %v :: CompletedCount - %v", j.ProjectID, len(project.VideoSegments), completedStatusCount)
+	} else if completedStatusCount > len(visibleVideoSegments) {
+		p.logger.Errorf("unexpected count of video segments. ProjectID - %v :: VideoSegmentCount - %v :: CompletedCount - %v", j.ProjectID, len(visibleVideoSegments), completedStatusCount)
 		p.jobsStore.Delete(context.TODO(), j.ID)
 		return
 	}
 
-	p.logger.Infof("video segment processing completed. Will trigger video concat - ProjectID - %v", j.ProjectID)
+	p.logger.Infof("video segment processing completed. Will trigger video concat - ProjectID - %v :: Language - %v", j.ProjectID, j.Language)
+	if j.Language != "" {
+		err = p.startLanguageOutput(project, j)
+		if err != nil {
+			p.logger.Errorf("unable to start video concatenation in language. ProjectID - %v :: Language - %v :: Err - %v", j.ProjectID, j.Language, err)
+		}
+		p.jobsStore.Delete(context.TODO(), j.ID)
+		return
+	}
 	vidSegmentList, err := project.GetVideoSegmentList()
 	if err != nil {
 		p.logger.Errorf("unable to get video segment list from project. will retry. ProjectID - %v :: Err - %v", j.ProjectID, err)
@@ -146,3 +169,19 @@ func (p Processor) processTriggerVideoConcat(j Job) {
 	}
 	p.jobsStore.Delete(context.TODO(), j.ID)
 }
+
+// startLanguageOutput concatenates the videos of the visible video segments in the language of
+// the job into the video output of the language
+func (p Processor) startLanguageOutput(singleProject project.Project, j Job) error {
+	videoOutput, err := videooutput.GetLanguageOutput(context.TODO(), p.videoOutputStore, singleProject.ID, j.Language)
+	if err != nil {
+		return err
+	}
+	videoSegments := singleProject.VisibleVideoSegments()
+	sort.Sort(videosegment.ByOrder(videoSegments))
+	videoSegmentList, err := videoOutput.VideoFiles(videoSegments)
+	if err != nil {
+		return err
+	}
+	return p.videoconcater.StartOutput(context.TODO(), videoOutput, j.UserID, videoSegmentList)
+}
@@ -0,0 +1,254 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrNonceMismatch   = errors.New("Nonce of ID token does not match the request")
+	ErrIssuerMismatch  = errors.New("ID token was not issued by the provider")
+	ErrAudienceInvalid = errors.New("ID token was not issued to the client")
+	ErrExpired         = errors.New("ID token has expired")
+	ErrSubjectEmpty    = errors.New("ID token has no subject")
+)
+
+// clockSkew is allowed between the clocks of the manager and the provider
+const clockSkew = time.Minute
+
+// keysRefreshInterval limits how often the keys of a provider are retrieved when an ID token
+// is signed by an unknown key
+const keysRefreshInterval = time.Minute
+
+// signingMethods are the algorithms that ID tokens are accepted with. HS256 is left out as
+// the secret of the client is not meant to sign tokens
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Claims are the claims of an ID token that are used to sign the user in
+type Claims struct {
+	Issuer          string        `json:"iss"`
+	Subject         string        `json:"sub"`
+	Audience        audience      `json:"aud"`
+	AuthorizedParty string        `json:"azp,omitempty"`
+	ExpiresAt       int64         `json:"exp"`
+	IssuedAt        int64         `json:"iat"`
+	NotBefore       int64         `json:"nbf,omitempty"`
+	Nonce           string        `json:"nonce"`
+	Email           string        `json:"email"`
+	EmailVerified   emailVerified `json:"email_verified"`
+}
+
+// Valid checks the times of the token. The rest of the claims are checked against the provider
+func (c *Claims) Valid() error {
+	now := time.Now()
+	if c.ExpiresAt == 0 || now.Add(-clockSkew).Unix() > c.ExpiresAt {
+		return ErrExpired
+	}
+	if c.NotBefore != 0 && now.Add(clockSkew).Unix() < c.NotBefore {
+		return fmt.Errorf("ID token is not valid yet")
+	}
+	return nil
+}
+
+// audience is either a single client or a list of clients
+type audience []string
+
+func (a *audience) UnmarshalJSON(raw []byte) error {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(raw, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// emailVerified is sent as a boolean by most providers and as a string by a few
+type emailVerified bool
+
+func (e *emailVerified) UnmarshalJSON(raw []byte) error {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		*e = emailVerified(b)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+	*e = emailVerified(s == "true")
+	return nil
+}
+
+// VerifyIDToken checks that the ID token was signed by the provider for the client and the
+// request with the nonce before returning its claims
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	parser := jwt.Parser{ValidMethods: signingMethods}
+	claims := &Claims{}
+	_, err = parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, md, kid)
+	})
+	if err != nil {
+		return Claims{}, fmt.Errorf("invalid ID token from provider %v. Err: %v", p.Name, err)
+	}
+
+	if claims.Issuer != md.Issuer {
+		return Claims{}, ErrIssuerMismatch
+	}
+	if !claims.Audience.contains(p.ClientID) {
+		return Claims{}, ErrAudienceInvalid
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.ClientID {
+		return Claims{}, ErrAudienceInvalid
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return Claims{}, ErrNonceMismatch
+	}
+	if claims.Subject == "" {
+		return Claims{}, ErrSubjectEmpty
+	}
+	return *claims, nil
+}
+
+// key provides the public key of the provider with the key ID. Keys are retrieved again when
+// the key is unknown as providers rotate their keys
+func (p *Provider) key(ctx context.Context, md Metadata, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	if time.Since(p.keysFetched) < keysRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %v", kid)
+	}
+
+	type jsonWebKeySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	jwks := jsonWebKeySet{}
+	err := p.getJSON(ctx, md.JWKSURI, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve keys of provider %v. Err: %v", p.Name, err)
+	}
+	keys := map[string]interface{}{}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		publicKey, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = publicKey
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %v", kid)
+}
+
+// lookupKey finds the key with the key ID. Tokens without a key ID are only accepted if the
+// provider has a single key
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+// jsonWebKey is a public key published by a provider
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %v", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %v", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %v", k.Kty)
+}
@@ -0,0 +1,240 @@
+// Package oidc signs users in with OpenID Connect providers such as Keycloak, Okta or Azure AD
+//
+// Providers are configured with their issuer. The endpoints and keys of the provider are
+// discovered from the issuer on first use. Users are sent to the provider with the
+// authorization code flow, protected by PKCE, and a state and nonce that are checked once the
+// user returns. The ID token returned by the provider is verified before its claims are used
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNameEmpty        = errors.New("Name of provider cannot be empty")
+	ErrIssuerEmpty      = errors.New("Issuer of provider cannot be empty")
+	ErrClientIDEmpty    = errors.New("Client ID of provider cannot be empty")
+	ErrRedirectURIEmpty = errors.New("Redirect URI of provider cannot be empty")
+	ErrIDTokenMissing   = errors.New("Provider did not return an ID token")
+)
+
+// Config identifies the manager to a provider. Scopes are requested on top of the openid scope
+// ClientSecret is left empty for public clients, which are only protected by PKCE
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+}
+
+// Metadata is the part of the discovery document of a provider that is used
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider signs users in with a single OpenID Connect provider
+type Provider struct {
+	Config
+	client *http.Client
+
+	mu          sync.Mutex
+	metadata    *Metadata
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+// NewProvider creates a provider from its configuration. The provider is only contacted once
+// it is used so that the manager is able to start while a provider is unavailable
+func NewProvider(client *http.Client, cfg Config) (*Provider, error) {
+	if cfg.Name == "" {
+		return nil, ErrNameEmpty
+	}
+	if cfg.Issuer == "" {
+		return nil, ErrIssuerEmpty
+	}
+	if cfg.ClientID == "" {
+		return nil, ErrClientIDEmpty
+	}
+	if cfg.RedirectURI == "" {
+		return nil, ErrRedirectURIEmpty
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{Config: cfg, client: client}, nil
+}
+
+// AuthRequest holds the values that tie the response of the provider to the request that
+// the user was sent with. It is to be kept by the user agent until the user returns
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// NewAuthRequest creates random values for a new sign in
+func NewAuthRequest() (AuthRequest, error) {
+	values := make([]string, 3)
+	for i := range values {
+		raw := make([]byte, 32)
+		_, err := rand.Read(raw)
+		if err != nil {
+			return AuthRequest{}, err
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	return AuthRequest{State: values[0], Nonce: values[1], CodeVerifier: values[2]}, nil
+}
+
+// CodeChallenge derives the S256 PKCE challenge of the code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// discover retrieves the discovery document of the provider. The document is kept once it
+// has been retrieved
+func (p *Provider) discover(ctx context.Context) (Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return *p.metadata, nil
+	}
+
+	md := Metadata{}
+	err := p.getJSON(ctx, strings.TrimSuffix(p.Issuer, "/")+"/.well-known/openid-configuration", &md)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("unable to discover provider %v. Err: %v", p.Name, err)
+	}
+	if md.Issuer != p.Issuer {
+		return Metadata{}, fmt.Errorf("provider %v has issuer %v instead of %v", p.Name, md.Issuer, p.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return Metadata{}, fmt.Errorf("discovery document of provider %v is missing endpoints", p.Name)
+	}
+	p.metadata = &md
+	return md, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v responded with status %v", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// AuthCodeURL provides the URL of the provider that the user is sent to in order to sign in
+func (p *Provider) AuthCodeURL(ctx context.Context, a AuthRequest) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	authURL, err := url.Parse(md.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint of provider %v. Err: %v", p.Name, err)
+	}
+
+	scopes := []string{"openid"}
+	for _, s := range p.Scopes {
+		if s != "openid" {
+			scopes = append(scopes, s)
+		}
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", p.RedirectURI)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", a.State)
+	q.Set("nonce", a.Nonce)
+	q.Set("code_challenge", CodeChallenge(a.CodeVerifier))
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+	return authURL.String(), nil
+}
+
+// Token is the response of the provider to the exchange of an authorization code
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// Exchange trades the authorization code that the user returned with for the tokens of the user
+// The code verifier proves that the code is redeemed by the same client that requested it
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (Token, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURI)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.ClientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("unable to exchange code with provider %v. Err: %v", p.Name, err)
+	}
+	defer resp.Body.Close()
+	rawResp, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		type errorResp struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		e := errorResp{}
+		json.Unmarshal(rawResp, &e)
+		return Token{}, fmt.Errorf("provider %v rejected code with status %v. Err: %v %v", p.Name, resp.StatusCode, e.Error, e.ErrorDescription)
+	}
+
+	t := Token{}
+	err = json.Unmarshal(rawResp, &t)
+	if err != nil {
+		return Token{}, fmt.Errorf("unable to parse token response of provider %v. Err: %v", p.Name, err)
+	}
+	if t.IDToken == "" {
+		return Token{}, ErrIDTokenMissing
+	}
+	return t, nil
+}
@@ -0,0 +1,138 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/oidc/oidctest"
+)
+
+func providerHelper(t *testing.T, server *oidctest.Server) *Provider {
+	p, err := NewProvider(server.Client(), Config{
+		Name:         "mock",
+		Issuer:       server.Issuer(),
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURI:  "http://localhost/callback",
+		Scopes:       []string{"email"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return p
+}
+
+// signIn sends the user to the provider and returns the code that the user is sent back with
+func signIn(t *testing.T, server *oidctest.Server, p *Provider, a AuthRequest) string {
+	authURL, err := p.AuthCodeURL(context.Background(), a)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	client := server.Client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(authURL)
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("unable to sign in at provider. %v %v", resp, err)
+	}
+	location, _ := url.Parse(resp.Header.Get("Location"))
+	if location.Query().Get("state") != a.State {
+		t.Fatalf("expected state to be returned. Got %v", location)
+	}
+	return location.Query().Get("code")
+}
+
+func TestProvider(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: "1234", Email: "user@example.com", EmailVerified: true})
+	p := providerHelper(t, server)
+	ctx := context.Background()
+
+	a, _ := NewAuthRequest()
+	code := signIn(t, server, p, a)
+	token, err := p.Exchange(ctx, code, a.CodeVerifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, a.Nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if claims.Subject != "1234" || claims.Email != "user@example.com" || !bool(claims.EmailVerified) {
+		t.Errorf("VerifyIDToken() = %+v", claims)
+	}
+
+	if _, err := p.Exchange(ctx, code, a.CodeVerifier); err == nil {
+		t.Errorf("expected code to only be redeemed once")
+	}
+	if _, err := p.VerifyIDToken(ctx, token.IDToken, "other"); err != ErrNonceMismatch {
+		t.Errorf("expected nonce of other request to be rejected. Err: %v", err)
+	}
+
+	other, _ := NewAuthRequest()
+	code = signIn(t, server, p, a)
+	if _, err := p.Exchange(ctx, code, other.CodeVerifier); err == nil {
+		t.Errorf("expected code verifier of other request to be rejected")
+	}
+}
+
+func TestProvider_VerifyIDToken(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	p := providerHelper(t, server)
+
+	now := time.Now()
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":   server.Issuer(),
+			"sub":   "1234",
+			"aud":   "client",
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil)).SignedString([]byte("secret"))
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "successful case", token: server.SignIDToken(claims(nil))},
+		{name: "list of audiences", token: server.SignIDToken(claims(jwt.MapClaims{"aud": []string{"client", "other"}, "azp": "client"}))},
+		{name: "other client", token: server.SignIDToken(claims(jwt.MapClaims{"aud": "other"})), wantErr: true},
+		{name: "authorized for other client", token: server.SignIDToken(claims(jwt.MapClaims{"aud": []string{"client", "other"}, "azp": "other"})), wantErr: true},
+		{name: "other issuer", token: server.SignIDToken(claims(jwt.MapClaims{"iss": "https://example.com"})), wantErr: true},
+		{name: "expired", token: server.SignIDToken(claims(jwt.MapClaims{"exp": now.Add(-time.Hour).Unix()})), wantErr: true},
+		{name: "missing nonce", token: server.SignIDToken(claims(jwt.MapClaims{"nonce": ""})), wantErr: true},
+		{name: "signed with client secret", token: hmacToken, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.VerifyIDToken(context.Background(), tt.token, "nonce")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyIDToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProvider_IssuerMismatch(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	p, _ := NewProvider(server.Client(), Config{Name: "mock", Issuer: server.Issuer() + "/", ClientID: "client", RedirectURI: "http://localhost/callback"})
+
+	a, _ := NewAuthRequest()
+	if _, err := p.AuthCodeURL(context.Background(), a); err == nil {
+		t.Errorf("expected provider with a different issuer in its discovery document to be rejected")
+	}
+}
@@ -0,0 +1,202 @@
+// Package oidctest provides a local OpenID Connect provider for tests
+//
+// The provider signs in whichever user is set on it without prompting. It checks the client,
+// the redirect URI and the PKCE verifier of each code the same way a real provider would
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// keyID is the ID of the key that the server signs ID tokens with
+const keyID = "oidctest"
+
+// User is the user that is signed in at the server
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// authorization is an authorization code that has yet to be redeemed
+type authorization struct {
+	user          User
+	nonce         string
+	redirectURI   string
+	codeChallenge string
+}
+
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	user  User
+	codes map[string]authorization
+}
+
+// NewServer starts a provider for the client. Close the server once the test is done
+func NewServer(clientID, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        map[string]authorization{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Issuer is the issuer that the server is configured with
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// SetUser signs the user in at the server
+func (s *Server) SetUser(u User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = u
+}
+
+// SignIDToken signs the claims with the key of the server
+func (s *Server) SignIDToken(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.Issuer(),
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	encode := base64.RawURLEncoding.EncodeToString
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   encode(s.key.N.Bytes()),
+			"e":   encode(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize signs the user in right away and sends the user back to the client with a code
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != s.ClientID || q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("redirect_uri") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	code := randomString()
+	s.codes[code] = authorization{
+		user:          s.user,
+		nonce:         q.Get("nonce"),
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+	}
+	s.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token redeems a code for the ID token of the user. Codes can only be redeemed once
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != s.ClientID || clientSecret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	code := r.PostFormValue("code")
+	a, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != a.redirectURI || base64.RawURLEncoding.EncodeToString(sum[:]) != a.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken := s.SignIDToken(jwt.MapClaims{
+		"iss":            s.Issuer(),
+		"sub":            a.user.Subject,
+		"aud":            s.ClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          a.nonce,
+		"email":          a.user.Email,
+		"email_verified": a.user.EmailVerified,
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"id_token":     idToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	raw, _ := json.Marshal(v)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(raw)
+}
+
+func randomString() string {
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
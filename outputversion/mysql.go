@@ -0,0 +1,102 @@
+package outputversion
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e OutputVersion) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, s := range e.Segments {
+		result = m.db.Save(&s)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, projectID, ID string) (OutputVersion, error) {
+	v := OutputVersion{}
+	result := m.db.Where("id = ? AND project_id = ?", ID, projectID).First(&v)
+	if result.Error != nil {
+		return v, result.Error
+	}
+	err := m.loadSegments(&v)
+	if err != nil {
+		return OutputVersion{}, err
+	}
+	return v, nil
+}
+
+func (m mysql) GetAll(ctx context.Context, projectID, videoOutputID string, Limit, After int) ([]OutputVersion, error) {
+	var versions []OutputVersion
+	result := m.db.Where("project_id = ? AND video_output_id = ?", projectID, videoOutputID).Order("version desc").Limit(Limit).Offset(After).Find(&versions)
+	if result.Error != nil {
+		return []OutputVersion{}, result.Error
+	}
+	for i := range versions {
+		err := m.loadSegments(&versions[i])
+		if err != nil {
+			return []OutputVersion{}, err
+		}
+	}
+	return versions, nil
+}
+
+func (m mysql) Update(ctx context.Context, projectID, ID string, setters ...func(*OutputVersion) error) (OutputVersion, error) {
+	v, err := m.Get(ctx, projectID, ID)
+	if err != nil {
+		return OutputVersion{}, err
+	}
+	for _, s := range setters {
+		err := s(&v)
+		if err != nil {
+			return OutputVersion{}, err
+		}
+	}
+	result := m.db.Save(&v)
+	if result.Error != nil {
+		return OutputVersion{}, result.Error
+	}
+	return v, nil
+}
+
+func (m mysql) Delete(ctx context.Context, projectID, ID string) error {
+	result := m.db.Where("output_version_id = ?", ID).Delete(VersionSegment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	result = m.db.Where("id = ? and project_id = ?", ID, projectID).Delete(OutputVersion{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (m mysql) loadSegments(v *OutputVersion) error {
+	s := []VersionSegment{}
+	result := m.db.Where("output_version_id = ?", v.ID).Order("`order`").Find(&s)
+	if result.Error != nil {
+		return result.Error
+	}
+	v.Segments = s
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package outputversion keeps the history of the videos that were rendered for a project
+// Every completed concatenation is stored as a numbered version so that earlier videos
+// remain downloadable and a version can be pinned as the published video
+package outputversion
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type VersionSegment struct {
+	OutputVersionID string `json:"-" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	VideoFile       string `json:"video_file" gorm:"type:varchar(100);primary_key"`
+	Order           int    `json:"order" gorm:"type:int"`
+}
+
+type OutputVersion struct {
+	ID        string `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	ProjectID string `json:"project_id" datastore:"-" gorm:"type:varchar(40)"`
+	// VideoOutputID is empty for versions of the main video of the project
+	VideoOutputID string           `json:"video_output_id,omitempty" gorm:"type:varchar(40)"`
+	Version       int              `json:"version" gorm:"type:int"`
+	BlobID        string           `json:"blob_id" gorm:"type:varchar(100)"`
+	Segments      []VersionSegment `json:"segments"`
+	Published     bool             `json:"published"`
+	DateCreated   time.Time        `json:"date_created"`
+}
+
+func New(projectID, videoOutputID string, version int, blobID string, videoFiles []string) OutputVersion {
+	id, _ := uuid.NewV4()
+	segments := []VersionSegment{}
+	for i, v := range videoFiles {
+		segments = append(segments, VersionSegment{
+			OutputVersionID: id.String(),
+			VideoFile:       v,
+			Order:           i,
+		})
+	}
+	return OutputVersion{
+		ID:            id.String(),
+		ProjectID:     projectID,
+		VideoOutputID: videoOutputID,
+		Version:       version,
+		BlobID:        blobID,
+		Segments:      segments,
+		DateCreated:   time.Now(),
+	}
+}
+
+// Expired returns the versions that are to be cleaned up when only the latest
+// versionsToKeep versions are retained. Published versions are always retained
+// A versionsToKeep of 0 or less retains all versions
+func Expired(versions []OutputVersion, versionsToKeep int) []OutputVersion {
+	expired := []OutputVersion{}
+	if versionsToKeep <= 0 {
+		return expired
+	}
+	sorted := make([]OutputVersion, len(versions))
+	copy(sorted, versions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version > sorted[j].Version })
+	for i, v := range sorted {
+		if i < versionsToKeep || v.Published {
+			continue
+		}
+		expired = append(expired, v)
+	}
+	return expired
+}
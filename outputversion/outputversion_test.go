@@ -0,0 +1,32 @@
+package outputversion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpired(t *testing.T) {
+	var versions []OutputVersion
+	for i := 1; i <= 5; i++ {
+		versions = append(versions, New("project", "", i, "video.mp4", []string{"segment.mp4"}))
+	}
+	versions[0].Published = true
+
+	expiredVersions := func(keep int) []int {
+		nums := []int{}
+		for _, v := range Expired(versions, keep) {
+			nums = append(nums, v.Version)
+		}
+		return nums
+	}
+
+	if got := expiredVersions(2); !reflect.DeepEqual(got, []int{3, 2}) {
+		t.Errorf("unexpected expired versions when keeping 2 versions. Got: %v", got)
+	}
+	if got := expiredVersions(0); len(got) != 0 {
+		t.Errorf("no versions are expected to expire when retention is disabled. Got: %v", got)
+	}
+	if got := expiredVersions(10); len(got) != 0 {
+		t.Errorf("no versions are expected to expire when there are fewer versions than retained. Got: %v", got)
+	}
+}
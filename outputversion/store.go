@@ -0,0 +1,26 @@
+package outputversion
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e OutputVersion) error
+	Get(ctx context.Context, projectID, ID string) (OutputVersion, error)
+	// GetAll retrieves the versions of the main video (empty videoOutputID) or a video output of the project
+	// Versions are sorted from the latest version
+	GetAll(ctx context.Context, projectID, videoOutputID string, Limit, After int) ([]OutputVersion, error)
+	Update(ctx context.Context, projectID, ID string, setters ...func(*OutputVersion) error) (OutputVersion, error)
+	Delete(ctx context.Context, projectID, ID string) error
+}
+
+func SetPublished(published bool) ([]func(*OutputVersion) error, error) {
+	var setters []func(*OutputVersion) error
+	setters = append(setters, setPublished(published))
+	return setters, nil
+}
+
+func setPublished(published bool) func(*OutputVersion) error {
+	return func(a *OutputVersion) error {
+		a.Published = published
+		return nil
+	}
+}
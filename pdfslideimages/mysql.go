@@ -24,6 +24,12 @@ func (m mysql) Create(ctx context.Context, e PDFSlideImages) error {
 	if result.Error != nil {
 		return result.Error
 	}
+	for _, s := range e.SlideAssets {
+		result = m.db.Save(&s)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
 	return nil
 }
 
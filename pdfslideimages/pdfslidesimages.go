@@ -1,9 +1,11 @@
 package pdfslideimages
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
 )
 
 // For job statuses
@@ -19,13 +21,17 @@ var (
 type SlideAsset struct {
 	ImageID         string `json:"image_id" gorm:"type:varchar(200);primary_key"`
 	Order           int    `json:"order" gorm:"type:int"`
-	PDFSlideImageID string `json:"-" datastore:"-" gorm:"type:varchar(100)"`
+	PDFSlideImageID string `json:"-" datastore:"-" gorm:"type:varchar(100);primary_key"`
+	// Script holds the text extracted from the pdf page of the slide (if requested)
+	Script string `json:"script,omitempty" datastore:",noindex" gorm:"type:text"`
 }
 
 type PDFSlideImages struct {
 	ID                 string       `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
 	ProjectID          string       `json:"project_id" datastore:"-" gorm:"type:varchar(40)"`
 	PDFFile            string       `json:"pdf_file" gorm:"type:varchar(200)"`
+	ScriptFile         string       `json:"script_file,omitempty" gorm:"type:varchar(200)"`
+	ExtractScripts     bool         `json:"extract_scripts"`
 	DateCreated        time.Time    `json:"date_created"`
 	SlideAssets        []SlideAsset `json:"slide_assets"`
 	Status             status       `json:"status" gorm:"type:varchar(20)"`
@@ -54,3 +60,38 @@ func New(projectID string) PDFSlideImages {
 		CompleteRecIdemKey: idemKey2.String(),
 	}
 }
+
+// Clone copies the pdf slide images record into a new record under the given project.
+// The pdf file, script file and slide images are given new names owned by the new record
+// so the caller is expected to copy the files over to the new names. The new record gets its own
+// idem keys so that status reports meant for the source record are not applied to it
+func (p *PDFSlideImages) Clone(projectID string) PDFSlideImages {
+	id, _ := uuid.NewV4()
+	idemKey1, _ := uuid.NewV4()
+	idemKey2, _ := uuid.NewV4()
+	assets := []SlideAsset{}
+	for _, s := range p.SlideAssets {
+		assets = append(assets, SlideAsset{
+			ImageID:         fmt.Sprintf("%v-%v%v", id.String(), s.Order, blobstorage.Ext(s.ImageID)),
+			Order:           s.Order,
+			PDFSlideImageID: id.String(),
+			Script:          s.Script,
+		})
+	}
+	scriptFile := ""
+	if p.ScriptFile != "" {
+		scriptFile = id.String() + "-script" + blobstorage.Ext(p.ScriptFile)
+	}
+	return PDFSlideImages{
+		ID:                 id.String(),
+		ProjectID:          projectID,
+		PDFFile:            id.String() + ".pdf",
+		ScriptFile:         scriptFile,
+		ExtractScripts:     p.ExtractScripts,
+		DateCreated:        time.Now(),
+		SlideAssets:        assets,
+		Status:             p.Status,
+		SetRunningIdemKey:  idemKey1.String(),
+		CompleteRecIdemKey: idemKey2.String(),
+	}
+}
@@ -0,0 +1,47 @@
+package pdfslideimages
+
+import (
+	"testing"
+)
+
+func TestPDFSlideImages_Clone(t *testing.T) {
+	source := New("project")
+	source.ScriptFile = source.ID + "-script.csv"
+	source.ExtractScripts = true
+	source.Status = completed
+	source.SlideAssets = []SlideAsset{
+		{ImageID: source.ID + "-0.png", Order: 0, PDFSlideImageID: source.ID, Script: "hello"},
+		{ImageID: source.ID + "-1.png", Order: 1, PDFSlideImageID: source.ID},
+	}
+
+	cloned := source.Clone("other")
+	if cloned.ID == source.ID || cloned.ProjectID != "other" {
+		t.Fatalf("expected clone to be a new record of the other project. Clone: %+v", cloned)
+	}
+	if cloned.PDFFile != cloned.ID+".pdf" || cloned.ScriptFile != cloned.ID+"-script.csv" {
+		t.Errorf("expected files to be named after the clone. Clone: %+v", cloned)
+	}
+	if !cloned.ExtractScripts || cloned.Status != completed {
+		t.Errorf("expected settings and status to be copied. Clone: %+v", cloned)
+	}
+	if cloned.SetRunningIdemKey == "" || cloned.CompleteRecIdemKey == "" ||
+		cloned.SetRunningIdemKey == source.SetRunningIdemKey || cloned.CompleteRecIdemKey == source.CompleteRecIdemKey {
+		t.Errorf("expected clone to have its own idem keys. Clone: %+v", cloned)
+	}
+	if len(cloned.SlideAssets) != 2 {
+		t.Fatalf("expected slide assets to be copied. Assets: %+v", cloned.SlideAssets)
+	}
+	for i, a := range cloned.SlideAssets {
+		if a.PDFSlideImageID != cloned.ID || a.Order != source.SlideAssets[i].Order || a.Script != source.SlideAssets[i].Script {
+			t.Errorf("unexpected slide asset %v. Asset: %+v", i, a)
+		}
+		if a.ImageID == source.SlideAssets[i].ImageID {
+			t.Errorf("expected slide asset %v to refer to a new image. Asset: %+v", i, a)
+		}
+	}
+
+	source.ScriptFile = ""
+	if cloned := source.Clone("other"); cloned.ScriptFile != "" {
+		t.Errorf("expected no script file on clone. Clone: %+v", cloned)
+	}
+}
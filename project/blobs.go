@@ -0,0 +1,199 @@
+package project
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/subtitles"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+// Folders are the folders in blob storage that the pdf files and slide images are kept in
+// The rest of the files of a project are kept at the root of blob storage
+type Folders struct {
+	PDF    string `json:"pdf"`
+	Images string `json:"images"`
+}
+
+// Blob is a file in blob storage that a project refers to
+type Blob struct {
+	Path string `json:"path"`
+	// Optional files such as subtitles are only generated for some of the videos
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Blobs lists the files in blob storage that the project refers to, which are the files
+// that Copy would copy over along with the generated videos. The project is expected to
+// have its pdf slide images and video segments already loaded
+func (p *Project) Blobs(folders Folders) []Blob {
+	_, copies := p.Copy(p.Name, folders, folders, true)
+	blobs := []Blob{}
+	for _, c := range copies {
+		blobs = append(blobs, Blob{Path: c.From, Optional: c.Optional})
+	}
+	return blobs
+}
+
+// BlobCopy is a file in blob storage that is to be copied over to a new name
+type BlobCopy struct {
+	From string
+	To   string
+	// Optional files such as subtitles are only generated for some of the videos
+	Optional bool
+}
+
+// Copy clones the project along with its pdf slide images and video segments. Every file
+// that the copy refers to is given a new name owned by the copy so that no files are shared
+// between projects. The files to be copied over to the new names are returned alongside
+// the copy. Generated videos are only carried over if withVideos is set
+//
+// The project is expected to have its pdf slide images and video segments already loaded
+// The folders of the source files can differ from the folders of the copy
+func (p *Project) Copy(name string, from, to Folders, withVideos bool) (Project, []BlobCopy) {
+	newProject := p.Clone(name)
+	var blobs []BlobCopy
+	if p.Music.File != "" {
+		blobs = append(blobs, BlobCopy{From: p.Music.File, To: newProject.Music.File})
+	}
+
+	images := map[string]string{}
+	for _, s := range p.PDFSlideImages {
+		newSlideImages := s.Clone(newProject.ID)
+		blobs = append(blobs, BlobCopy{From: from.PDF + "/" + s.PDFFile, To: to.PDF + "/" + newSlideImages.PDFFile})
+		if s.ScriptFile != "" {
+			blobs = append(blobs, BlobCopy{From: from.PDF + "/" + s.ScriptFile, To: to.PDF + "/" + newSlideImages.ScriptFile})
+		}
+		for i, a := range s.SlideAssets {
+			if _, ok := images[a.ImageID]; ok {
+				continue
+			}
+			images[a.ImageID] = newSlideImages.SlideAssets[i].ImageID
+			blobs = append(blobs, BlobCopy{From: from.Images + "/" + a.ImageID, To: to.Images + "/" + newSlideImages.SlideAssets[i].ImageID})
+		}
+		newProject.PDFSlideImages = append(newProject.PDFSlideImages, newSlideImages)
+	}
+
+	for _, v := range p.VideoSegments {
+		segment := v.Clone(newProject.ID)
+		if v.ImageID != "" {
+			imageID, ok := images[v.ImageID]
+			if !ok {
+				imageID = segment.ID + blobstorage.Ext(v.ImageID)
+				images[v.ImageID] = imageID
+				blobs = append(blobs, BlobCopy{From: from.Images + "/" + v.ImageID, To: to.Images + "/" + imageID})
+			}
+			segment.ImageID = imageID
+		}
+		if v.AudioID != "" {
+			audioID, _ := uuid.NewV4()
+			segment.AudioID = audioID.String() + blobstorage.Ext(v.AudioID)
+			blobs = append(blobs, BlobCopy{From: v.AudioID, To: segment.AudioID})
+		}
+		if withVideos {
+			blobs = append(blobs, p.copySegmentVideos(v, &segment)...)
+		}
+		newProject.VideoSegments = append(newProject.VideoSegments, segment)
+	}
+
+	if withVideos && p.VideoOutputID != "" {
+		newProject.VideoOutputID = newProject.ID + ".mp4"
+		blobs = append(blobs, BlobCopy{From: p.VideoOutputID, To: newProject.VideoOutputID})
+		for _, format := range []string{subtitles.FormatVTT, subtitles.FormatSRT} {
+			blobs = append(blobs, BlobCopy{
+				From:     subtitles.FileName(p.VideoOutputID, format),
+				To:       subtitles.FileName(newProject.VideoOutputID, format),
+				Optional: true,
+			})
+		}
+	}
+	return newProject, blobs
+}
+
+// copySegmentVideos carries the generated videos of the video segment over to its copy
+// Videos that were up to date stay up to date even though the copy refers to new files
+func (p *Project) copySegmentVideos(v videosegment.VideoSegment, segment *videosegment.VideoSegment) []BlobCopy {
+	var blobs []BlobCopy
+	if v.VideoFile != "" {
+		segment.VideoFile = segment.ID + ".mp4"
+		segment.Status = v.Status
+		segment.ScriptRevision = v.ScriptRevision
+		segment.VideoScriptRevision = v.VideoScriptRevision
+		segment.RenderedFingerprint = v.RenderedFingerprint
+		if !v.IsStale(p.Settings) {
+			segment.RenderedFingerprint = segment.Fingerprint(p.Settings)
+		}
+		blobs = append(blobs, BlobCopy{From: v.VideoFile, To: segment.VideoFile})
+	}
+	for i, t := range v.Translations {
+		if t.VideoFile == "" {
+			continue
+		}
+		translation := &segment.Translations[i]
+		translation.VideoFile = segment.ID + "-" + t.Language + ".mp4"
+		translation.Status = t.Status
+		translation.RenderedFingerprint = t.RenderedFingerprint
+		languageSettings, err := p.LanguageSettings(t.Language)
+		if err == nil && !v.IsTranslationStale(t.Language, languageSettings) {
+			translation.RenderedFingerprint = segment.TranslationFingerprint(t.Language, languageSettings)
+		}
+		blobs = append(blobs, BlobCopy{From: t.VideoFile, To: translation.VideoFile})
+	}
+	return blobs
+}
+
+// Reference clones the project along with its pdf slide images and video segments. Unlike
+// Copy, the clone refers to the same pdf files, scripts, slide images, audio and music as the
+// project so that no files need to be copied. Generated videos are not carried over
+//
+// The project is expected to have its pdf slide images and video segments already loaded
+// The project is to be updated with ShareBlobs if it was not sharing its blobs before
+func (p *Project) Reference(name string) Project {
+	newProject := p.Clone(name)
+	newProject.Music.File = p.Music.File
+	newProject.BlobSourceID = p.BlobSourceID
+	if newProject.BlobSourceID == "" {
+		newProject.BlobSourceID = p.ID
+	}
+	for _, s := range p.PDFSlideImages {
+		newSlideImages := s.Clone(newProject.ID)
+		newSlideImages.PDFFile = s.PDFFile
+		newSlideImages.ScriptFile = s.ScriptFile
+		for i, a := range s.SlideAssets {
+			newSlideImages.SlideAssets[i].ImageID = a.ImageID
+		}
+		newProject.PDFSlideImages = append(newProject.PDFSlideImages, newSlideImages)
+	}
+	for _, v := range p.VideoSegments {
+		newProject.VideoSegments = append(newProject.VideoSegments, v.Clone(newProject.ID))
+	}
+	return newProject
+}
+
+// SharedBlobs provides the blobs of the project that other projects still refer to. These
+// are to be kept when the blobs of the project are removed. Trashed projects that have yet
+// to be purged are counted as well
+func SharedBlobs(ctx context.Context, store Store, p Project, folders Folders) (map[string]bool, error) {
+	shared := map[string]bool{}
+	if p.BlobSourceID == "" {
+		return shared, nil
+	}
+	projects, err := store.GetAllSharingBlobs(ctx, p.BlobSourceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range projects {
+		if other.ID == p.ID {
+			continue
+		}
+		// The pdf slide images and video segments of the project are needed to find its blobs
+		other, err = store.Get(ctx, other.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range other.Blobs(folders) {
+			shared[b.Path] = true
+		}
+	}
+	return shared, nil
+}
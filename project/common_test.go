@@ -67,7 +67,7 @@ func common_ops(t *testing.T, projectStore Store, pdfDB pdfslideimages.Store, ac
 	}
 
 	// Get number of records
-	projectCount, err := projectStore.Count(context.TODO(), "1111")
+	projectCount, err := projectStore.Count(context.TODO(), "1111", Filter{})
 	if err != nil {
 		t.Fatalf("Failed to retrieve record from mysql database. Err: %v", err)
 	}
@@ -82,7 +82,7 @@ func common_ops(t *testing.T, projectStore Store, pdfDB pdfslideimages.Store, ac
 	}
 
 	// Get all records
-	projects, err := projectStore.GetAll(context.TODO(), "1111", 10, 0)
+	projects, _, err := projectStore.GetAll(context.TODO(), "1111", DefaultListOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error when retrieving all records. Err: %v", err)
 	}
@@ -124,7 +124,7 @@ func common_ops(t *testing.T, projectStore Store, pdfDB pdfslideimages.Store, ac
 	}
 
 	// Get all records
-	projects, err = projectStore.GetAll(context.TODO(), "1111", 10, 0)
+	projects, _, err = projectStore.GetAll(context.TODO(), "1111", DefaultListOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error when retrieving all records. Err: %v", err)
 	}
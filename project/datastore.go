@@ -12,6 +12,8 @@ import (
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 )
 
+const maxDeleteBatch = 500
+
 type googleDatastore struct {
 	logger                   logger.Logger
 	entityName               string
@@ -28,6 +30,7 @@ func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, en, pdfslide
 		entityName:               en,
 		pdfSlideImagesEntityName: pdfslideimagesEn,
 		videoSegmentEntityName:   videoSegmentEn,
+		aclEntityName:            "acl",
 	}
 	return &datastore
 }
@@ -102,41 +105,142 @@ func (g *googleDatastore) Update(ctx context.Context, ID string, setters ...func
 	return project, nil
 }
 
-func (g *googleDatastore) GetAll(ctx context.Context, userID string, limit, after int) ([]Project, error) {
+func (g *googleDatastore) GetAll(ctx context.Context, userID string, opts ListOptions) ([]Project, string, error) {
+	projects, err := g.userProjects(ctx, userID, opts.TeamIDs)
+	if err != nil {
+		return []Project{}, "", err
+	}
+	return applyListOptions(projects, opts)
+}
+
+// userProjects retrieves all projects that the user and the teams of the user have access to
+// Datastore is unable to join the acls to projects, so filtering and sorting happens in memory
+func (g *googleDatastore) userProjects(ctx context.Context, userID string, teamIDs []string) ([]Project, error) {
+	queries := []*datastore.Query{datastore.NewQuery(g.aclEntityName).Filter("UserID =", userID)}
+	for _, teamID := range teamIDs {
+		queries = append(queries, datastore.NewQuery(g.aclEntityName).Filter("UserID =", "").Filter("TeamID =", teamID))
+	}
 	acls := []acl.ACL{}
-	query := datastore.NewQuery(g.aclEntityName)
-	query = query.Limit(limit)
-	query = query.Offset(after)
-	query = query.Filter("UserID =", userID)
-	keys, err := g.client.GetAll(ctx, query, &acls)
+	for _, query := range queries {
+		_, err := g.client.GetAll(ctx, query, &acls)
+		if err != nil {
+			return []Project{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+		}
+	}
+
+	keys := []*datastore.Key{}
+	seen := map[string]bool{}
+	for _, a := range acls {
+		if seen[a.ProjectID] {
+			continue
+		}
+		seen[a.ProjectID] = true
+		keys = append(keys, datastore.NameKey(g.entityName, a.ProjectID, nil))
+	}
+	found := make([]Project, len(keys))
+	err := g.client.GetMulti(ctx, keys, found)
+	// Acls can outlive their projects, so projects that no longer exist are left out
+	multiErr, isMultiErr := err.(datastore.MultiError)
+	if err != nil && !isMultiErr {
+		return []Project{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	projects := []Project{}
+	for i, key := range keys {
+		if isMultiErr && multiErr[i] != nil {
+			if multiErr[i] == datastore.ErrNoSuchEntity {
+				continue
+			}
+			return []Project{}, fmt.Errorf("unable to retrieve all results. err: %v", multiErr[i])
+		}
+		found[i].ID = key.Name
+		projects = append(projects, found[i])
+	}
+	return projects, nil
+}
+
+func (g *googleDatastore) GetAllInFolder(ctx context.Context, folderID string) ([]Project, error) {
+	projects := []Project{}
+	query := datastore.NewQuery(g.entityName).Filter("FolderID =", folderID)
+	keys, err := g.client.GetAll(ctx, query, &projects)
 	if err != nil {
 		return []Project{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
 	}
+	for i, key := range keys {
+		projects[i].ID = key.Name
+	}
+	return projects, nil
+}
 
+func (g *googleDatastore) GetAllSharingBlobs(ctx context.Context, blobSourceID string) ([]Project, error) {
 	projects := []Project{}
-	err = g.client.GetMulti(ctx, keys, &projects)
+	query := datastore.NewQuery(g.entityName).Filter("BlobSourceID =", blobSourceID)
+	keys, err := g.client.GetAll(ctx, query, &projects)
 	if err != nil {
 		return []Project{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
 	}
+	for i, key := range keys {
+		projects[i].ID = key.Name
+	}
 	return projects, nil
 }
 
+func (g *googleDatastore) GetAllTrashed(ctx context.Context, deletedBefore time.Time, limit int) ([]Project, error) {
+	projects := []Project{}
+	query := datastore.NewQuery(g.entityName)
+	query = query.Filter("DeletedAt <", deletedBefore)
+	query = query.Limit(limit)
+	keys, err := g.client.GetAll(ctx, query, &projects)
+	if err != nil {
+		return []Project{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	trashed := []Project{}
+	for i, key := range keys {
+		projects[i].ID = key.Name
+		if projects[i].IsTrashed() {
+			trashed = append(trashed, projects[i])
+		}
+	}
+	return trashed, nil
+}
+
+// Delete removes the project along with all child entities stored under it as well as its acls
 func (g *googleDatastore) Delete(ctx context.Context, ID string) error {
 	key := datastore.NameKey(g.entityName, ID, nil)
-	err := g.client.Delete(ctx, key)
+	query := datastore.NewQuery("").Ancestor(key).KeysOnly()
+	keys, err := g.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve child records of project. err: %v", err)
+	}
+	aclKeys, err := g.client.GetAll(ctx, datastore.NewQuery(g.aclEntityName).Filter("ProjectID =", ID).KeysOnly(), nil)
 	if err != nil {
-		return fmt.Errorf("unable to delete project. err: %v", err)
+		return fmt.Errorf("unable to retrieve acls of project. err: %v", err)
+	}
+	keys = append(keys, aclKeys...)
+	// Ancestor query includes the project itself
+	// Datastore limits the number of entities that can be deleted in a single call
+	for start := 0; start < len(keys); start = start + maxDeleteBatch {
+		end := start + maxDeleteBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		err = g.client.DeleteMulti(ctx, keys[start:end])
+		if err != nil {
+			return fmt.Errorf("unable to delete project. err: %v", err)
+		}
 	}
 	return nil
 }
 
-func (g *googleDatastore) Count(ctx context.Context, UserID string) (int, error) {
-	projects := []Project{}
-	query := datastore.NewQuery(g.entityName)
-	query = query.KeysOnly()
-	keys, err := g.client.GetAll(ctx, query, &projects)
+func (g *googleDatastore) Count(ctx context.Context, userID string, f Filter) (int, error) {
+	projects, err := g.userProjects(ctx, userID, f.TeamIDs)
 	if err != nil {
-		return 0, fmt.Errorf("unable to retrieve all results. err: %v", err)
+		return 0, err
+	}
+	count := 0
+	for _, p := range projects {
+		if f.matches(p) {
+			count = count + 1
+		}
 	}
-	return len(keys), nil
+	return count, nil
 }
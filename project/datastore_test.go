@@ -45,4 +45,17 @@ func Test_datastore_ops(t *testing.T) {
 	aclDB, _ := acl.NewGoogleDatastore(logger.LoggerForTests{Tester: t}, xClient, "acl")
 
 	common_ops(t, projectStore, pdfDB, aclDB)
+
+	// Acls of projects that no longer exist are skipped
+	err = aclDB.Create(context.TODO(), acl.New("9999", "1111"))
+	if err != nil {
+		t.Fatalf("Failed to create record in datastore. Err: %v", err)
+	}
+	projects, _, err := projectStore.GetAll(context.TODO(), "1111", DefaultListOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error when retrieving all records. Err: %v", err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("Unexpected no of projects. Projects: %+v", projects)
+	}
 }
@@ -0,0 +1,107 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
+
+// MaxLanguages is the maximum number of additional languages of a project
+const MaxLanguages = 10
+
+// ProjectLanguage is an additional language that the project is narrated in
+// The primary language of the project is the language of the voice in the project settings
+// and its scripts and videos are kept in the fields of the video segments themselves
+type ProjectLanguage struct {
+	ProjectID string         `json:"-" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	Code      string         `json:"code" gorm:"type:varchar(20);primary_key"`
+	Voice     settings.Voice `json:"voice" gorm:"embedded;embedded_prefix:voice_"`
+}
+
+// PrimaryLanguage is the language code of the voice used for the scripts of the video segments
+func (p *Project) PrimaryLanguage() string {
+	return settings.Effective(p.Settings, settings.Voice{}).Voice.LanguageCode
+}
+
+// IsPrimaryLanguage checks if the language refers to the primary language of the project
+// An empty language is treated as the primary language
+func (p *Project) IsPrimaryLanguage(code string) bool {
+	return code == "" || strings.EqualFold(code, p.PrimaryLanguage())
+}
+
+// Language retrieves an additional language of the project
+func (p *Project) Language(code string) (ProjectLanguage, bool) {
+	for _, l := range p.Languages {
+		if strings.EqualFold(l.Code, code) {
+			return l, true
+		}
+	}
+	return ProjectLanguage{}, false
+}
+
+// LanguageCodes lists the primary language followed by the additional languages of the project
+func (p *Project) LanguageCodes() []string {
+	codes := []string{p.PrimaryLanguage()}
+	for _, l := range p.Languages {
+		codes = append(codes, l.Code)
+	}
+	return codes
+}
+
+// LanguageSettings are the project settings to be used to generate videos in the language
+// The voice of an additional language starts from the project voice without the voice name,
+// as voice names are specific to a language, and is then replaced with the voice of the language
+func (p *Project) LanguageSettings(code string) (settings.Settings, error) {
+	if p.IsPrimaryLanguage(code) {
+		return p.Settings, nil
+	}
+	l, ok := p.Language(code)
+	if !ok {
+		return settings.Settings{}, fmt.Errorf("language %v is not part of project", code)
+	}
+	s := p.Settings
+	base := s.Voice
+	base.Name = ""
+	s.Voice = base.Merge(l.Voice)
+	s.Voice.LanguageCode = l.Code
+	return s, nil
+}
+
+// SetLanguages replaces the additional languages of the project
+func SetLanguages(languages []ProjectLanguage) ([]func(*Project) error, error) {
+	if len(languages) > MaxLanguages {
+		return nil, fmt.Errorf("project cannot have more than %v additional languages", MaxLanguages)
+	}
+	cleaned := []ProjectLanguage{}
+	seen := map[string]bool{}
+	for _, l := range languages {
+		l.Code = strings.TrimSpace(l.Code)
+		if l.Code == "" {
+			return nil, fmt.Errorf("language code cannot be empty")
+		}
+		if seen[strings.ToLower(l.Code)] {
+			return nil, fmt.Errorf("language %v is listed more than once", l.Code)
+		}
+		seen[strings.ToLower(l.Code)] = true
+		l.Voice = l.Voice.Normalize()
+		l.Voice.LanguageCode = l.Code
+		err := l.Voice.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid voice for language %v. %v", l.Code, err)
+		}
+		cleaned = append(cleaned, l)
+	}
+	var setters []func(*Project) error
+	setters = append(setters, func(a *Project) error {
+		for i, l := range cleaned {
+			if a.IsPrimaryLanguage(l.Code) {
+				return fmt.Errorf("language %v is already the primary language of the project", l.Code)
+			}
+			cleaned[i].ProjectID = a.ID
+		}
+		a.Languages = cleaned
+		return nil
+	})
+	return setters, nil
+}
@@ -0,0 +1,258 @@
+package project
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SortByName         = "name"
+	SortByDateCreated  = "date_created"
+	SortByDateModified = "date_modified"
+)
+
+var statuses = map[status]bool{
+	created:             true,
+	running:             true,
+	completed:           true,
+	errorStatus:         true,
+	splittingPDF:        true,
+	generatingSegment:   true,
+	concatenatingVideos: true,
+}
+
+// Filter narrows down the projects that are listed or counted for a user
+// Zero values are ignored, so an empty filter matches all projects of the user that are not
+// in the trash. Trashed lists the projects in the trash instead. TeamIDs are the teams of the
+// user; projects that the teams have access to are included with the projects of the user
+type Filter struct {
+	TeamIDs        []string
+	Trashed        bool
+	FolderID       string
+	Tag            string
+	Status         string
+	Name           string
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+}
+
+// ListOptions controls the filtering, sorting and pagination of projects
+// Cursor is an alternative to Offset; it is returned from a previous GetAll call
+// and takes precedence if both are provided
+type ListOptions struct {
+	Filter
+	SortBy     string
+	Descending bool
+	Limit      int
+	Offset     int
+	Cursor     string
+}
+
+// MaxListLimit is the most projects that are listed at a time. It limits the size of a page but
+// not the work done to produce it on Google Datastore - Datastore is unable to join the acls of
+// the user to projects, so all the projects of the user are loaded and then filtered, sorted and
+// paged in memory by applyListOptions. Listing slows down with the number of projects of the user
+const MaxListLimit = 100
+
+// DefaultListOptions lists the most recently created projects first
+func DefaultListOptions() ListOptions {
+	return ListOptions{
+		SortBy:     SortByDateCreated,
+		Descending: true,
+		Limit:      10,
+	}
+}
+
+func (f Filter) Validate() error {
+	if f.Status != "" && !statuses[status(f.Status)] {
+		return fmt.Errorf("invalid project status %v", f.Status)
+	}
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && f.CreatedAfter.After(f.CreatedBefore) {
+		return fmt.Errorf("created after date is later than created before date")
+	}
+	if !f.ModifiedAfter.IsZero() && !f.ModifiedBefore.IsZero() && f.ModifiedAfter.After(f.ModifiedBefore) {
+		return fmt.Errorf("modified after date is later than modified before date")
+	}
+	return nil
+}
+
+func (o ListOptions) Validate() error {
+	err := o.Filter.Validate()
+	if err != nil {
+		return err
+	}
+	switch o.SortBy {
+	case SortByName, SortByDateCreated, SortByDateModified:
+	default:
+		return fmt.Errorf("invalid sort field %v. Only name, date_created and date_modified are supported", o.SortBy)
+	}
+	if o.Limit < 1 {
+		return fmt.Errorf("limit needs to be at least 1")
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("offset cannot be negative")
+	}
+	if o.Cursor != "" {
+		_, err = decodeCursor(o.Cursor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f Filter) matches(p Project) bool {
+	if p.IsTrashed() != f.Trashed {
+		return false
+	}
+	if f.FolderID != "" && p.FolderID != f.FolderID {
+		return false
+	}
+	if f.Tag != "" && !p.HasTag(f.Tag) {
+		return false
+	}
+	if f.Status != "" && string(p.Status) != f.Status {
+		return false
+	}
+	if f.Name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && p.DateCreated.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && p.DateCreated.After(f.CreatedBefore) {
+		return false
+	}
+	if !f.ModifiedAfter.IsZero() && p.DateModified.Before(f.ModifiedAfter) {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && p.DateModified.After(f.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// cursor marks the last project of a page by its sort value and ID
+// The ID breaks ties between projects with the same sort value
+type cursor struct {
+	Name string    `json:"name,omitempty"`
+	Date time.Time `json:"date,omitempty"`
+	ID   string    `json:"id"`
+}
+
+func cursorFor(p Project, sortBy string) cursor {
+	c := cursor{ID: p.ID}
+	switch sortBy {
+	case SortByName:
+		c.Name = p.Name
+	case SortByDateCreated:
+		c.Date = p.DateCreated
+	case SortByDateModified:
+		c.Date = p.DateModified
+	}
+	return c
+}
+
+func (c cursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(raw string) (cursor, error) {
+	c := cursor{}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor")
+	}
+	err = json.Unmarshal(decoded, &c)
+	if err != nil || c.ID == "" {
+		return c, fmt.Errorf("invalid cursor")
+	}
+	return c, nil
+}
+
+// compare orders a project against the cursor position based on the sort field
+// Returns a negative number if the project comes before the cursor in ascending order
+func (c cursor) compare(p Project, sortBy string) int {
+	result := 0
+	switch sortBy {
+	case SortByName:
+		result = strings.Compare(p.Name, c.Name)
+	case SortByDateCreated:
+		result = compareTime(p.DateCreated, c.Date)
+	case SortByDateModified:
+		result = compareTime(p.DateModified, c.Date)
+	}
+	if result != 0 {
+		return result
+	}
+	return strings.Compare(p.ID, c.ID)
+}
+
+func compareTime(a, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	}
+	if a.After(b) {
+		return 1
+	}
+	return 0
+}
+
+// nextCursor provides the cursor for the page after the retrieved projects
+// An empty cursor is returned once there are no more projects to page through
+func nextCursor(projects []Project, o ListOptions) string {
+	if len(projects) == 0 || len(projects) < o.Limit {
+		return ""
+	}
+	return cursorFor(projects[len(projects)-1], o.SortBy).encode()
+}
+
+// applyListOptions filters, sorts and paginates projects in memory for stores
+// that are unable to do so as part of their queries
+func applyListOptions(projects []Project, o ListOptions) ([]Project, string, error) {
+	matched := []Project{}
+	for _, p := range projects {
+		if o.Filter.matches(p) {
+			matched = append(matched, p)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		result := cursorFor(matched[j], o.SortBy).compare(matched[i], o.SortBy)
+		if o.Descending {
+			return result > 0
+		}
+		return result < 0
+	})
+
+	start := o.Offset
+	if o.Cursor != "" {
+		c, err := decodeCursor(o.Cursor)
+		if err != nil {
+			return []Project{}, "", err
+		}
+		start = len(matched)
+		for i, p := range matched {
+			result := c.compare(p, o.SortBy)
+			if (o.Descending && result < 0) || (!o.Descending && result > 0) {
+				start = i
+				break
+			}
+		}
+	}
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + o.Limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	page := matched[start:end]
+	return page, nextCursor(page, o), nil
+}
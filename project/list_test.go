@@ -0,0 +1,124 @@
+package project
+
+import (
+	"testing"
+	"time"
+)
+
+func projectIDs(projects []Project) []string {
+	ids := []string{}
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func Test_applyListOptions(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	projects := []Project{
+		{ID: "a", Name: "Quarterly review", Status: completed, DateCreated: base, DateModified: base.Add(4 * time.Hour), FolderID: "f1", Tags: []string{"Finance"}},
+		{ID: "b", Name: "Onboarding", Status: created, DateCreated: base.Add(time.Hour), DateModified: base.Add(time.Hour)},
+		{ID: "c", Name: "Weekly review", Status: completed, DateCreated: base.Add(2 * time.Hour), DateModified: base.Add(2 * time.Hour)},
+		{ID: "d", Name: "Annual review", Status: running, DateCreated: base.Add(2 * time.Hour), DateModified: base.Add(3 * time.Hour)},
+	}
+
+	opts := DefaultListOptions()
+	items, _, err := applyListOptions(projects, opts)
+	if err != nil {
+		t.Fatalf("unexpected error. Err: %v", err)
+	}
+	if got := projectIDs(items); len(got) != 4 || got[0] != "d" || got[1] != "c" || got[3] != "a" {
+		t.Errorf("unexpected default order. Items: %v", got)
+	}
+
+	opts = DefaultListOptions()
+	opts.Name = "REVIEW"
+	opts.Status = string(completed)
+	opts.SortBy = SortByName
+	opts.Descending = false
+	items, _, _ = applyListOptions(projects, opts)
+	if got := projectIDs(items); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("unexpected filtered projects. Items: %v", got)
+	}
+
+	opts = DefaultListOptions()
+	opts.ModifiedAfter = base.Add(150 * time.Minute)
+	opts.SortBy = SortByDateModified
+	items, _, _ = applyListOptions(projects, opts)
+	if got := projectIDs(items); len(got) != 2 || got[0] != "a" || got[1] != "d" {
+		t.Errorf("unexpected projects within date range. Items: %v", got)
+	}
+
+	opts = DefaultListOptions()
+	opts.Tag = "finance"
+	items, _, _ = applyListOptions(projects, opts)
+	if got := projectIDs(items); len(got) != 1 || got[0] != "a" {
+		t.Errorf("unexpected projects with tag. Items: %v", got)
+	}
+	opts = DefaultListOptions()
+	opts.FolderID = "f2"
+	items, _, _ = applyListOptions(projects, opts)
+	if len(items) != 0 {
+		t.Errorf("unexpected projects in folder. Items: %v", projectIDs(items))
+	}
+
+	deletedAt := base.Add(5 * time.Hour)
+	trashed := append([]Project{{ID: "e", Name: "Old review", Status: completed, DateCreated: base, DateModified: base, DeletedAt: &deletedAt}}, projects...)
+	items, _, _ = applyListOptions(trashed, DefaultListOptions())
+	if got := projectIDs(items); len(got) != 4 {
+		t.Errorf("expected trashed projects to be hidden. Items: %v", got)
+	}
+	opts = DefaultListOptions()
+	opts.Trashed = true
+	items, _, _ = applyListOptions(trashed, opts)
+	if got := projectIDs(items); len(got) != 1 || got[0] != "e" {
+		t.Errorf("expected only trashed projects. Items: %v", got)
+	}
+
+	// Paging through with cursors should list every project exactly once
+	opts = DefaultListOptions()
+	opts.Limit = 3
+	seen := []string{}
+	for i := 0; i < 3; i++ {
+		var next string
+		items, next, err = applyListOptions(projects, opts)
+		if err != nil {
+			t.Fatalf("unexpected error when paging. Err: %v", err)
+		}
+		seen = append(seen, projectIDs(items)...)
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+	if len(seen) != 4 || seen[0] != "d" || seen[1] != "c" || seen[2] != "b" || seen[3] != "a" {
+		t.Errorf("unexpected projects when paging with cursor. Items: %v", seen)
+	}
+}
+
+func TestListOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(o *ListOptions)
+		wantErr bool
+	}{
+		{name: "default", modify: func(o *ListOptions) {}},
+		{name: "bad status", modify: func(o *ListOptions) { o.Status = "unknown" }, wantErr: true},
+		{name: "bad sort", modify: func(o *ListOptions) { o.SortBy = "status" }, wantErr: true},
+		{name: "bad cursor", modify: func(o *ListOptions) { o.Cursor = "not-a-cursor" }, wantErr: true},
+		{name: "bad limit", modify: func(o *ListOptions) { o.Limit = 0 }, wantErr: true},
+		{name: "reversed range", modify: func(o *ListOptions) {
+			o.CreatedAfter = time.Now()
+			o.CreatedBefore = time.Now().Add(-time.Hour)
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := DefaultListOptions()
+			tt.modify(&o)
+			if err := o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package project
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
-	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
@@ -29,12 +31,96 @@ func (m mysql) Create(ctx context.Context, e Project) error {
 	if result.Error != nil {
 		return result.Error
 	}
+	err := m.saveTags(e)
+	if err != nil {
+		return err
+	}
+	return m.saveLanguages(e)
+}
+
+// saveTags replaces the tags of the project in the tags table
+func (m mysql) saveTags(p Project) error {
+	result := m.db.Where("project_id = ?", p.ID).Delete(ProjectTag{})
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, t := range p.Tags {
+		result = m.db.Save(&ProjectTag{ProjectID: p.ID, Name: t})
+		if result.Error != nil {
+			return result.Error
+		}
+	}
 	return nil
 }
 
+// saveLanguages replaces the additional languages of the project in the languages table
+func (m mysql) saveLanguages(p Project) error {
+	result := m.db.Where("project_id = ?", p.ID).Delete(ProjectLanguage{})
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, l := range p.Languages {
+		l.ProjectID = p.ID
+		result = m.db.Save(&l)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func (m mysql) loadLanguages(projects []Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	ids := []string{}
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	var languages []ProjectLanguage
+	result := m.db.Where("project_id IN (?)", ids).Order("code").Find(&languages)
+	if result.Error != nil {
+		return result.Error
+	}
+	for i := range projects {
+		for _, l := range languages {
+			if l.ProjectID == projects[i].ID {
+				projects[i].Languages = append(projects[i].Languages, l)
+			}
+		}
+	}
+	return nil
+}
+
+func (m mysql) loadTags(projects []Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	ids := []string{}
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	var tags []ProjectTag
+	result := m.db.Where("project_id IN (?)", ids).Order("name").Find(&tags)
+	if result.Error != nil {
+		return result.Error
+	}
+	for i := range projects {
+		for _, t := range tags {
+			if t.ProjectID == projects[i].ID {
+				projects[i].Tags = append(projects[i].Tags, t.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Soft delete of projects is handled explicitly via the DeletedAt field, so project queries
+// are unscoped to avoid gorm from hiding trashed projects on its own
+
 func (m mysql) Get(ctx context.Context, ID string) (Project, error) {
 	p := Project{}
-	result := m.db.Where("id = ?", ID).First(&p)
+	result := m.db.Unscoped().Where("id = ?", ID).First(&p)
 	if result.Error != nil {
 		return p, result.Error
 	}
@@ -57,15 +143,130 @@ func (m mysql) Get(ctx context.Context, ID string) (Project, error) {
 	if result.Error != nil {
 		return p, result.Error
 	}
+	for k, v := range segments {
+		var translations []videosegment.Translation
+		result = m.db.Where("video_segment_id = ?", v.ID).Order("language").Find(&translations)
+		if result.Error != nil {
+			return p, result.Error
+		}
+		segments[k].Translations = translations
+	}
 	p.VideoSegments = segments
-	return p, nil
+	projects := []Project{p}
+	err := m.loadTags(projects)
+	if err != nil {
+		return p, err
+	}
+	err = m.loadLanguages(projects)
+	if err != nil {
+		return p, err
+	}
+	return projects[0], nil
 }
 
-func (m mysql) GetAll(ctx context.Context, UserID string, Limit, After int) ([]Project, error) {
+func (m mysql) GetAll(ctx context.Context, UserID string, opts ListOptions) ([]Project, string, error) {
 	var projects []Project
-	// result := m.db.Order("date_created desc").Limit(Limit).Offset(After).Find(&projects)
-	result := m.db.Model(&acl.ACL{}).Select("acls.project_id as id, projects.name, projects.date_created, projects.date_modified, projects.status").Where("user_id = ?", UserID).Joins("left join projects on acls.project_id = projects.id").Order("date_created desc").Limit(Limit).Offset(After).Scan(&projects)
-	m.logger.Error(projects)
+	query := m.filterQuery(UserID, opts.Filter)
+
+	column := "projects." + opts.SortBy
+	direction := "asc"
+	comparison := ">"
+	if opts.Descending {
+		direction = "desc"
+		comparison = "<"
+	}
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return []Project{}, "", err
+		}
+		var value interface{} = c.Date
+		if opts.SortBy == SortByName {
+			value = c.Name
+		}
+		query = query.Where(fmt.Sprintf("(%v %v ?) OR (%v = ? AND projects.id %v ?)", column, comparison, column, comparison), value, value, c.ID)
+	} else {
+		query = query.Offset(opts.Offset)
+	}
+
+	result := query.Select("projects.*").Order(fmt.Sprintf("%v %v, projects.id %v", column, direction, direction)).Limit(opts.Limit).Find(&projects)
+	if result.Error != nil {
+		return []Project{}, "", result.Error
+	}
+	err := m.loadTags(projects)
+	if err != nil {
+		return []Project{}, "", err
+	}
+	err = m.loadLanguages(projects)
+	if err != nil {
+		return []Project{}, "", err
+	}
+	return projects, nextCursor(projects, opts), nil
+}
+
+// filterQuery limits the projects to the ones that the user has access to and that matches the filter
+func (m mysql) filterQuery(UserID string, f Filter) *gorm.DB {
+	query := m.db.Unscoped().Model(&Project{})
+	if len(f.TeamIDs) > 0 {
+		query = query.Where("projects.id IN (SELECT project_id FROM acls WHERE user_id = ? OR (user_id = ? AND team_id IN (?)))", UserID, "", f.TeamIDs)
+	} else {
+		query = query.Where("projects.id IN (SELECT project_id FROM acls WHERE user_id = ?)", UserID)
+	}
+	if f.Trashed {
+		query = query.Where("projects.deleted_at IS NOT NULL")
+	} else {
+		query = query.Where("projects.deleted_at IS NULL")
+	}
+	if f.FolderID != "" {
+		query = query.Where("projects.folder_id = ?", f.FolderID)
+	}
+	// Tags are matched regardless of case, the same as HasTag, whatever the collation of the table
+	if f.Tag != "" {
+		query = query.Where("projects.id IN (SELECT project_id FROM project_tags WHERE LOWER(name) = LOWER(?))", f.Tag)
+	}
+	if f.Status != "" {
+		query = query.Where("projects.status = ?", f.Status)
+	}
+	if f.Name != "" {
+		escaper := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+		query = query.Where("projects.name LIKE ?", "%"+escaper.Replace(f.Name)+"%")
+	}
+	if !f.CreatedAfter.IsZero() {
+		query = query.Where("projects.date_created >= ?", f.CreatedAfter)
+	}
+	if !f.CreatedBefore.IsZero() {
+		query = query.Where("projects.date_created <= ?", f.CreatedBefore)
+	}
+	if !f.ModifiedAfter.IsZero() {
+		query = query.Where("projects.date_modified >= ?", f.ModifiedAfter)
+	}
+	if !f.ModifiedBefore.IsZero() {
+		query = query.Where("projects.date_modified <= ?", f.ModifiedBefore)
+	}
+	return query
+}
+
+func (m mysql) GetAllInFolder(ctx context.Context, FolderID string) ([]Project, error) {
+	var projects []Project
+	result := m.db.Unscoped().Where("folder_id = ?", FolderID).Find(&projects)
+	if result.Error != nil {
+		return []Project{}, result.Error
+	}
+	return projects, nil
+}
+
+func (m mysql) GetAllSharingBlobs(ctx context.Context, BlobSourceID string) ([]Project, error) {
+	var projects []Project
+	result := m.db.Unscoped().Where("blob_source_id = ?", BlobSourceID).Find(&projects)
+	if result.Error != nil {
+		return []Project{}, result.Error
+	}
+	return projects, nil
+}
+
+func (m mysql) GetAllTrashed(ctx context.Context, DeletedBefore time.Time, Limit int) ([]Project, error) {
+	var projects []Project
+	result := m.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", DeletedBefore).Order("deleted_at asc").Limit(Limit).Find(&projects)
 	if result.Error != nil {
 		return []Project{}, result.Error
 	}
@@ -74,34 +275,52 @@ func (m mysql) GetAll(ctx context.Context, UserID string, Limit, After int) ([]P
 
 func (m mysql) Update(ctx context.Context, ID string, setters ...func(*Project) error) (Project, error) {
 	var p Project
-	result := m.db.Where("id = ?", ID).First(&p)
+	result := m.db.Unscoped().Where("id = ?", ID).First(&p)
 	if result.Error != nil {
 		return Project{}, result.Error
 	}
+	projects := []Project{p}
+	err := m.loadTags(projects)
+	if err != nil {
+		return Project{}, err
+	}
+	err = m.loadLanguages(projects)
+	if err != nil {
+		return Project{}, err
+	}
+	p = projects[0]
 	for _, s := range setters {
 		err := s(&p)
 		if err != nil {
 			return Project{}, err
 		}
 	}
-	result = m.db.Save(&p)
+	result = m.db.Unscoped().Save(&p)
 	if result.Error != nil {
 		return Project{}, result.Error
 	}
+	err = m.saveTags(p)
+	if err != nil {
+		return Project{}, err
+	}
+	err = m.saveLanguages(p)
+	if err != nil {
+		return Project{}, err
+	}
 	return p, nil
 }
 
 func (m mysql) Delete(ctx context.Context, ID string) error {
-	result := m.db.Where("id = ?", ID).Delete(Project{})
+	result := m.db.Unscoped().Where("id = ?", ID).Delete(Project{})
 	if result.Error != nil {
 		return result.Error
 	}
 	return nil
 }
 
-func (m mysql) Count(ctx context.Context, UserID string) (int, error) {
+func (m mysql) Count(ctx context.Context, UserID string, f Filter) (int, error) {
 	var count int64
-	result := m.db.Model(&acl.ACL{}).Where("user_id = ?", UserID).Count(&count)
+	result := m.filterQuery(UserID, f).Count(&count)
 	if result.Error != nil {
 		return 0, result.Error
 	}
@@ -3,11 +3,14 @@ package project
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 )
 
@@ -35,6 +38,22 @@ type Project struct {
 	ACLs               []acl.ACL                       `json:"acls" datastore:"-" gorm:"-"`
 	SetRunningIdemKey  string                          `json:"-" gorm:"varchar(40)"`
 	CompleteRecIdemKey string                          `json:"-" gorm:"varchar(40)"`
+	DeletedAt          *time.Time                      `json:"deleted_at,omitempty"`
+	FolderID           string                          `json:"folder_id,omitempty" gorm:"type:varchar(40)"`
+	Tags               []string                        `json:"tags,omitempty" gorm:"-"`
+	Settings           settings.Settings               `json:"settings" gorm:"embedded;embedded_prefix:settings_"`
+	Languages          []ProjectLanguage               `json:"languages,omitempty" gorm:"-"`
+	Music              settings.Music                  `json:"music" gorm:"embedded;embedded_prefix:music_"`
+	TeamID             string                          `json:"team_id,omitempty" gorm:"type:varchar(40)"`
+	// BlobSourceID is the project whose blobs were first shared by cloning it. Projects with
+	// the same blob source may refer to the same blobs, see SharedBlobs
+	BlobSourceID string `json:"-" gorm:"type:varchar(40);index"`
+}
+
+// ProjectTag holds the tags of a project for databases that are unable to store lists in a column
+type ProjectTag struct {
+	ProjectID string `gorm:"type:varchar(40);primary_key"`
+	Name      string `gorm:"type:varchar(50);primary_key"`
 }
 
 func New() Project {
@@ -49,8 +68,70 @@ func New() Project {
 	}
 }
 
+// Clone creates a fresh project that copies over the details of the current project
+// Child resources (pdf slide images, video segments and acls) are not copied here
+// and would need to be recreated against the new project ID. Tags, settings, languages and
+// background music are copied over but the cloned project is not placed in any folder
+// The music file is given a new name owned by the cloned project. Use Copy to clone the
+// project along with its child resources and files
+func (p *Project) Clone(name string) Project {
+	newProject := New()
+	newProject.Name = name
+	if name == "" {
+		newProject.Name = "Copy of " + p.Name
+	}
+	newProject.Tags = append([]string{}, p.Tags...)
+	newProject.Settings = p.Settings
+	newProject.Music = p.Music
+	if p.Music.File != "" {
+		musicID, _ := uuid.NewV4()
+		newProject.Music.File = newProject.ID + "-music-" + musicID.String() + blobstorage.Ext(p.Music.File)
+	}
+	for _, l := range p.Languages {
+		l.ProjectID = newProject.ID
+		newProject.Languages = append(newProject.Languages, l)
+	}
+	return newProject
+}
+
+// VisibleVideoSegments returns the video segments that are not hidden
+// Hidden video segments are left out of video generation and concatenation
+func (p *Project) VisibleVideoSegments() []videosegment.VideoSegment {
+	videoSegments := []videosegment.VideoSegment{}
+	for _, v := range p.VideoSegments {
+		if !v.Hidden {
+			videoSegments = append(videoSegments, v)
+		}
+	}
+	return videoSegments
+}
+
+// HasTag checks if the project is labelled with the tag
+func (p *Project) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTrashed checks if the project has been moved to the trash
+// Trashed projects are hidden from listings until they are restored or purged
+func (p *Project) IsTrashed() bool {
+	return p.DeletedAt != nil
+}
+
+// PurgeAt is the time after which a trashed project can no longer be restored
+func (p *Project) PurgeAt(retention time.Duration) time.Time {
+	if p.DeletedAt == nil {
+		return time.Time{}
+	}
+	return p.DeletedAt.Add(retention)
+}
+
 func (p *Project) GetVideoSegmentList() ([]string, error) {
-	videoSegments := p.VideoSegments
+	videoSegments := p.VisibleVideoSegments()
 	sort.Sort(videosegment.ByOrder(videoSegments))
 	items := []string{}
 	for _, v := range videoSegments {
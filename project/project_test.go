@@ -0,0 +1,235 @@
+package project
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+func TestProject_GetVideoSegmentList(t *testing.T) {
+	p := New()
+	first := videosegment.New(p.ID, "image-0.png", 0)
+	first.VideoFile = "first.mp4"
+	hidden := videosegment.New(p.ID, "image-1.png", 1)
+	hidden.Hidden = true
+	last := videosegment.New(p.ID, "image-2.png", 2)
+	last.VideoFile = "last.mp4"
+	p.VideoSegments = []videosegment.VideoSegment{last, hidden, first}
+
+	items, err := p.GetVideoSegmentList()
+	if err != nil {
+		t.Fatalf("unexpected error when retrieving video segment list. Err: %v", err)
+	}
+	if !reflect.DeepEqual(items, []string{"first.mp4", "last.mp4"}) {
+		t.Errorf("unexpected video segment list. Items: %v", items)
+	}
+
+	p.VideoSegments = []videosegment.VideoSegment{hidden}
+	_, err = p.GetVideoSegmentList()
+	if err == nil {
+		t.Errorf("expected error when all video segments are hidden")
+	}
+}
+
+func TestProject_Trash(t *testing.T) {
+	p := New()
+	setters, _ := MoveToTrash()
+	for _, s := range setters {
+		if err := s(&p); err != nil {
+			t.Fatalf("unexpected error when moving project to trash. Err: %v", err)
+		}
+	}
+	if !p.IsTrashed() {
+		t.Fatalf("expected project to be in the trash")
+	}
+	if err := setters[0](&p); err == nil {
+		t.Errorf("expected error when moving a trashed project to trash")
+	}
+
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	p.DeletedAt = &deletedAt
+	setters, _ = RestoreFromTrash(24 * time.Hour)
+	if err := setters[0](&p); err == nil {
+		t.Errorf("expected error when restoring project beyond the retention period")
+	}
+
+	setters, _ = RestoreFromTrash(72 * time.Hour)
+	if err := setters[0](&p); err != nil {
+		t.Fatalf("unexpected error when restoring project. Err: %v", err)
+	}
+	if p.IsTrashed() {
+		t.Errorf("expected project to be restored")
+	}
+	if err := setters[0](&p); err == nil {
+		t.Errorf("expected error when restoring a project that is not in the trash")
+	}
+}
+
+func TestProject_SetTags(t *testing.T) {
+	p := New()
+	setters, err := SetTags([]string{" Marketing ", "marketing", "", "Q3"})
+	if err != nil {
+		t.Fatalf("unexpected error when setting tags. Err: %v", err)
+	}
+	setters[0](&p)
+	if !reflect.DeepEqual(p.Tags, []string{"Marketing", "Q3"}) {
+		t.Errorf("unexpected tags. Tags: %v", p.Tags)
+	}
+	if !p.HasTag("q3") {
+		t.Errorf("expected tags to be matched regardless of case")
+	}
+
+	tooMany := []string{}
+	for i := 0; i <= MaxTags; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("tag-%v", i))
+	}
+	if _, err = SetTags(tooMany); err == nil {
+		t.Errorf("expected error when setting more than %v tags", MaxTags)
+	}
+}
+
+func TestProject_LanguageSettings(t *testing.T) {
+	p := New()
+	p.Settings = settings.Settings{Voice: settings.Voice{LanguageCode: "en-GB", Name: "en-GB-Wavenet-A", Pitch: 2.0}}
+	setters, err := SetLanguages([]ProjectLanguage{
+		{Code: " fr-FR ", Voice: settings.Voice{Gender: "male"}},
+		{Code: "ja-JP"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error when setting languages. Err: %v", err)
+	}
+	if err = setters[0](&p); err != nil {
+		t.Fatalf("unexpected error when setting languages. Err: %v", err)
+	}
+	if !reflect.DeepEqual(p.LanguageCodes(), []string{"en-GB", "fr-FR", "ja-JP"}) {
+		t.Errorf("unexpected languages. Languages: %v", p.LanguageCodes())
+	}
+	if !p.IsPrimaryLanguage("") || !p.IsPrimaryLanguage("en-gb") || p.IsPrimaryLanguage("fr-FR") {
+		t.Errorf("unexpected primary language check")
+	}
+
+	s, err := p.LanguageSettings("fr-FR")
+	if err != nil {
+		t.Fatalf("unexpected error when retrieving language settings. Err: %v", err)
+	}
+	if s.Voice.LanguageCode != "fr-FR" || s.Voice.Name != "" || s.Voice.Gender != settings.GenderMale || s.Voice.Pitch != 2.0 {
+		t.Errorf("unexpected voice for language. Voice: %+v", s.Voice)
+	}
+	if _, err = p.LanguageSettings("de-DE"); err == nil {
+		t.Errorf("expected error for language that is not part of project")
+	}
+
+	setters, _ = SetLanguages([]ProjectLanguage{{Code: "en-GB"}})
+	if err = setters[0](&p); err == nil {
+		t.Errorf("expected error when adding the primary language as an additional language")
+	}
+	if _, err = SetLanguages([]ProjectLanguage{{Code: "fr-FR"}, {Code: "fr-fr"}}); err == nil {
+		t.Errorf("expected error when a language is listed more than once")
+	}
+}
+
+func TestProject_Clone(t *testing.T) {
+	p := New()
+	p.Name = "demo"
+	p.Tags = []string{"sales"}
+	p.Music.File = p.ID + "-music-1234.mp3"
+	p.Languages = []ProjectLanguage{{ProjectID: p.ID, Code: "fr-FR"}}
+
+	cloned := p.Clone("")
+	if cloned.ID == p.ID || cloned.Name != "Copy of demo" {
+		t.Fatalf("expected clone to be a new project named after the source. Clone: %+v", cloned)
+	}
+	if !reflect.DeepEqual(cloned.Tags, p.Tags) {
+		t.Errorf("expected tags to be copied. Tags: %v", cloned.Tags)
+	}
+	if cloned.Music.File == p.Music.File || !strings.HasPrefix(cloned.Music.File, cloned.ID) {
+		t.Errorf("expected music to be named after the clone. Music: %v", cloned.Music.File)
+	}
+	if len(cloned.Languages) != 1 || cloned.Languages[0].ProjectID != cloned.ID {
+		t.Errorf("expected languages to be copied to the clone. Languages: %+v", cloned.Languages)
+	}
+	if cloned := p.Clone("named"); cloned.Name != "named" {
+		t.Errorf("expected name of clone to be used. Name: %v", cloned.Name)
+	}
+}
+
+func TestProject_Copy(t *testing.T) {
+	p := New()
+	slideImages := pdfslideimages.New(p.ID)
+	slideImages.SlideAssets = []pdfslideimages.SlideAsset{{ImageID: slideImages.ID + "-0.png", Order: 0, PDFSlideImageID: slideImages.ID}}
+	p.PDFSlideImages = []pdfslideimages.PDFSlideImages{slideImages}
+	segment := videosegment.New(p.ID, slideImages.ID+"-0.png", 0)
+	segment.VideoFile = segment.ID + ".mp4"
+	p.VideoSegments = []videosegment.VideoSegment{segment}
+	p.VideoOutputID = p.ID + ".mp4"
+	folders := Folders{PDF: "pdf", Images: "images"}
+
+	copied, blobs := p.Copy("copy", folders, folders, false)
+	if len(copied.PDFSlideImages) != 1 || len(copied.VideoSegments) != 1 {
+		t.Fatalf("expected pdf slide images and video segments to be copied. Copy: %+v", copied)
+	}
+	copiedImage := copied.PDFSlideImages[0].SlideAssets[0].ImageID
+	if copied.VideoSegments[0].ImageID != copiedImage || copied.VideoSegments[0].ProjectID != copied.ID {
+		t.Errorf("expected video segment to refer to the copied image. Segment: %+v", copied.VideoSegments[0])
+	}
+	if copied.VideoSegments[0].VideoFile != "" || copied.VideoOutputID != "" {
+		t.Errorf("expected videos to not be copied. Copy: %+v", copied)
+	}
+	expected := []BlobCopy{
+		{From: "pdf/" + slideImages.PDFFile, To: "pdf/" + copied.PDFSlideImages[0].PDFFile},
+		{From: "images/" + slideImages.ID + "-0.png", To: "images/" + copiedImage},
+	}
+	if !reflect.DeepEqual(blobs, expected) {
+		t.Errorf("unexpected files to copy. Blobs: %+v", blobs)
+	}
+
+	copied, blobs = p.Copy("copy", folders, folders, true)
+	if copied.VideoSegments[0].VideoFile != copied.VideoSegments[0].ID+".mp4" || copied.VideoOutputID != copied.ID+".mp4" {
+		t.Errorf("expected videos to be named after the copy. Copy: %+v", copied)
+	}
+	if len(blobs) != 6 {
+		t.Errorf("expected videos and their subtitles to be copied. Blobs: %+v", blobs)
+	}
+}
+
+func TestProject_Reference(t *testing.T) {
+	p := New()
+	p.Music.File = p.ID + "-music-1234.mp3"
+	slideImages := pdfslideimages.New(p.ID)
+	slideImages.ScriptFile = slideImages.ID + "-script.txt"
+	slideImages.SlideAssets = []pdfslideimages.SlideAsset{{ImageID: slideImages.ID + "-0.png", Order: 0, PDFSlideImageID: slideImages.ID}}
+	p.PDFSlideImages = []pdfslideimages.PDFSlideImages{slideImages}
+	segment := videosegment.New(p.ID, slideImages.ID+"-0.png", 0)
+	segment.AudioID = "audio.mp3"
+	segment.VideoFile = segment.ID + ".mp4"
+	p.VideoSegments = []videosegment.VideoSegment{segment}
+	p.VideoOutputID = p.ID + ".mp4"
+
+	cloned := p.Reference("clone")
+	if cloned.ID == p.ID || cloned.BlobSourceID != p.ID {
+		t.Fatalf("expected clone to share the blobs of the project. Clone: %+v", cloned)
+	}
+	clonedSlideImages, clonedSegment := cloned.PDFSlideImages[0], cloned.VideoSegments[0]
+	if clonedSlideImages.ID == slideImages.ID || clonedSlideImages.ProjectID != cloned.ID || clonedSlideImages.SlideAssets[0].PDFSlideImageID != clonedSlideImages.ID {
+		t.Errorf("expected pdf slide images to be a new record of the clone. Pdf slide images: %+v", clonedSlideImages)
+	}
+	if clonedSlideImages.PDFFile != slideImages.PDFFile || clonedSlideImages.ScriptFile != slideImages.ScriptFile || clonedSlideImages.SlideAssets[0].ImageID != slideImages.SlideAssets[0].ImageID {
+		t.Errorf("expected pdf slide images to refer to the same files. Pdf slide images: %+v", clonedSlideImages)
+	}
+	if clonedSegment.ImageID != segment.ImageID || clonedSegment.AudioID != segment.AudioID || cloned.Music.File != p.Music.File {
+		t.Errorf("expected video segment and music to refer to the same files. Video segment: %+v Music: %v", clonedSegment, cloned.Music.File)
+	}
+	if clonedSegment.VideoFile != "" || cloned.VideoOutputID != "" {
+		t.Errorf("expected videos to not be carried over. Clone: %+v", cloned)
+	}
+
+	if again := cloned.Reference("again"); again.BlobSourceID != p.ID {
+		t.Errorf("expected clone of clone to share the blobs of the same source. Blob source: %v", again.BlobSourceID)
+	}
+}
@@ -4,16 +4,26 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 )
 
 type Store interface {
 	Create(ctx context.Context, e Project) error
 	Get(ctx context.Context, ID string) (Project, error)
-	GetAll(ctx context.Context, UserID string, Limit, After int) ([]Project, error)
-	Count(ctx context.Context, UserID string) (int, error)
+	// GetAll returns a page of projects that the user has access to along with the cursor for the next page
+	GetAll(ctx context.Context, UserID string, opts ListOptions) ([]Project, string, error)
+	Count(ctx context.Context, UserID string, f Filter) (int, error)
+	// GetAllInFolder returns all projects placed directly in the folder regardless of user access
+	GetAllInFolder(ctx context.Context, FolderID string) ([]Project, error)
+	// GetAllSharingBlobs returns all projects, including trashed ones, with the blob source
+	GetAllSharingBlobs(ctx context.Context, BlobSourceID string) ([]Project, error)
+	// GetAllTrashed returns projects across all users that were moved to the trash before the given time
+	GetAllTrashed(ctx context.Context, DeletedBefore time.Time, Limit int) ([]Project, error)
 	Update(ctx context.Context, ID string, setters ...func(*Project) error) (Project, error)
+	// Delete permanently removes the project. Use MoveToTrash updaters for a recoverable delete
 	Delete(ctx context.Context, ID string) error
 }
 
@@ -101,6 +111,133 @@ func clearCompleteRecIdemKey(idemKey string) func(*Project) error {
 	}
 }
 
+const (
+	MaxTags      = 20
+	MaxTagLength = 50
+)
+
+// SetTags replaces the tags of the project. Tags are trimmed and duplicates are dropped
+func SetTags(tags []string) ([]func(*Project) error, error) {
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		if len(t) > MaxTagLength {
+			return nil, fmt.Errorf("tag %v is longer than %v characters", t, MaxTagLength)
+		}
+		seen[strings.ToLower(t)] = true
+		cleaned = append(cleaned, t)
+	}
+	if len(cleaned) > MaxTags {
+		return nil, fmt.Errorf("project cannot have more than %v tags", MaxTags)
+	}
+	var setters []func(*Project) error
+	setters = append(setters, func(a *Project) error {
+		a.Tags = cleaned
+		return nil
+	})
+	return setters, nil
+}
+
+// SetSettings replaces the voice and render settings of the project
+// Unset values fall back to the defaults of the workers
+func SetSettings(s settings.Settings) ([]func(*Project) error, error) {
+	s = s.Normalize()
+	err := s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var setters []func(*Project) error
+	setters = append(setters, func(a *Project) error {
+		a.Settings = s
+		a.DateModified = time.Now()
+		return nil
+	})
+	return setters, nil
+}
+
+// SetMusic replaces the background music of the project. Music without a file removes
+// the background music of the project
+func SetMusic(m settings.Music) ([]func(*Project) error, error) {
+	err := m.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var setters []func(*Project) error
+	setters = append(setters, func(a *Project) error {
+		if m.File == "" {
+			m = settings.Music{}
+		}
+		a.Music = m
+		a.DateModified = time.Now()
+		return nil
+	})
+	return setters, nil
+}
+
+// MoveToFolder places the project in the folder. An empty folder ID moves the project out of all folders
+func MoveToFolder(folderID string) ([]func(*Project) error, error) {
+	var setters []func(*Project) error
+	setters = append(setters, func(a *Project) error {
+		a.FolderID = folderID
+		return nil
+	})
+	return setters, nil
+}
+
+// ShareBlobs marks the project as sharing its blobs with the projects that are cloned from it
+func ShareBlobs() ([]func(*Project) error, error) {
+	var setters []func(*Project) error
+	setters = append(setters, func(a *Project) error {
+		if a.BlobSourceID == "" {
+			a.BlobSourceID = a.ID
+		}
+		return nil
+	})
+	return setters, nil
+}
+
+// MoveToTrash marks the project as deleted. The project can be restored until it is purged
+func MoveToTrash() ([]func(*Project) error, error) {
+	var setters []func(*Project) error
+	setters = append(setters, setDeletedAt())
+	return setters, nil
+}
+
+// RestoreFromTrash brings back a trashed project as long as the retention window has not passed
+func RestoreFromTrash(retention time.Duration) ([]func(*Project) error, error) {
+	var setters []func(*Project) error
+	setters = append(setters, clearDeletedAt(retention))
+	return setters, nil
+}
+
+func setDeletedAt() func(*Project) error {
+	return func(a *Project) error {
+		if a.IsTrashed() {
+			return fmt.Errorf("project is already in the trash")
+		}
+		currentTime := time.Now()
+		a.DeletedAt = &currentTime
+		return nil
+	}
+}
+
+func clearDeletedAt(retention time.Duration) func(*Project) error {
+	return func(a *Project) error {
+		if !a.IsTrashed() {
+			return fmt.Errorf("project is not in the trash")
+		}
+		if time.Now().After(a.PurgeAt(retention)) {
+			return fmt.Errorf("project has been in the trash beyond the retention period and can no longer be restored")
+		}
+		a.DeletedAt = nil
+		return nil
+	}
+}
+
 func RegenerateIdemKeys() ([]func(*Project) error, error) {
 	var setters []func(*Project) error
 	setters = append(setters, recreateIdemKeys())
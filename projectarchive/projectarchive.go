@@ -0,0 +1,212 @@
+// Package projectarchive handles the packaging of a project into a portable zip archive
+// The archive can be used to move projects between environments (e.g. between a
+// Google Datastore deployment and a MySQL deployment) or to keep it for archival purposes
+package projectarchive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+// ManifestVersion is bumped whenever the layout of the manifest changes in
+// a way that older versions of the manager cannot import
+const ManifestVersion = 2
+
+// MaxArchiveSize is the largest archive that can be imported
+const MaxArchiveSize = 1 << 30
+
+// MaxBlobSize is the largest file that can be imported from an archive
+const MaxBlobSize = 256 << 20
+
+// maxImportedSize is the largest total size of the files imported from an archive
+const maxImportedSize = 4 << 30
+
+const manifestFileName = "manifest.json"
+const blobFolderName = "blobs/"
+
+// Manifest describes the project that is stored in the archive
+// Blobs are referenced by the path they had in blob storage when exported
+type Manifest struct {
+	Version        int                             `json:"version"`
+	DateExported   time.Time                       `json:"date_exported"`
+	Folders        project.Folders                 `json:"folders"`
+	Project        project.Project                 `json:"project"`
+	PDFSlideImages []pdfslideimages.PDFSlideImages `json:"pdf_slide_images"`
+	VideoSegments  []videosegment.VideoSegment     `json:"video_segments"`
+	Blobs          []project.Blob                  `json:"blobs"`
+}
+
+// NewManifest builds the manifest for the project. The project is expected to
+// have its pdf slide images and video segments already loaded
+func NewManifest(p project.Project, folders project.Folders) Manifest {
+	blobs := p.Blobs(folders)
+	pdfSlideImages := p.PDFSlideImages
+	videoSegments := p.VideoSegments
+	p.PDFSlideImages = nil
+	p.VideoSegments = nil
+	p.ACLs = nil
+
+	return Manifest{
+		Version:        ManifestVersion,
+		DateExported:   time.Now(),
+		Folders:        folders,
+		Project:        p,
+		PDFSlideImages: pdfSlideImages,
+		VideoSegments:  videoSegments,
+		Blobs:          blobs,
+	}
+}
+
+// Export creates a zip archive containing the manifest as well as all blobs referenced by it
+// Optional blobs that are not in blob storage are left out of the archive and the manifest
+func Export(ctx context.Context, storage blobstorage.BlobStorage, m Manifest) ([]byte, error) {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+
+	exported := []project.Blob{}
+	for _, blob := range m.Blobs {
+		content, err := storage.Load(ctx, blob.Path)
+		if err != nil && blob.Optional {
+			continue
+		}
+		if err != nil {
+			return []byte{}, fmt.Errorf("unable to load %v from blob storage. err: %v", blob.Path, err)
+		}
+		f, err := zw.Create(blobFolderName + blob.Path)
+		if err != nil {
+			return []byte{}, fmt.Errorf("unable to add %v to archive. err: %v", blob.Path, err)
+		}
+		f.Write(content)
+		exported = append(exported, blob)
+	}
+	m.Blobs = exported
+
+	rawManifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return []byte{}, fmt.Errorf("unable to marshal manifest. err: %v", err)
+	}
+	f, err := zw.Create(manifestFileName)
+	if err != nil {
+		return []byte{}, fmt.Errorf("unable to add manifest to archive. err: %v", err)
+	}
+	f.Write(rawManifest)
+
+	err = zw.Close()
+	if err != nil {
+		return []byte{}, fmt.Errorf("unable to finalize archive. err: %v", err)
+	}
+	return b.Bytes(), nil
+}
+
+// Import reads the zip archive and recreates the project within it as a new project
+// Every file in the archive is saved into blob storage under a new name owned by the new
+// project, so an archive can never replace the files of other projects. The new project
+// is returned with its pdf slide images and video segments for the caller to save
+func Import(ctx context.Context, storage blobstorage.BlobStorage, raw []byte, folders project.Folders) (project.Project, error) {
+	if len(raw) > MaxArchiveSize {
+		return project.Project{}, fmt.Errorf("archive is larger than %v bytes", MaxArchiveSize)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return project.Project{}, fmt.Errorf("unable to read archive. err: %v", err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	manifestFile, ok := files[manifestFileName]
+	if !ok {
+		return project.Project{}, fmt.Errorf("archive is missing %v", manifestFileName)
+	}
+	rawManifest, err := readZipFile(manifestFile)
+	if err != nil {
+		return project.Project{}, err
+	}
+	m := Manifest{}
+	err = json.Unmarshal(rawManifest, &m)
+	if err != nil {
+		return project.Project{}, fmt.Errorf("unable to parse manifest. err: %v", err)
+	}
+	if m.Version != ManifestVersion {
+		return project.Project{}, fmt.Errorf("unsupported manifest version %v", m.Version)
+	}
+
+	source := m.Project
+	source.PDFSlideImages = m.PDFSlideImages
+	source.VideoSegments = m.VideoSegments
+	importedProject, blobs := source.Copy(m.Project.Name, m.Folders, folders, true)
+
+	// Only the files referred to by the records of the project are accepted
+	referenced := map[string]bool{manifestFileName: true}
+	for _, b := range blobs {
+		referenced[blobFolderName+b.From] = true
+		if strings.Contains(b.To, "..") {
+			return project.Project{}, fmt.Errorf("invalid blob name %v in manifest", b.From)
+		}
+	}
+	for name := range files {
+		if !referenced[name] {
+			return project.Project{}, fmt.Errorf("archive contains %v which is not referred to by the manifest", name)
+		}
+	}
+
+	importedSize := 0
+	for _, b := range blobs {
+		f, ok := files[blobFolderName+b.From]
+		if !ok && b.Optional {
+			continue
+		}
+		if !ok {
+			return project.Project{}, fmt.Errorf("archive is missing blob %v", b.From)
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return project.Project{}, err
+		}
+		importedSize = importedSize + len(content)
+		if importedSize > maxImportedSize {
+			return project.Project{}, fmt.Errorf("files in archive are larger than %v bytes in total", maxImportedSize)
+		}
+		err = storage.Save(ctx, b.To, content)
+		if err != nil {
+			return project.Project{}, fmt.Errorf("unable to save %v into blob storage. err: %v", b.To, err)
+		}
+	}
+
+	return importedProject, nil
+}
+
+// readZipFile reads the file within the archive. Files are read up to MaxBlobSize as the
+// sizes recorded in the archive cannot be trusted
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > MaxBlobSize {
+		return []byte{}, fmt.Errorf("%v in archive is larger than %v bytes", f.Name, MaxBlobSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return []byte{}, fmt.Errorf("unable to open %v in archive. err: %v", f.Name, err)
+	}
+	defer rc.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(rc, MaxBlobSize+1))
+	if err != nil {
+		return []byte{}, fmt.Errorf("unable to read %v in archive. err: %v", f.Name, err)
+	}
+	if len(content) > MaxBlobSize {
+		return []byte{}, fmt.Errorf("%v in archive is larger than %v bytes", f.Name, MaxBlobSize)
+	}
+	return content, nil
+}
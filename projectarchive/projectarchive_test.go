@@ -0,0 +1,147 @@
+package projectarchive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/subtitles"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type memoryStorage map[string][]byte
+
+func (m memoryStorage) Save(ctx context.Context, fileName string, content []byte) error {
+	m[fileName] = content
+	return nil
+}
+
+func (m memoryStorage) Load(ctx context.Context, fileName string) ([]byte, error) {
+	content, ok := m[fileName]
+	if !ok {
+		return []byte{}, fmt.Errorf("%v not found", fileName)
+	}
+	return content, nil
+}
+
+func (m memoryStorage) Delete(ctx context.Context, fileName string) error {
+	delete(m, fileName)
+	return nil
+}
+
+func TestExportImport(t *testing.T) {
+	p := project.New()
+	p.Name = "quarterly update"
+	p.VideoOutputID = "output.mp4"
+	p.PDFSlideImages = []pdfslideimages.PDFSlideImages{
+		{
+			ID:          "pdf1",
+			PDFFile:     "pdf1.pdf",
+			SlideAssets: []pdfslideimages.SlideAsset{{ImageID: "pdf1-0.png", Order: 0}},
+		},
+	}
+	p.Music.File = "music.mp3"
+	segment := videosegment.New(p.ID, "pdf1-0.png", 0)
+	segment.Script = "hello world"
+	segment.VideoFile = "segment.mp4"
+	segment.Translations = []videosegment.Translation{{Language: "ms", Script: "selamat pagi", VideoFile: "segment-ms.mp4"}}
+	p.VideoSegments = []videosegment.VideoSegment{segment}
+
+	// Subtitles in srt format were not generated for the video
+	source := memoryStorage{
+		"pdf/pdf1.pdf":      []byte("pdf"),
+		"images/pdf1-0.png": []byte("png"),
+		"segment.mp4":       []byte("segment"),
+		"segment-ms.mp4":    []byte("segment in malay"),
+		"output.mp4":        []byte("output"),
+		"output.vtt":        []byte("subtitles"),
+		"music.mp3":         []byte("music"),
+	}
+
+	m := NewManifest(p, project.Folders{PDF: "pdf", Images: "images"})
+	if len(m.Blobs) != 8 {
+		t.Fatalf("Unexpected number of blobs in manifest. Blobs: %v", m.Blobs)
+	}
+
+	raw, err := Export(context.TODO(), source, m)
+	if err != nil {
+		t.Fatalf("Unexpected error during export. Err: %v", err)
+	}
+
+	// Blobs of other projects with the names in the archive are left alone
+	target := memoryStorage{"slides/pdf1.pdf": []byte("other"), "segment.mp4": []byte("other")}
+	imported, err := Import(context.TODO(), target, raw, project.Folders{PDF: "slides", Images: "images"})
+	if err != nil {
+		t.Fatalf("Unexpected error during import. Err: %v", err)
+	}
+	if imported.ID == p.ID || imported.Name != p.Name {
+		t.Errorf("Unexpected imported project. Project: %+v", imported)
+	}
+	if string(target["slides/pdf1.pdf"]) != "other" || string(target["segment.mp4"]) != "other" {
+		t.Errorf("Expected blobs of other projects to be kept. Storage: %v", target)
+	}
+	if len(imported.PDFSlideImages) != 1 || len(imported.VideoSegments) != 1 || imported.VideoSegments[0].Script != "hello world" {
+		t.Fatalf("Unexpected records of imported project. Project: %+v", imported)
+	}
+	slideImages := imported.PDFSlideImages[0]
+	importedSegment := imported.VideoSegments[0]
+	restored := map[string]string{
+		"slides/" + slideImages.PDFFile:                   "pdf",
+		"images/" + slideImages.SlideAssets[0].ImageID:    "png",
+		"images/" + importedSegment.ImageID:               "png",
+		importedSegment.VideoFile:                         "segment",
+		importedSegment.Translations[0].VideoFile:         "segment in malay",
+		imported.VideoOutputID:                            "output",
+		subtitles.FileName(imported.VideoOutputID, "vtt"): "subtitles",
+		imported.Music.File:                               "music",
+	}
+	for name, content := range restored {
+		if string(target[name]) != content {
+			t.Errorf("Blob %v not restored. Expected %v Actual %v", name, content, string(target[name]))
+		}
+	}
+	if importedSegment.ImageID != slideImages.SlideAssets[0].ImageID || importedSegment.VideoFile != importedSegment.ID+".mp4" || imported.VideoOutputID != imported.ID+".mp4" {
+		t.Errorf("Expected references to be rewritten to the new blobs. Project: %+v", imported)
+	}
+
+	if _, ok := target[subtitles.FileName(imported.VideoOutputID, "srt")]; ok || len(target) != len(source)+2 {
+		t.Errorf("Unexpected blobs after import. Storage: %v", target)
+	}
+
+	_, err = Export(context.TODO(), memoryStorage{}, m)
+	if err == nil {
+		t.Errorf("Expected export to fail when blobs are missing")
+	}
+}
+
+func TestImport_UnreferencedEntries(t *testing.T) {
+	p := project.New()
+	source := memoryStorage{}
+	raw, err := Export(context.TODO(), source, NewManifest(p, project.Folders{PDF: "pdf", Images: "images"}))
+	if err != nil {
+		t.Fatalf("Unexpected error during export. Err: %v", err)
+	}
+
+	// Add an entry that the manifest does not refer to
+	zr, _ := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, f := range zr.File {
+		content, _ := readZipFile(f)
+		w, _ := zw.Create(f.Name)
+		w.Write(content)
+	}
+	w, _ := zw.Create(blobFolderName + "pdf/other.pdf")
+	w.Write([]byte("other"))
+	zw.Close()
+
+	target := memoryStorage{}
+	_, err = Import(context.TODO(), target, b.Bytes(), project.Folders{PDF: "pdf", Images: "images"})
+	if err == nil || len(target) != 0 {
+		t.Errorf("Expected archive with unreferenced entries to be rejected. Err: %v Storage: %v", err, target)
+	}
+}
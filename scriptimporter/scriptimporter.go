@@ -0,0 +1,149 @@
+// Package scriptimporter handles parsing of script files that are provided alongside a pdf
+// so that video segment scripts do not need to be typed in one by one.
+//
+// Scripts are keyed by slide number which starts from 1 (first page of the pdf).
+// The following formats are supported:
+//   - Markdown (.md, .markdown) - each slide begins with a heading such as "## Slide 3"
+//   - JSON (.json) - either an object {"1": "script"} or a list [{"slide": 1, "script": "script"}]
+//   - CSV (.csv) - rows of slide number and script, with an optional header row
+package scriptimporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedFormat = fmt.Errorf("unsupported script file format. Only markdown, json and csv are supported")
+
+var markdownSlideHeading = regexp.MustCompile(`(?i)^#{1,6}\s*slide\s+(\d+)\b.*$`)
+
+// IsSupported checks the extension of the filename to see if it can be parsed
+func IsSupported(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".md", ".markdown", ".json", ".csv":
+		return true
+	}
+	return false
+}
+
+// Parse reads the content of the script file based on the file extension of the filename
+// Returns a map of slide number to its script
+func Parse(filename string, content []byte) (map[int]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".md", ".markdown":
+		return parseMarkdown(content)
+	case ".json":
+		return parseJSON(content)
+	case ".csv":
+		return parseCSV(content)
+	}
+	return map[int]string{}, ErrUnsupportedFormat
+}
+
+// ForOrder retrieves the script for a slide asset order. Slide asset order starts
+// from 0 while slide numbers in script files start from 1
+func ForOrder(scripts map[int]string, order int) string {
+	return scripts[order+1]
+}
+
+func parseMarkdown(content []byte) (map[int]string, error) {
+	scripts := map[int]string{}
+	currentSlide := 0
+	var currentLines []string
+	flush := func() {
+		if currentSlide > 0 {
+			scripts[currentSlide] = strings.TrimSpace(strings.Join(currentLines, "\n"))
+		}
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		matches := markdownSlideHeading.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) == 2 {
+			flush()
+			num, _ := strconv.Atoi(matches[1])
+			if num < 1 {
+				return map[int]string{}, fmt.Errorf("invalid slide number %v in markdown heading", matches[1])
+			}
+			if _, exists := scripts[num]; exists {
+				return map[int]string{}, fmt.Errorf("slide %v is defined more than once", num)
+			}
+			currentSlide = num
+			currentLines = []string{}
+			continue
+		}
+		currentLines = append(currentLines, line)
+	}
+	flush()
+	if len(scripts) == 0 {
+		return scripts, fmt.Errorf("no slide headings found in markdown file. Use headings such as '## Slide 1'")
+	}
+	return scripts, nil
+}
+
+func parseJSON(content []byte) (map[int]string, error) {
+	scripts := map[int]string{}
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		type slideScript struct {
+			Slide  int    `json:"slide"`
+			Script string `json:"script"`
+		}
+		items := []slideScript{}
+		err := json.Unmarshal(trimmed, &items)
+		if err != nil {
+			return map[int]string{}, fmt.Errorf("unable to parse json script file. err: %v", err)
+		}
+		for _, item := range items {
+			if item.Slide < 1 {
+				return map[int]string{}, fmt.Errorf("invalid slide number %v in json script file", item.Slide)
+			}
+			scripts[item.Slide] = strings.TrimSpace(item.Script)
+		}
+		return scripts, nil
+	}
+
+	raw := map[string]string{}
+	err := json.Unmarshal(trimmed, &raw)
+	if err != nil {
+		return map[int]string{}, fmt.Errorf("unable to parse json script file. err: %v", err)
+	}
+	for k, v := range raw {
+		num, err := strconv.Atoi(strings.TrimSpace(k))
+		if err != nil || num < 1 {
+			return map[int]string{}, fmt.Errorf("invalid slide number %v in json script file", k)
+		}
+		scripts[num] = strings.TrimSpace(v)
+	}
+	return scripts, nil
+}
+
+func parseCSV(content []byte) (map[int]string, error) {
+	scripts := map[int]string{}
+	r := csv.NewReader(bytes.NewReader(content))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return map[int]string{}, fmt.Errorf("unable to parse csv script file. err: %v", err)
+	}
+	for i, record := range records {
+		if len(record) < 2 {
+			return map[int]string{}, fmt.Errorf("row %v of csv script file requires both slide number and script", i+1)
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil && i == 0 {
+			// Header row
+			continue
+		}
+		if err != nil || num < 1 {
+			return map[int]string{}, fmt.Errorf("invalid slide number %v in row %v of csv script file", record[0], i+1)
+		}
+		scripts[num] = strings.TrimSpace(record[1])
+	}
+	return scripts, nil
+}
@@ -0,0 +1,77 @@
+package scriptimporter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     map[int]string
+		wantErr  bool
+	}{
+		{
+			name:     "markdown",
+			filename: "scripts.md",
+			content:  "# Deck title\n\n## Slide 1\nHello everyone.\n\n## Slide 2 - Agenda\nToday we cover\nthree things.\n",
+			want:     map[int]string{1: "Hello everyone.", 2: "Today we cover\nthree things."},
+		},
+		{
+			name:     "markdown without slide headings",
+			filename: "scripts.md",
+			content:  "Just some notes",
+			wantErr:  true,
+		},
+		{
+			name:     "json object",
+			filename: "scripts.JSON",
+			content:  `{"1": "Hello", "3": "Bye"}`,
+			want:     map[int]string{1: "Hello", 3: "Bye"},
+		},
+		{
+			name:     "json list",
+			filename: "scripts.json",
+			content:  `[{"slide": 2, "script": " Middle "}]`,
+			want:     map[int]string{2: "Middle"},
+		},
+		{
+			name:     "json bad slide number",
+			filename: "scripts.json",
+			content:  `{"zero": "Hello"}`,
+			wantErr:  true,
+		},
+		{
+			name:     "csv with header",
+			filename: "scripts.csv",
+			content:  "slide,script\n1,\"Hello, world\"\n2,Second\n",
+			want:     map[int]string{1: "Hello, world", 2: "Second"},
+		},
+		{
+			name:     "csv bad row",
+			filename: "scripts.csv",
+			content:  "1,Hello\nabc,Second\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported format",
+			filename: "scripts.docx",
+			content:  "",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.filename, []byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package scriptrevision
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger            logger.Logger
+	entityName        string
+	projectEntityName string
+	client            *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, projectEntity, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:            logger,
+		client:            ds,
+		entityName:        en,
+		projectEntityName: projectEntity,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e ScriptRevision) error {
+	projectKey := datastore.NameKey(g.projectEntityName, e.ProjectID, nil)
+	newKey := datastore.NameKey(g.entityName, e.ID, projectKey)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) Get(ctx context.Context, projectID, videoSegmentID string, revision int) (ScriptRevision, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	query := datastore.NewQuery(g.entityName).Ancestor(projectKey).Filter("VideoSegmentID =", videoSegmentID).Filter("Revision =", revision)
+	revisions := []ScriptRevision{}
+	keys, err := g.client.GetAll(ctx, query, &revisions)
+	if err != nil {
+		return ScriptRevision{}, fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+	}
+	if len(revisions) != 1 {
+		return ScriptRevision{}, fmt.Errorf("no records found")
+	}
+	revisions[0].ID = keys[0].Name
+	revisions[0].ProjectID = projectID
+	return revisions[0], nil
+}
+
+func (g *googleDatastore) GetAll(ctx context.Context, projectID, videoSegmentID string, limit, after int) ([]ScriptRevision, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	query := datastore.NewQuery(g.entityName).Ancestor(projectKey).Filter("VideoSegmentID =", videoSegmentID).Order("-Revision").Limit(limit).Offset(after)
+	revisions := []ScriptRevision{}
+	keys, err := g.client.GetAll(ctx, query, &revisions)
+	if err != nil {
+		return []ScriptRevision{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	for i, key := range keys {
+		revisions[i].ID = key.Name
+		revisions[i].ProjectID = projectID
+	}
+	return revisions, nil
+}
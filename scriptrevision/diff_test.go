@@ -0,0 +1,44 @@
+package scriptrevision
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "no change",
+			before: "Hello",
+			after:  "Hello",
+			want:   "",
+		},
+		{
+			name:   "new script",
+			before: "",
+			after:  "Hello",
+			want:   "+ Hello",
+		},
+		{
+			name:   "changed line",
+			before: "Hello\nWorld\nBye",
+			after:  "Hello\nEveryone\nBye",
+			want:   "  Hello\n- World\n+ Everyone\n  Bye",
+		},
+		{
+			name:   "removed lines",
+			before: "Hello\nWorld",
+			after:  "World",
+			want:   "- Hello\n  World",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.before, tt.after); got != tt.want {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
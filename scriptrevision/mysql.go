@@ -0,0 +1,46 @@
+package scriptrevision
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e ScriptRevision) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, projectID, videoSegmentID string, revision int) (ScriptRevision, error) {
+	s := ScriptRevision{}
+	result := m.db.Where("project_id = ? AND video_segment_id = ? AND revision = ?", projectID, videoSegmentID, revision).First(&s)
+	if result.Error != nil {
+		return s, result.Error
+	}
+	return s, nil
+}
+
+func (m mysql) GetAll(ctx context.Context, projectID, videoSegmentID string, Limit, After int) ([]ScriptRevision, error) {
+	var revisions []ScriptRevision
+	result := m.db.Where("project_id = ? AND video_segment_id = ?", projectID, videoSegmentID).Order("revision desc").Limit(Limit).Offset(After).Find(&revisions)
+	if result.Error != nil {
+		return []ScriptRevision{}, result.Error
+	}
+	return revisions, nil
+}
@@ -0,0 +1,35 @@
+// Package scriptrevision keeps the history of edits made to the script of a video segment
+package scriptrevision
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type ScriptRevision struct {
+	ID             string    `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	ProjectID      string    `json:"project_id" datastore:"-" gorm:"type:varchar(40)"`
+	VideoSegmentID string    `json:"video_segment_id" gorm:"type:varchar(40)"`
+	Revision       int       `json:"revision" gorm:"type:int"`
+	Script         string    `json:"script" datastore:",noindex" gorm:"type:text"`
+	Diff           string    `json:"diff" datastore:",noindex" gorm:"type:text"`
+	Author         string    `json:"author" gorm:"type:varchar(40)"`
+	DateCreated    time.Time `json:"date_created"`
+}
+
+// New creates a revision of the script. The diff is computed against the script
+// of the previous revision
+func New(projectID, videoSegmentID string, revision int, previousScript, script, author string) ScriptRevision {
+	id, _ := uuid.NewV4()
+	return ScriptRevision{
+		ID:             id.String(),
+		ProjectID:      projectID,
+		VideoSegmentID: videoSegmentID,
+		Revision:       revision,
+		Script:         script,
+		Diff:           Diff(previousScript, script),
+		Author:         author,
+		DateCreated:    time.Now(),
+	}
+}
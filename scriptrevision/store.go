@@ -0,0 +1,9 @@
+package scriptrevision
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e ScriptRevision) error
+	Get(ctx context.Context, projectID, videoSegmentID string, revision int) (ScriptRevision, error)
+	GetAll(ctx context.Context, projectID, videoSegmentID string, Limit, After int) ([]ScriptRevision, error)
+}
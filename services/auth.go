@@ -1,10 +1,62 @@
 package services
 
+import (
+	"github.com/gorilla/securecookie"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/accesstoken"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/session"
+)
+
 type Auth struct {
-	Secret     string
-	ExpiryTime int
-	Issuer     string
-	HashKey    []byte
-	BlockKey   []byte
-	CookieName string
+	// Secret signs the JWTs that are issued. PreviousSecrets are still accepted so that
+	// tokens issued before the secret is rotated remain valid until they expire
+	Secret          string
+	PreviousSecrets []string
+	// SigningKeys sign the JWTs with RS256 or EdDSA in place of the secret once configured
+	// Their public keys are published so that tokens can be verified without the ability to
+	// mint them
+	SigningKeys KeySet
+	ExpiryTime  int
+	Issuer      string
+	// HashKey and BlockKey encode the cookies that are set. PreviousCookieKeys are still
+	// accepted so that users stay signed in when the keys are rotated
+	HashKey            []byte
+	BlockKey           []byte
+	PreviousCookieKeys []CookieKeys
+	CookieName         string
+	// AccessTokens looks up the personal access tokens that users authenticate with
+	AccessTokens accesstoken.Store
+	// Sessions keeps the sessions that the cookies and JWTs of users belong to
+	Sessions session.Store
+	// SessionExpiryTime is the number of seconds that a session lasts after the user logs in
+	SessionExpiryTime int
+}
+
+// CookieKeys is a pair of keys that cookies are encoded with
+type CookieKeys struct {
+	HashKey  []byte
+	BlockKey []byte
+}
+
+// Secrets lists the secrets that JWTs are checked against, starting with the current secret
+func (a Auth) Secrets() []string {
+	return append([]string{a.Secret}, a.PreviousSecrets...)
+}
+
+// Keys provides the keys that JWTs are signed and verified with. Secrets are no longer
+// accepted once signing keys are configured as anyone with a secret is able to mint tokens
+func (a Auth) Keys() KeySet {
+	if len(a.SigningKeys) > 0 {
+		return a.SigningKeys
+	}
+	return SecretKeys(a.Secrets()...)
+}
+
+// CookieCodecs provides the codecs that cookies are encoded and decoded with. Cookies are
+// encoded with the current keys and decoded with any of the keys
+func (a Auth) CookieCodecs() []securecookie.Codec {
+	keyPairs := [][]byte{a.HashKey, a.BlockKey}
+	for _, k := range a.PreviousCookieKeys {
+		keyPairs = append(keyPairs, k.HashKey, k.BlockKey)
+	}
+	return securecookie.CodecsFromPairs(keyPairs...)
 }
@@ -16,8 +16,11 @@ var ErrJWTSigning = errors.New("Error while signing the JWT Token")
 var ErrJWTExtract = errors.New("Error while extracting values from JWT Token")
 
 // JWTCustomClaims contains the list of values that is to be contained within the JWT Token
+// SessionID is the session that the token was issued for. The token stops working once the
+// session is revoked
 type JWTCustomClaims struct {
-	ID string `json:"id"`
+	ID        string `json:"id"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -27,21 +30,21 @@ type JWTCustomClaims struct {
 // issuer is to specify the application that is providing this token as an identifier
 // secret is to seal everything into the token
 func NewToken(id string, exp int, secret, issuer string) (string, error) {
+	return NewSessionToken(id, "", exp, SecretKeys(secret), issuer)
+}
+
+// NewSessionToken creates a new JWT token for the session of the user. The token is signed
+// with the signing key of keys
+func NewSessionToken(id, sessionID string, exp int, keys KeySet, issuer string) (string, error) {
 	claims := JWTCustomClaims{
 		id,
+		sessionID,
 		jwt.StandardClaims{
 			ExpiresAt: int64(time.Now().Add(time.Duration(exp) * time.Second).Unix()),
 			Issuer:    issuer,
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString([]byte(secret))
-	if err != nil {
-		return "", ErrJWTSigning
-	}
-
-	return tokenString, nil
+	return keys.Sign(claims)
 }
 
 // ExtractToken takes a JWT Token that is used by the application and extracts the values out
@@ -49,24 +52,31 @@ func NewToken(id string, exp int, secret, issuer string) (string, error) {
 // return the values
 // This function is only expected to be used to deal with incoming HTTP requests
 // - so we would expect the word "Bearer" as well
-func ExtractToken(tokenString, secret string) (string, error) {
+// The token is checked against each of the secrets in turn so that secrets can be rotated
+// Only tokens signed with HS256 are accepted
+func ExtractToken(tokenString string, secrets ...string) (string, error) {
+	claims, err := ExtractClaims(tokenString, SecretKeys(secrets...))
+	if err != nil {
+		return "", err
+	}
+	return claims.ID, nil
+}
+
+// ExtractClaims takes a JWT Token in the same way as ExtractToken and returns all of its values
+// The token has to be signed by one of keys with the algorithm of that key
+func ExtractClaims(tokenString string, keys KeySet) (JWTCustomClaims, error) {
 	splitTokenString := strings.Split(tokenString, " ")
 	if len(splitTokenString) != 2 {
-		return "", fmt.Errorf("Invalid JWT. No type provided")
+		return JWTCustomClaims{}, fmt.Errorf("Invalid JWT. No type provided")
 	}
 
-	token, err := jwt.ParseWithClaims(splitTokenString[1], &JWTCustomClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
+	token, err := keys.Parse(splitTokenString[1], func() jwt.Claims { return &JWTCustomClaims{} })
 	if err != nil {
-		return "", err
+		return JWTCustomClaims{}, err
 	}
-
 	claims, ok := token.Claims.(*JWTCustomClaims)
-	if ok && token.Valid {
-		return claims.ID, nil
+	if !ok {
+		return JWTCustomClaims{}, ErrJWTExtract
 	}
-
-	return "", ErrJWTExtract
+	return *claims, nil
 }
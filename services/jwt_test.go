@@ -43,3 +43,19 @@ func TestExtractToken(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractClaims(t *testing.T) {
+	token, _ := NewSessionToken("1234", "session", 3600, SecretKeys("current"), "manager")
+	oldToken, _ := NewSessionToken("1234", "session", 3600, SecretKeys("previous"), "manager")
+
+	claims, err := ExtractClaims("Bearer "+token, SecretKeys("current", "previous"))
+	if err != nil || claims.ID != "1234" || claims.SessionID != "session" {
+		t.Errorf("ExtractClaims() = %+v, %v", claims, err)
+	}
+	if _, err := ExtractClaims("Bearer "+oldToken, SecretKeys("current", "previous")); err != nil {
+		t.Errorf("expected token signed with previous secret to be accepted. Err: %v", err)
+	}
+	if _, err := ExtractClaims("Bearer "+oldToken, SecretKeys("current")); err == nil {
+		t.Errorf("expected token signed with removed secret to be rejected")
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// ErrNoSigningKey is returned when none of the keys is able to sign tokens
+var ErrNoSigningKey = errors.New("No key available to sign the JWT Token")
+
+// SigningKey is a key that JWTs are signed or verified with
+// Keys of HS256 are shared secrets and are never published. Keys of RS256 and EdDSA are
+// identified by their ID in the "kid" header of the token. Asymmetric keys without a
+// private key are only kept to verify tokens that were issued before the key was rotated
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// NewSecretKey creates a HS256 key from a shared secret
+func NewSecretKey(secret string) SigningKey {
+	return SigningKey{
+		Method:     jwt.SigningMethodHS256,
+		PrivateKey: []byte(secret),
+		PublicKey:  []byte(secret),
+	}
+}
+
+// ParseSigningKey creates a key from PEM encoded keys. algorithm is either RS256 or EdDSA
+// The public key is derived from the private key if it is not provided. The private key is
+// left out for keys that are only used to verify tokens
+func ParseSigningKey(id, algorithm string, privateKeyPEM, publicKeyPEM []byte) (SigningKey, error) {
+	if id == "" {
+		return SigningKey{}, fmt.Errorf("ID of signing key cannot be empty")
+	}
+	if len(privateKeyPEM) == 0 && len(publicKeyPEM) == 0 {
+		return SigningKey{}, fmt.Errorf("signing key %v has neither a private key nor a public key", id)
+	}
+
+	key := SigningKey{ID: id}
+	var err error
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+		if len(privateKeyPEM) > 0 {
+			var privateKey *rsa.PrivateKey
+			privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+			if err != nil {
+				return SigningKey{}, fmt.Errorf("unable to parse private key of %v. Err: %v", id, err)
+			}
+			key.PrivateKey, key.PublicKey = privateKey, privateKey.Public()
+		}
+		if len(publicKeyPEM) > 0 {
+			key.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+		}
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+		if len(privateKeyPEM) > 0 {
+			var privateKey crypto.PrivateKey
+			privateKey, err = jwt.ParseEdPrivateKeyFromPEM(privateKeyPEM)
+			if err != nil {
+				return SigningKey{}, fmt.Errorf("unable to parse private key of %v. Err: %v", id, err)
+			}
+			key.PrivateKey, key.PublicKey = privateKey, privateKey.(ed25519.PrivateKey).Public()
+		}
+		if len(publicKeyPEM) > 0 {
+			key.PublicKey, err = jwt.ParseEdPublicKeyFromPEM(publicKeyPEM)
+		}
+	default:
+		return SigningKey{}, fmt.Errorf("signing key %v has an unsupported algorithm %v", id, algorithm)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("unable to parse public key of %v. Err: %v", id, err)
+	}
+	return key, nil
+}
+
+// KeySet is the list of keys that JWTs are signed and verified with
+// Tokens are signed with the first key that has a private key. Tokens are verified with
+// the key named in their "kid" header, or with each key in turn if the header is absent
+// The algorithm of a token has to match that of the key that verifies it
+type KeySet []SigningKey
+
+// SecretKeys creates a key set of shared secrets, starting with the secret that signs tokens
+func SecretKeys(secrets ...string) KeySet {
+	var keys KeySet
+	for _, secret := range secrets {
+		keys = append(keys, NewSecretKey(secret))
+	}
+	return keys
+}
+
+// Sign signs the claims into a token
+func (k KeySet) Sign(claims jwt.Claims) (string, error) {
+	for _, key := range k {
+		if key.PrivateKey == nil {
+			continue
+		}
+		token := jwt.NewWithClaims(key.Method, claims)
+		if key.ID != "" {
+			token.Header["kid"] = key.ID
+		}
+		tokenString, err := token.SignedString(key.PrivateKey)
+		if err != nil {
+			return "", ErrJWTSigning
+		}
+		return tokenString, nil
+	}
+	return "", ErrNoSigningKey
+}
+
+// Parse verifies the token and decodes its values into claims. claims is created for each
+// attempt so that values of a rejected attempt do not carry over
+func (k KeySet) Parse(tokenString string, claims func() jwt.Claims) (*jwt.Token, error) {
+	var kid string
+	if unverified, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims()); err == nil {
+		kid, _ = unverified.Header["kid"].(string)
+	}
+
+	err := ErrJWTExtract
+	for _, key := range k {
+		if kid != "" && key.ID != kid {
+			continue
+		}
+		if key.PublicKey == nil {
+			continue
+		}
+		parser := jwt.Parser{ValidMethods: []string{key.Method.Alg()}}
+		var token *jwt.Token
+		token, err = parser.ParseWithClaims(tokenString, claims(), func(token *jwt.Token) (interface{}, error) {
+			return key.PublicKey, nil
+		})
+		if err == nil && token.Valid {
+			return token, nil
+		}
+		if err == nil {
+			err = ErrJWTExtract
+		}
+	}
+	return nil, err
+}
+
+// JSONWebKey is the public part of a signing key as published in the JWKS endpoint
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// N and E are the modulus and exponent of RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve and X are the curve and public key of EdDSA keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet lists the public keys that tokens can be verified with
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS provides the public keys of the asymmetric keys in the set. Shared secrets are
+// left out as they would allow anyone to mint tokens
+func (k KeySet) JWKS() JSONWebKeySet {
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range k {
+		jwk := JSONWebKey{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+func rsaKeyHelper(t *testing.T, id string) (SigningKey, []byte) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate rsa key. Err: %v", err)
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	rawPublicKey, _ := x509.MarshalPKIXPublicKey(privateKey.Public())
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rawPublicKey})
+	key, err := ParseSigningKey(id, "RS256", privatePEM, nil)
+	if err != nil {
+		t.Fatalf("ParseSigningKey() error = %v", err)
+	}
+	return key, publicPEM
+}
+
+func edKeyHelper(t *testing.T, id string) SigningKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate ed25519 key. Err: %v", err)
+	}
+	rawPrivateKey, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rawPrivateKey})
+	key, err := ParseSigningKey(id, "EdDSA", privatePEM, nil)
+	if err != nil {
+		t.Fatalf("ParseSigningKey() error = %v", err)
+	}
+	return key
+}
+
+func TestKeySet(t *testing.T) {
+	rsaKey, rsaPublicPEM := rsaKeyHelper(t, "rsa")
+	edKey := edKeyHelper(t, "ed")
+	verifyOnlyKey, err := ParseSigningKey("rsa", "RS256", nil, rsaPublicPEM)
+	if err != nil {
+		t.Fatalf("ParseSigningKey() error = %v", err)
+	}
+
+	rsaToken, _ := NewSessionToken("1234", "session", 3600, KeySet{rsaKey}, "manager")
+	edToken, _ := NewSessionToken("1234", "session", 3600, KeySet{edKey, rsaKey}, "manager")
+	secretToken, _ := NewToken("1234", 3600, "manager", "manager")
+
+	// Token signed with HS256 using the public key of the RSA key as the secret
+	publicKeyAsSecret, _ := NewToken("1234", 3600, string(rsaPublicPEM), "manager")
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, JWTCustomClaims{ID: "1234"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	unknownKid := jwt.NewWithClaims(jwt.SigningMethodRS256, JWTCustomClaims{ID: "1234"})
+	unknownKid.Header["kid"] = "other"
+	unknownKidToken, _ := unknownKid.SignedString(rsaKey.PrivateKey)
+
+	tests := []struct {
+		name    string
+		token   string
+		keys    KeySet
+		wantErr bool
+	}{
+		{name: "rsa token", token: rsaToken, keys: KeySet{rsaKey}},
+		{name: "eddsa token with several keys", token: edToken, keys: KeySet{rsaKey, edKey}},
+		{name: "rsa token verified with public key only", token: rsaToken, keys: KeySet{edKey, verifyOnlyKey}},
+		{name: "token of removed key", token: edToken, keys: KeySet{rsaKey}, wantErr: true},
+		{name: "secret token rejected by asymmetric keys", token: secretToken, keys: KeySet{rsaKey, edKey}, wantErr: true},
+		{name: "public key used as secret", token: publicKeyAsSecret, keys: KeySet{verifyOnlyKey}, wantErr: true},
+		{name: "unsigned token", token: unsigned, keys: KeySet{rsaKey}, wantErr: true},
+		{name: "unsigned token with secrets", token: unsigned, keys: SecretKeys("manager"), wantErr: true},
+		{name: "unknown kid", token: unknownKidToken, keys: KeySet{rsaKey}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ExtractClaims("Bearer "+tt.token, tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractClaims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && claims.ID != "1234" {
+				t.Errorf("ExtractClaims() = %+v", claims)
+			}
+		})
+	}
+
+	if _, err := (KeySet{verifyOnlyKey}).Sign(JWTCustomClaims{ID: "1234"}); err != ErrNoSigningKey {
+		t.Errorf("expected key set without private keys to be unable to sign. Err: %v", err)
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, _ := rsaKeyHelper(t, "rsa")
+	edKey := edKeyHelper(t, "ed")
+
+	jwks := KeySet{edKey, rsaKey, NewSecretKey("manager")}.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected only the asymmetric keys to be published. Got %+v", jwks.Keys)
+	}
+	if k := jwks.Keys[0]; k.KeyID != "ed" || k.KeyType != "OKP" || k.Curve != "Ed25519" || k.Algorithm != "EdDSA" || k.X == "" {
+		t.Errorf("unexpected eddsa key %+v", k)
+	}
+	if k := jwks.Keys[1]; k.KeyID != "rsa" || k.KeyType != "RSA" || k.Algorithm != "RS256" || k.N == "" || k.E != "AQAB" {
+		t.Errorf("unexpected rsa key %+v", k)
+	}
+}
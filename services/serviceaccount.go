@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// ServiceAccount is the identity of a worker that reports the progress of its jobs to the manager
+type ServiceAccount string
+
+var (
+	PDFSplitter      ServiceAccount = "pdf-splitter"
+	ImageToVideo     ServiceAccount = "image-to-video"
+	ConcatenateVideo ServiceAccount = "concatenate-video"
+)
+
+// Scope is an operation on the manager that a service account can be allowed to do
+type Scope string
+
+var (
+	// UpdatePDFSlideImagesStatus allows the status of the pdf slide images to be updated
+	UpdatePDFSlideImagesStatus Scope = "pdfslideimages:update-status"
+	// UpdateVideoSegmentStatus allows the status of a video segment to be updated
+	UpdateVideoSegmentStatus Scope = "videosegment:update-status"
+	// UpdateProjectStatus allows the status of the concatenated video of a project to be updated
+	UpdateProjectStatus Scope = "project:update-status"
+	// UpdateVideoOutputStatus allows the status of a video output to be updated
+	UpdateVideoOutputStatus Scope = "videooutput:update-status"
+)
+
+var serviceAccountScopes = map[ServiceAccount][]Scope{
+	PDFSplitter:      {UpdatePDFSlideImagesStatus},
+	ImageToVideo:     {UpdateVideoSegmentStatus},
+	ConcatenateVideo: {UpdateProjectStatus, UpdateVideoOutputStatus},
+}
+
+// Allows checks if the service account is allowed to do the operation of the scope
+func (a ServiceAccount) Allows(s Scope) bool {
+	for _, allowed := range serviceAccountScopes[a] {
+		if allowed == s {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceAudience marks tokens that are issued to service accounts so that they are never
+// mistaken for tokens of users
+var serviceAudience = "service"
+
+// ServiceClaims contains the values within the token that is issued to a service account
+// for a single job. The token only allows the operation of the scope on the resource of the job
+// Subject is the ID of the user that started the job
+type ServiceClaims struct {
+	Account    ServiceAccount `json:"account"`
+	Scope      Scope          `json:"scope"`
+	ProjectID  string         `json:"project_id"`
+	ResourceID string         `json:"resource_id"`
+	jwt.StandardClaims
+}
+
+// NewServiceToken creates a token for the service account to report the progress of a job
+// resourceID is the ID of the item being updated by the job - it is empty if the project
+// itself is being updated
+// exp is the expiry in the number of seconds after its issue
+func NewServiceToken(account ServiceAccount, scope Scope, projectID, resourceID, userID string, exp int, keys KeySet, issuer string) (string, error) {
+	if !account.Allows(scope) {
+		return "", fmt.Errorf("service account %v is not allowed to %v", account, scope)
+	}
+	claims := ServiceClaims{
+		Account:    account,
+		Scope:      scope,
+		ProjectID:  projectID,
+		ResourceID: resourceID,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  serviceAudience,
+			ExpiresAt: int64(time.Now().Add(time.Duration(exp) * time.Second).Unix()),
+			Issuer:    issuer,
+			Subject:   userID,
+		},
+	}
+	return keys.Sign(claims)
+}
+
+// ExtractServiceToken takes a token issued to a service account from the Authorization header
+// and extracts its claims. Tokens of users are rejected, as are tokens with a scope that the
+// service account is not allowed. The token has to be signed by one of keys
+func ExtractServiceToken(tokenString string, keys KeySet) (ServiceClaims, error) {
+	splitTokenString := strings.Split(tokenString, " ")
+	if len(splitTokenString) != 2 {
+		return ServiceClaims{}, fmt.Errorf("Invalid JWT. No type provided")
+	}
+
+	token, err := keys.Parse(splitTokenString[1], func() jwt.Claims { return &ServiceClaims{} })
+	if err != nil {
+		return ServiceClaims{}, err
+	}
+	claims, ok := token.Claims.(*ServiceClaims)
+	if !ok || !claims.VerifyAudience(serviceAudience, true) {
+		return ServiceClaims{}, ErrJWTExtract
+	}
+	if !claims.Account.Allows(claims.Scope) {
+		return ServiceClaims{}, fmt.Errorf("service account %v is not allowed to %v", claims.Account, claims.Scope)
+	}
+
+	return *claims, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestNewServiceToken(t *testing.T) {
+	if _, err := NewServiceToken(PDFSplitter, UpdateProjectStatus, "project", "", "user", 3600, SecretKeys("manager"), "manager"); err == nil {
+		t.Errorf("expected error when service account is not allowed the scope")
+	}
+}
+
+func TestExtractServiceToken(t *testing.T) {
+	serviceToken, _ := NewServiceToken(ImageToVideo, UpdateVideoSegmentStatus, "project", "segment", "user", 3600, SecretKeys("manager"), "manager")
+	expiredToken, _ := NewServiceToken(ImageToVideo, UpdateVideoSegmentStatus, "project", "segment", "user", -10, SecretKeys("manager"), "manager")
+
+	tests := []struct {
+		name        string
+		tokenString string
+		want        ServiceClaims
+		wantErr     bool
+	}{
+		{
+			name:        "successful case",
+			tokenString: "Bearer " + serviceToken,
+			want:        ServiceClaims{Account: ImageToVideo, Scope: UpdateVideoSegmentStatus, ProjectID: "project", ResourceID: "segment"},
+		},
+		{
+			name:        "user token",
+			tokenString: tokenHelper("1234-1234-1234-1234"),
+			wantErr:     true,
+		},
+		{
+			name:        "expired token",
+			tokenString: "Bearer " + expiredToken,
+			wantErr:     true,
+		},
+		{
+			name:        "no type provided",
+			tokenString: serviceToken,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractServiceToken(tt.tokenString, SecretKeys("manager"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractServiceToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Account != tt.want.Account || got.Scope != tt.want.Scope || got.ProjectID != tt.want.ProjectID || got.ResourceID != tt.want.ResourceID || got.Subject != "user" {
+				t.Errorf("ExtractServiceToken() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if userID, _ := ExtractToken("Bearer "+serviceToken, "manager"); userID != "" {
+		t.Errorf("expected service token to not be accepted as user token. Got user %v", userID)
+	}
+}
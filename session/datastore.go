@@ -0,0 +1,98 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger     logger.Logger
+	entityName string
+	client     *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:     logger,
+		client:     ds,
+		entityName: en,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e Session) error {
+	newKey := datastore.NameKey(g.entityName, e.ID, nil)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) Get(ctx context.Context, ID string) (Session, error) {
+	key := datastore.NameKey(g.entityName, ID, nil)
+	s := Session{}
+	if err := g.client.Get(ctx, key, &s); err != nil {
+		return Session{}, fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+	}
+	s.ID = ID
+	return s, nil
+}
+
+func (g *googleDatastore) Update(ctx context.Context, ID string, setters ...func(*Session) error) (Session, error) {
+	key := datastore.NameKey(g.entityName, ID, nil)
+	s := Session{}
+	_, err := g.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(key, &s); err != nil {
+			return fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+		}
+		s.ID = ID
+		for _, setFunc := range setters {
+			err := setFunc(&s)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := tx.Put(key, &s)
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Session{}, fmt.Errorf("unable to send record to datastore: err: %w", err)
+	}
+	return s, nil
+}
+
+func (g *googleDatastore) RevokeAll(ctx context.Context, userID string) error {
+	query := datastore.NewQuery(g.entityName).Filter("UserID =", userID)
+	sessions := []Session{}
+	keys, err := g.client.GetAll(ctx, query, &sessions)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	now := time.Now()
+	revokedKeys := []*datastore.Key{}
+	revoked := []Session{}
+	for i, s := range sessions {
+		if s.RevokedAt != nil {
+			continue
+		}
+		s.RevokedAt = &now
+		s.DateModified = now
+		revokedKeys = append(revokedKeys, keys[i])
+		revoked = append(revoked, s)
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+	_, err = g.client.PutMulti(ctx, revokedKeys, revoked)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
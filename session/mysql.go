@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e Session) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, ID string) (Session, error) {
+	s := Session{}
+	result := m.db.Where("id = ?", ID).First(&s)
+	if result.Error != nil {
+		return s, result.Error
+	}
+	return s, nil
+}
+
+func (m mysql) Update(ctx context.Context, ID string, setters ...func(*Session) error) (Session, error) {
+	s := Session{}
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		// The row is locked so that the refresh token is only replaced once
+		result := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", ID).First(&s)
+		if result.Error != nil {
+			return result.Error
+		}
+		for _, setFunc := range setters {
+			err := setFunc(&s)
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Save(&s).Error
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	return s, nil
+}
+
+func (m mysql) RevokeAll(ctx context.Context, userID string) error {
+	now := time.Now()
+	result := m.db.Model(&Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).Updates(map[string]interface{}{"revoked_at": now, "date_modified": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
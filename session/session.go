@@ -0,0 +1,154 @@
+// Package session keeps the sessions that users sign in with on the server
+//
+// A session is created when the user logs in. The cookie and the tokens handed to the user
+// refer to the session so that they stop working once the session is revoked by logging out
+// or by changing the password. Short lived tokens are renewed with the refresh token of the
+// session, which is replaced on every refresh. Only the hash of the refresh token is kept.
+// Refresh tokens carry the session and the number of times that it was refreshed. A refresh
+// token from an earlier refresh could only have been used again if it was stolen, so the
+// session is revoked when that happens
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+var (
+	ErrRevoked             = errors.New("Session has been revoked")
+	ErrExpired             = errors.New("Session has expired")
+	ErrRefreshTokenInvalid = errors.New("Refresh token of session is not valid")
+	ErrRefreshTokenReused  = errors.New("Refresh token of session has already been used")
+)
+
+type Session struct {
+	ID               string `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	UserID           string `json:"user_id" gorm:"type:varchar(40);index"`
+	RefreshTokenHash string `json:"-" gorm:"type:varchar(64);unique_index"`
+	// RefreshCount is the number of times that the session was refreshed. It is carried by the
+	// refresh token so that refresh tokens of earlier refreshes are told apart from invalid ones
+	RefreshCount int        `json:"-"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	DateCreated  time.Time  `json:"date_created"`
+	DateModified time.Time  `json:"date_modified"`
+}
+
+// New creates a session for the user that lasts for the lifetime. The refresh token is only
+// returned here - it is to be handed to the user right away as only its hash is kept
+func New(userID string, lifetime time.Duration) (Session, string, error) {
+	sessionID, _ := uuid.NewV4()
+	refreshToken, err := newRefreshToken(sessionID.String(), 0)
+	if err != nil {
+		return Session{}, "", err
+	}
+	currentTime := time.Now()
+	return Session{
+		ID:               sessionID.String(),
+		UserID:           userID,
+		RefreshTokenHash: HashRefreshToken(refreshToken),
+		ExpiresAt:        currentTime.Add(lifetime),
+		DateCreated:      currentTime,
+		DateModified:     currentTime,
+	}, refreshToken, nil
+}
+
+// newRefreshToken creates a refresh token for the session after it has been refreshed count times
+func newRefreshToken(sessionID string, count int) (string, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v.%v.%v", sessionID, count, base64.RawURLEncoding.EncodeToString(raw)), nil
+}
+
+// ParseRefreshToken provides the session of the refresh token and the number of times that the
+// session had been refreshed when the refresh token was handed out
+func ParseRefreshToken(refreshToken string) (string, int, error) {
+	parts := strings.Split(refreshToken, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return "", 0, ErrRefreshTokenInvalid
+	}
+	count, err := strconv.Atoi(parts[1])
+	if err != nil || count < 0 {
+		return "", 0, ErrRefreshTokenInvalid
+	}
+	return parts[0], count, nil
+}
+
+// HashRefreshToken provides the value that is kept for the refresh token
+func HashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// Active checks that the session can still be used
+func (s Session) Active(now time.Time) error {
+	if s.RevokedAt != nil {
+		return ErrRevoked
+	}
+	if !now.Before(s.ExpiresAt) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// Refresh replaces the refresh token of the session. The refresh token that was provided
+// can no longer be used afterwards - ErrRefreshTokenReused is returned if it or any refresh
+// token before it is provided again, in which case the session is to be revoked
+func Refresh(refreshToken string) ([]func(*Session) error, string, error) {
+	sessionID, count, err := ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+	newToken, err := newRefreshToken(sessionID, count+1)
+	if err != nil {
+		return nil, "", err
+	}
+	var setters []func(*Session) error
+	setters = append(setters, func(s *Session) error {
+		err := s.Active(time.Now())
+		if err != nil {
+			return err
+		}
+		if s.ID != sessionID {
+			return ErrRefreshTokenInvalid
+		}
+		if s.RefreshTokenHash != HashRefreshToken(refreshToken) || s.RefreshCount != count {
+			if count < s.RefreshCount {
+				return ErrRefreshTokenReused
+			}
+			return ErrRefreshTokenInvalid
+		}
+		s.RefreshCount = count + 1
+		s.RefreshTokenHash = HashRefreshToken(newToken)
+		s.DateModified = time.Now()
+		return nil
+	})
+	return setters, newToken, nil
+}
+
+// Revoke ends the session. The cookie and tokens of the session stop working immediately
+func Revoke() ([]func(*Session) error, error) {
+	var setters []func(*Session) error
+	setters = append(setters, func(s *Session) error {
+		if s.RevokedAt != nil {
+			return ErrRevoked
+		}
+		now := time.Now()
+		s.RevokedAt = &now
+		s.DateModified = now
+		return nil
+	})
+	return setters, nil
+}
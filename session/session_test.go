@@ -0,0 +1,74 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	a, refreshToken, err := New("user", time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error when creating session. Err: %v", err)
+	}
+	_, other, _ := New("user", time.Hour)
+	if refreshToken == "" || refreshToken == other {
+		t.Errorf("expected random refresh tokens. %v %v", refreshToken, other)
+	}
+	if a.RefreshTokenHash != HashRefreshToken(refreshToken) || a.RefreshTokenHash == refreshToken {
+		t.Errorf("expected only hash of refresh token to be kept. %+v", a)
+	}
+	if err := a.Active(time.Now()); err != nil {
+		t.Errorf("expected session to be active. Err: %v", err)
+	}
+	if err := a.Active(a.ExpiresAt); err != ErrExpired {
+		t.Errorf("expected session to be expired. Err: %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	s, refreshToken, _ := New("user", time.Hour)
+
+	setters, newToken, _ := Refresh(refreshToken)
+	if err := setters[0](&s); err != nil {
+		t.Fatalf("unexpected error when refreshing session. Err: %v", err)
+	}
+	if s.RefreshTokenHash != HashRefreshToken(newToken) {
+		t.Errorf("expected refresh token to be replaced")
+	}
+
+	setters, latestToken, _ := Refresh(newToken)
+	if err := setters[0](&s); err != nil {
+		t.Fatalf("unexpected error when refreshing session again. Err: %v", err)
+	}
+
+	setters, _, _ = Refresh(newToken)
+	if err := setters[0](&s); err != ErrRefreshTokenReused {
+		t.Errorf("expected replaced refresh token to be reported as reused. Err: %v", err)
+	}
+	setters, _, _ = Refresh(refreshToken)
+	if err := setters[0](&s); err != ErrRefreshTokenReused {
+		t.Errorf("expected refresh token from two refreshes back to be reported as reused. Err: %v", err)
+	}
+	if _, _, err := Refresh("unknown"); err != ErrRefreshTokenInvalid {
+		t.Errorf("expected malformed refresh token to be rejected. Err: %v", err)
+	}
+	_, other, _ := New("user", time.Hour)
+	setters, _, _ = Refresh(other)
+	if err := setters[0](&s); err != ErrRefreshTokenInvalid {
+		t.Errorf("expected refresh token of another session to be rejected. Err: %v", err)
+	}
+	setters, _, _ = Refresh(s.ID + ".5.forged")
+	if err := setters[0](&s); err != ErrRefreshTokenInvalid {
+		t.Errorf("expected unknown refresh token to be rejected. Err: %v", err)
+	}
+
+	revokers, _ := Revoke()
+	revokers[0](&s)
+	setters, _, _ = Refresh(latestToken)
+	if err := setters[0](&s); err != ErrRevoked {
+		t.Errorf("expected refresh of revoked session to be rejected. Err: %v", err)
+	}
+	if err := revokers[0](&s); err != ErrRevoked {
+		t.Errorf("expected error when revoking session again. Err: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package session
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e Session) error
+	Get(ctx context.Context, ID string) (Session, error)
+	// Update applies the setters while holding the session so that concurrent refreshes with
+	// the same refresh token cannot both succeed
+	Update(ctx context.Context, ID string, setters ...func(*Session) error) (Session, error)
+	// RevokeAll ends all the sessions of the user
+	RevokeAll(ctx context.Context, userID string) error
+}
@@ -0,0 +1,74 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var musicFormats = map[string]bool{
+	".mp3": true,
+	".m4a": true,
+	".aac": true,
+	".wav": true,
+	".ogg": true,
+}
+
+// Music is a background music track that is mixed under the narration when the videos of
+// the video segments are concatenated. The track is looped or trimmed to the length of the video
+//
+// Unlike the voice and render settings, music is not merged field by field. The music of a
+// video output replaces the music of the project as a whole once a file is set
+type Music struct {
+	File string `json:"file,omitempty" gorm:"type:varchar(100)"`
+	// Volume of the music relative to its original volume. Defaults to 0.3
+	Volume float64 `json:"volume,omitempty"`
+	// Ducking lowers the music while the narration is playing
+	Ducking bool `json:"ducking"`
+	// DuckingRatio is the compression ratio applied to the music under the narration. Defaults to 8
+	DuckingRatio float64 `json:"ducking_ratio,omitempty"`
+	// FadeIn and FadeOut are in seconds. The music is not faded if they are left as 0
+	FadeIn  float64 `json:"fade_in,omitempty"`
+	FadeOut float64 `json:"fade_out,omitempty"`
+}
+
+// IsMusicFormatSupported checks the extension of an uploaded music file
+func IsMusicFormatSupported(fileName string) bool {
+	return musicFormats[strings.ToLower(filepath.Ext(fileName))]
+}
+
+// ResolveMusic picks the music to be used for a video. The music of the video output is
+// used if it has one, otherwise the music of the project is used
+func ResolveMusic(project, output Music) Music {
+	if output.File != "" {
+		return output
+	}
+	return project
+}
+
+// WithDefaults fills in the values that the workers need to mix the music
+func (m Music) WithDefaults() Music {
+	if m.Volume == 0 {
+		m.Volume = 0.3
+	}
+	if m.DuckingRatio == 0 {
+		m.DuckingRatio = 8
+	}
+	return m
+}
+
+func (m Music) Validate() error {
+	if len(m.File) > 100 {
+		return fmt.Errorf("music file name cannot be longer than 100 characters")
+	}
+	if m.Volume < 0 || m.Volume > 2.0 {
+		return fmt.Errorf("music volume needs to be between 0.0 and 2.0")
+	}
+	if m.DuckingRatio != 0 && (m.DuckingRatio < 1 || m.DuckingRatio > 20) {
+		return fmt.Errorf("ducking ratio needs to be between 1 and 20")
+	}
+	if m.FadeIn < 0 || m.FadeIn > 30 || m.FadeOut < 0 || m.FadeOut > 30 {
+		return fmt.Errorf("fade in and fade out need to be between 0 and 30 seconds")
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Package settings holds the voice and render settings that are used by the workers to
+// generate the videos of a project
+//
+// Settings are defined at the project level. Video segments can override the voice
+// settings of the project; render settings apply to the whole project as the videos of
+// the segments are concatenated without being re-encoded and need to share the same format
+//
+// Zero values are treated as unset and fall back to the project settings followed by
+// the defaults of the workers. This also means that a voice override cannot set the
+// pitch back to 0 if the project uses a different pitch
+package settings
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	GenderMale    = "MALE"
+	GenderFemale  = "FEMALE"
+	GenderNeutral = "NEUTRAL"
+)
+
+var videoCodecs = map[string]bool{
+	"libx264": true,
+	"libx265": true,
+}
+
+// Voice controls the text to speech generation of the script of a video segment
+type Voice struct {
+	LanguageCode string  `json:"language_code,omitempty" gorm:"type:varchar(20)"`
+	Name         string  `json:"name,omitempty" gorm:"type:varchar(100)"`
+	Gender       string  `json:"gender,omitempty" gorm:"type:varchar(20)"`
+	SpeakingRate float64 `json:"speaking_rate,omitempty"`
+	Pitch        float64 `json:"pitch,omitempty"`
+}
+
+// Render controls how the slides are turned into video
+// Width and height are left as 0 to keep the size of the slide images
+type Render struct {
+	Width        int    `json:"width,omitempty" gorm:"type:int"`
+	Height       int    `json:"height,omitempty" gorm:"type:int"`
+	FrameRate    int    `json:"frame_rate,omitempty" gorm:"type:int"`
+	VideoCodec   string `json:"video_codec,omitempty" gorm:"type:varchar(20)"`
+	CRF          int    `json:"crf,omitempty" gorm:"type:int"`
+	AudioBitrate int    `json:"audio_bitrate,omitempty" gorm:"type:int"`
+	// SlideDensity is the DPI used when the pdf is split into slide images
+	SlideDensity int `json:"slide_density,omitempty" gorm:"type:int"`
+}
+
+type Settings struct {
+	Voice  Voice  `json:"voice" gorm:"embedded;embedded_prefix:voice_"`
+	Render Render `json:"render" gorm:"embedded;embedded_prefix:render_"`
+}
+
+// Default are the settings that were previously hard coded in the workers
+func Default() Settings {
+	return Settings{
+		Voice: Voice{
+			LanguageCode: "en-US",
+			Gender:       GenderFemale,
+			SpeakingRate: 1.0,
+		},
+		Render: Render{
+			FrameRate:    25,
+			VideoCodec:   "libx264",
+			CRF:          23,
+			AudioBitrate: 128,
+			SlideDensity: 150,
+		},
+	}
+}
+
+// Effective resolves the settings to be used for a video segment from the settings of
+// the project and the voice override of the video segment
+func Effective(project Settings, override Voice) Settings {
+	s := Default().Merge(project)
+	s.Voice = s.Voice.Merge(override)
+	return s
+}
+
+// Merge replaces the values of the settings with the values that are set in the other settings
+func (s Settings) Merge(o Settings) Settings {
+	return Settings{
+		Voice:  s.Voice.Merge(o.Voice),
+		Render: s.Render.Merge(o.Render),
+	}
+}
+
+func (v Voice) Merge(o Voice) Voice {
+	if o.LanguageCode != "" {
+		v.LanguageCode = o.LanguageCode
+	}
+	if o.Name != "" {
+		v.Name = o.Name
+	}
+	if o.Gender != "" {
+		v.Gender = o.Gender
+	}
+	if o.SpeakingRate != 0 {
+		v.SpeakingRate = o.SpeakingRate
+	}
+	if o.Pitch != 0 {
+		v.Pitch = o.Pitch
+	}
+	return v
+}
+
+func (r Render) Merge(o Render) Render {
+	if o.Width != 0 || o.Height != 0 {
+		r.Width = o.Width
+		r.Height = o.Height
+	}
+	if o.FrameRate != 0 {
+		r.FrameRate = o.FrameRate
+	}
+	if o.VideoCodec != "" {
+		r.VideoCodec = o.VideoCodec
+	}
+	if o.CRF != 0 {
+		r.CRF = o.CRF
+	}
+	if o.AudioBitrate != 0 {
+		r.AudioBitrate = o.AudioBitrate
+	}
+	if o.SlideDensity != 0 {
+		r.SlideDensity = o.SlideDensity
+	}
+	return r
+}
+
+// Normalize cleans up the casing of the values so that they can be passed to the workers as is
+func (s Settings) Normalize() Settings {
+	s.Voice = s.Voice.Normalize()
+	s.Render.VideoCodec = strings.ToLower(strings.TrimSpace(s.Render.VideoCodec))
+	return s
+}
+
+func (v Voice) Normalize() Voice {
+	v.LanguageCode = strings.TrimSpace(v.LanguageCode)
+	v.Name = strings.TrimSpace(v.Name)
+	v.Gender = strings.ToUpper(strings.TrimSpace(v.Gender))
+	return v
+}
+
+func (s Settings) Validate() error {
+	err := s.Voice.Validate()
+	if err != nil {
+		return err
+	}
+	return s.Render.Validate()
+}
+
+// Validate checks the values that are set. Unset values are not checked as they are
+// filled in from the project settings or the defaults
+func (v Voice) Validate() error {
+	if len(v.LanguageCode) > 20 {
+		return fmt.Errorf("language code cannot be longer than 20 characters")
+	}
+	if len(v.Name) > 100 {
+		return fmt.Errorf("voice name cannot be longer than 100 characters")
+	}
+	switch v.Gender {
+	case "", GenderMale, GenderFemale, GenderNeutral:
+	default:
+		return fmt.Errorf("invalid voice gender %v. Only MALE, FEMALE and NEUTRAL are supported", v.Gender)
+	}
+	if v.SpeakingRate != 0 && (v.SpeakingRate < 0.25 || v.SpeakingRate > 4.0) {
+		return fmt.Errorf("speaking rate needs to be between 0.25 and 4.0")
+	}
+	if v.Pitch < -20.0 || v.Pitch > 20.0 {
+		return fmt.Errorf("pitch needs to be between -20.0 and 20.0")
+	}
+	return nil
+}
+
+func (r Render) Validate() error {
+	if (r.Width == 0) != (r.Height == 0) {
+		return fmt.Errorf("width and height need to be set together")
+	}
+	if r.Width < 0 || r.Height < 0 || r.Width > 3840 || r.Height > 3840 {
+		return fmt.Errorf("width and height need to be between 1 and 3840")
+	}
+	if r.Width%2 != 0 || r.Height%2 != 0 {
+		return fmt.Errorf("width and height need to be even numbers")
+	}
+	if r.FrameRate < 0 || r.FrameRate > 60 {
+		return fmt.Errorf("frame rate needs to be between 1 and 60")
+	}
+	if r.VideoCodec != "" && !videoCodecs[r.VideoCodec] {
+		return fmt.Errorf("invalid video codec %v. Only libx264 and libx265 are supported", r.VideoCodec)
+	}
+	if r.CRF < 0 || r.CRF > 51 {
+		return fmt.Errorf("crf needs to be between 1 and 51")
+	}
+	if r.AudioBitrate != 0 && (r.AudioBitrate < 32 || r.AudioBitrate > 320) {
+		return fmt.Errorf("audio bitrate needs to be between 32 and 320 kbps")
+	}
+	if r.SlideDensity != 0 && (r.SlideDensity < 50 || r.SlideDensity > 600) {
+		return fmt.Errorf("slide density needs to be between 50 and 600")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package settings
+
+import "testing"
+
+func TestEffective(t *testing.T) {
+	project := Settings{
+		Voice:  Voice{LanguageCode: "en-GB", Pitch: 2.0},
+		Render: Render{Width: 1280, Height: 720, FrameRate: 30},
+	}
+	override := Voice{Name: "en-GB-Wavenet-B", Gender: GenderMale}
+
+	s := Effective(project, override)
+	if s.Voice.LanguageCode != "en-GB" || s.Voice.Name != "en-GB-Wavenet-B" || s.Voice.Gender != GenderMale {
+		t.Errorf("unexpected voice settings. Voice: %+v", s.Voice)
+	}
+	if s.Voice.SpeakingRate != 1.0 || s.Voice.Pitch != 2.0 {
+		t.Errorf("expected unset voice settings to be inherited. Voice: %+v", s.Voice)
+	}
+	if s.Render.Width != 1280 || s.Render.Height != 720 || s.Render.FrameRate != 30 {
+		t.Errorf("unexpected render settings. Render: %+v", s.Render)
+	}
+	if s.Render.VideoCodec != "libx264" || s.Render.SlideDensity != 150 {
+		t.Errorf("expected unset render settings to use defaults. Render: %+v", s.Render)
+	}
+
+	if Effective(Settings{}, Voice{}) != Default() {
+		t.Errorf("expected defaults for projects without settings")
+	}
+}
+
+func TestSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		wantErr  bool
+	}{
+		{name: "empty", settings: Settings{}},
+		{name: "default", settings: Default()},
+		{name: "bad gender", settings: Settings{Voice: Voice{Gender: "ROBOT"}}, wantErr: true},
+		{name: "bad speaking rate", settings: Settings{Voice: Voice{SpeakingRate: 5}}, wantErr: true},
+		{name: "bad pitch", settings: Settings{Voice: Voice{Pitch: -21}}, wantErr: true},
+		{name: "width only", settings: Settings{Render: Render{Width: 1280}}, wantErr: true},
+		{name: "odd height", settings: Settings{Render: Render{Width: 1280, Height: 721}}, wantErr: true},
+		{name: "bad codec", settings: Settings{Render: Render{VideoCodec: "mpeg2video"}}, wantErr: true},
+		{name: "bad crf", settings: Settings{Render: Render{CRF: 52}}, wantErr: true},
+		{name: "bad audio bitrate", settings: Settings{Render: Render{AudioBitrate: 8}}, wantErr: true},
+		{name: "bad slide density", settings: Settings{Render: Render{SlideDensity: 1000}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveMusic(t *testing.T) {
+	project := Music{File: "project.mp3", Volume: 0.5, Ducking: true}
+	if m := ResolveMusic(project, Music{Volume: 0.8}); m != project {
+		t.Errorf("expected project music for video output without music file. Music: %+v", m)
+	}
+	output := Music{File: "output.mp3"}
+	m := ResolveMusic(project, output)
+	if m != output {
+		t.Errorf("expected music of video output to replace project music. Music: %+v", m)
+	}
+	m = m.WithDefaults()
+	if m.Volume != 0.3 || m.DuckingRatio != 8 || m.Ducking {
+		t.Errorf("unexpected defaults of music. Music: %+v", m)
+	}
+
+	if err := (Music{File: "music.mp3", Volume: 3}).Validate(); err == nil {
+		t.Errorf("expected error for music volume that is too loud")
+	}
+	if err := (Music{File: "music.mp3", FadeOut: 60}).Validate(); err == nil {
+		t.Errorf("expected error for fade that is too long")
+	}
+	if !IsMusicFormatSupported("Theme.MP3") || IsMusicFormatSupported("theme.mid") {
+		t.Errorf("unexpected music format check")
+	}
+}
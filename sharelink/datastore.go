@@ -0,0 +1,105 @@
+package sharelink
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger            logger.Logger
+	projectEntityName string
+	entityName        string
+	client            *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, projectEntity, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:            logger,
+		client:            ds,
+		entityName:        en,
+		projectEntityName: projectEntity,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e ShareLink) error {
+	projectKey := datastore.NameKey(g.projectEntityName, e.ProjectID, nil)
+	newKey := datastore.NameKey(g.entityName, e.ID, projectKey)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) Get(ctx context.Context, projectID, ID string) (ShareLink, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	key := datastore.NameKey(g.entityName, ID, projectKey)
+	l := ShareLink{}
+	if err := g.client.Get(ctx, key, &l); err != nil {
+		return ShareLink{}, fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+	}
+	l.ID = ID
+	l.ProjectID = projectID
+	return l, nil
+}
+
+func (g *googleDatastore) GetByToken(ctx context.Context, token string) (ShareLink, error) {
+	query := datastore.NewQuery(g.entityName).Filter("Token =", token).Limit(1)
+	links := []ShareLink{}
+	keys, err := g.client.GetAll(ctx, query, &links)
+	if err != nil {
+		return ShareLink{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	if len(keys) == 0 {
+		return ShareLink{}, fmt.Errorf("share link not found")
+	}
+	links[0].ID = keys[0].Name
+	links[0].ProjectID = keys[0].Parent.Name
+	return links[0], nil
+}
+
+func (g *googleDatastore) GetAll(ctx context.Context, projectID string, limit, after int) ([]ShareLink, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	query := datastore.NewQuery(g.entityName).Ancestor(projectKey).Order("-DateCreated").Limit(limit).Offset(after)
+	links := []ShareLink{}
+	keys, err := g.client.GetAll(ctx, query, &links)
+	if err != nil {
+		return []ShareLink{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	for i, key := range keys {
+		links[i].ID = key.Name
+		links[i].ProjectID = projectID
+	}
+	return links, nil
+}
+
+func (g *googleDatastore) Update(ctx context.Context, projectID, ID string, setters ...func(*ShareLink) error) (ShareLink, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	key := datastore.NameKey(g.entityName, ID, projectKey)
+	l := ShareLink{}
+	_, err := g.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(key, &l); err != nil {
+			return fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+		}
+		l.ID = ID
+		l.ProjectID = projectID
+		for _, setFunc := range setters {
+			err := setFunc(&l)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := tx.Put(key, &l)
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return ShareLink{}, fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return l, nil
+}
@@ -0,0 +1,73 @@
+package sharelink
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e ShareLink) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, projectID, ID string) (ShareLink, error) {
+	l := ShareLink{}
+	result := m.db.Where("id = ? AND project_id = ?", ID, projectID).First(&l)
+	if result.Error != nil {
+		return l, result.Error
+	}
+	return l, nil
+}
+
+func (m mysql) GetByToken(ctx context.Context, token string) (ShareLink, error) {
+	l := ShareLink{}
+	result := m.db.Where("token = ?", token).First(&l)
+	if result.Error != nil {
+		return l, result.Error
+	}
+	return l, nil
+}
+
+func (m mysql) GetAll(ctx context.Context, projectID string, Limit, After int) ([]ShareLink, error) {
+	var links []ShareLink
+	result := m.db.Where("project_id = ?", projectID).Order("date_created desc").Limit(Limit).Offset(After).Find(&links)
+	if result.Error != nil {
+		return []ShareLink{}, result.Error
+	}
+	return links, nil
+}
+
+func (m mysql) Update(ctx context.Context, projectID, ID string, setters ...func(*ShareLink) error) (ShareLink, error) {
+	l, err := m.Get(ctx, projectID, ID)
+	if err != nil {
+		return ShareLink{}, err
+	}
+	for _, s := range setters {
+		err := s(&l)
+		if err != nil {
+			return ShareLink{}, err
+		}
+	}
+	result := m.db.Save(&l)
+	if result.Error != nil {
+		return ShareLink{}, result.Error
+	}
+	return l, nil
+}
@@ -0,0 +1,139 @@
+// Package sharelink manages public links to the video of a project
+// Anyone with the link is able to watch and download the video as an anonymous viewer
+// without an account. Links can be protected with a password, expire and are revocable
+package sharelink
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrRevoked           = errors.New("Share link has been revoked")
+	ErrExpired           = errors.New("Share link has expired")
+	ErrPasswordRequired  = errors.New("Share link requires a password")
+	ErrPasswordIncorrect = errors.New("Password of share link is incorrect")
+	ErrPasswordLong      = errors.New("Password of share link cannot be longer than 120 characters")
+	ErrExpiryPassed      = errors.New("Expiry of share link needs to be in the future")
+)
+
+type ShareLink struct {
+	ID        string `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	ProjectID string `json:"project_id" datastore:"-" gorm:"type:varchar(40)"`
+	// VideoOutputID is empty for links to the main video of the project
+	VideoOutputID string `json:"video_output_id,omitempty" gorm:"type:varchar(40)"`
+	Token         string `json:"token" gorm:"type:varchar(60);unique_index"`
+	Password      string `json:"-" gorm:"type:varchar(250)"`
+	// PasswordProtected is shown to owners of the project as the password itself is not kept
+	PasswordProtected bool `json:"password_protected"`
+	// ExpiresAt is empty for links that do not expire
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ViewCount   int        `json:"view_count" gorm:"type:int"`
+	CreatedBy   string     `json:"created_by" gorm:"type:varchar(40)"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	DateCreated time.Time  `json:"date_created"`
+}
+
+// New creates a link to the main video of the project or to a video output of the project
+// An empty password leaves the link open to anyone with it
+func New(projectID, videoOutputID, createdBy, password string, expiresAt *time.Time) (ShareLink, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ShareLink{}, ErrExpiryPassed
+	}
+	if len(password) > 120 {
+		return ShareLink{}, ErrPasswordLong
+	}
+	token, err := newToken()
+	if err != nil {
+		return ShareLink{}, err
+	}
+	linkID, _ := uuid.NewV4()
+	link := ShareLink{
+		ID:            linkID.String(),
+		ProjectID:     projectID,
+		VideoOutputID: videoOutputID,
+		Token:         token,
+		ExpiresAt:     expiresAt,
+		CreatedBy:     createdBy,
+		DateCreated:   time.Now(),
+	}
+	if password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+		if err != nil {
+			return ShareLink{}, err
+		}
+		link.Password = string(hashedPassword)
+		link.PasswordProtected = true
+	}
+	return link, nil
+}
+
+// newToken generates the random part of the link that is given out to viewers
+func newToken() (string, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Active checks that the link can still be opened
+func (l ShareLink) Active(now time.Time) error {
+	if l.RevokedAt != nil {
+		return ErrRevoked
+	}
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// CheckPassword checks the password provided by the viewer. Links without a password
+// accept any password
+func (l ShareLink) CheckPassword(password string) error {
+	if !l.PasswordProtected {
+		return nil
+	}
+	if password == "" {
+		return ErrPasswordRequired
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(l.Password), []byte(password))
+	if err != nil {
+		return ErrPasswordIncorrect
+	}
+	return nil
+}
+
+// Revoke disables the link. Revoked links are kept so that their views remain visible
+func Revoke() ([]func(*ShareLink) error, error) {
+	var setters []func(*ShareLink) error
+	setters = append(setters, func(l *ShareLink) error {
+		if l.RevokedAt != nil {
+			return ErrRevoked
+		}
+		now := time.Now()
+		l.RevokedAt = &now
+		return nil
+	})
+	return setters, nil
+}
+
+// RecordView counts a viewer opening the link
+func RecordView() ([]func(*ShareLink) error, error) {
+	var setters []func(*ShareLink) error
+	setters = append(setters, func(l *ShareLink) error {
+		err := l.Active(time.Now())
+		if err != nil {
+			return err
+		}
+		l.ViewCount = l.ViewCount + 1
+		return nil
+	})
+	return setters, nil
+}
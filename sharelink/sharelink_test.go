@@ -0,0 +1,74 @@
+package sharelink
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	if _, err := New("project", "", "user", "", &past); err != ErrExpiryPassed {
+		t.Errorf("expected error for expiry in the past. Err: %v", err)
+	}
+
+	a, err := New("project", "", "user", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error when creating share link. Err: %v", err)
+	}
+	b, _ := New("project", "", "user", "", nil)
+	if a.Token == "" || a.Token == b.Token {
+		t.Errorf("expected random tokens for share links. %v %v", a.Token, b.Token)
+	}
+	if a.PasswordProtected || a.CheckPassword("") != nil {
+		t.Errorf("expected share link without password to be open")
+	}
+}
+
+func TestShareLink_CheckPassword(t *testing.T) {
+	l, _ := New("project", "", "user", "secret", nil)
+	if !l.PasswordProtected || l.Password == "secret" {
+		t.Fatalf("expected password to be hashed. %+v", l)
+	}
+	tests := []struct {
+		password string
+		want     error
+	}{
+		{password: "", want: ErrPasswordRequired},
+		{password: "wrong", want: ErrPasswordIncorrect},
+		{password: "secret", want: nil},
+	}
+	for _, tt := range tests {
+		if err := l.CheckPassword(tt.password); err != tt.want {
+			t.Errorf("CheckPassword(%q) = %v, want %v", tt.password, err, tt.want)
+		}
+	}
+}
+
+func TestShareLink_Active(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	l, _ := New("project", "", "user", "", &expiry)
+	if err := l.Active(time.Now()); err != nil {
+		t.Errorf("expected share link to be active. Err: %v", err)
+	}
+	if err := l.Active(expiry); err != ErrExpired {
+		t.Errorf("expected share link to be expired. Err: %v", err)
+	}
+
+	setters, _ := RecordView()
+	setters[0](&l)
+	if l.ViewCount != 1 {
+		t.Errorf("expected view to be counted. Views: %v", l.ViewCount)
+	}
+	setters, _ = Revoke()
+	setters[0](&l)
+	if err := l.Active(time.Now()); err != ErrRevoked {
+		t.Errorf("expected share link to be revoked. Err: %v", err)
+	}
+	if err := setters[0](&l); err != ErrRevoked {
+		t.Errorf("expected error when revoking share link again. Err: %v", err)
+	}
+	setters, _ = RecordView()
+	if err := setters[0](&l); err != ErrRevoked {
+		t.Errorf("expected views of revoked share link to not be counted. Err: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package sharelink
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e ShareLink) error
+	Get(ctx context.Context, projectID, ID string) (ShareLink, error)
+	// GetByToken retrieves the link that is opened by a viewer. Viewers only know the token
+	GetByToken(ctx context.Context, token string) (ShareLink, error)
+	// GetAll retrieves the links of the project. Links are sorted from the latest link
+	GetAll(ctx context.Context, projectID string, Limit, After int) ([]ShareLink, error)
+	Update(ctx context.Context, projectID, ID string, setters ...func(*ShareLink) error) (ShareLink, error)
+}
@@ -0,0 +1,132 @@
+// Package subtitles builds WebVTT and SRT subtitles for a video from the scripts of its
+// video segments
+//
+// Subtitle files share the name of the video they are generated for, e.g. the subtitles of
+// abc.mp4 are kept as abc.vtt and abc.srt. This keeps the subtitles of every version of a
+// video without needing to track them separately
+package subtitles
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	FormatVTT = "vtt"
+	FormatSRT = "srt"
+)
+
+// Formats lists the supported subtitle formats and the content type they are served as
+var Formats = map[string]string{
+	FormatVTT: "text/vtt",
+	FormatSRT: "application/x-subrip",
+}
+
+// Cue is a single subtitle that is shown between the start and end of the cue
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Clip is a video segment of the video with the script narrated in it
+type Clip struct {
+	Script   string
+	Duration time.Duration
+}
+
+// FileName is the name of the subtitle file in the format for the video file
+func FileName(videoFile, format string) string {
+	return strings.TrimSuffix(videoFile, filepath.Ext(videoFile)) + "." + format
+}
+
+// Build creates the cues of the clips that are played one after another
+// Padding is the silence before and after the narration of each clip. The narration of a clip
+// is split into sentences which are timed according to their length
+func Build(clips []Clip, padding time.Duration) []Cue {
+	cues := []Cue{}
+	offset := time.Duration(0)
+	for _, c := range clips {
+		start := offset + padding
+		speech := c.Duration - 2*padding
+		if speech <= 0 {
+			start = offset
+			speech = c.Duration
+		}
+		offset = offset + c.Duration
+
+		sentences := SplitSentences(c.Script)
+		totalLength := 0
+		for _, s := range sentences {
+			totalLength = totalLength + utf8.RuneCountInString(s)
+		}
+		for _, s := range sentences {
+			length := time.Duration(int64(speech) * int64(utf8.RuneCountInString(s)) / int64(totalLength))
+			cues = append(cues, Cue{
+				Start: start,
+				End:   start + length,
+				Text:  s,
+			})
+			start = start + length
+		}
+	}
+	return cues
+}
+
+// SplitSentences splits the script into sentences. Whitespace within a sentence is collapsed
+// so that each sentence fits within a single cue
+func SplitSentences(script string) []string {
+	sentences := []string{}
+	current := []rune{}
+	runes := []rune(script)
+	for i, r := range runes {
+		current = append(current, r)
+		if r != '.' && r != '!' && r != '?' && r != '\n' && r != '。' {
+			continue
+		}
+		// Punctuation in the middle of words such as 3.14 or e.g. does not end a sentence
+		if r != '\n' && r != '。' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		sentences = appendSentence(sentences, string(current))
+		current = []rune{}
+	}
+	return appendSentence(sentences, string(current))
+}
+
+func appendSentence(sentences []string, sentence string) []string {
+	sentence = strings.Join(strings.Fields(sentence), " ")
+	if sentence == "" {
+		return sentences
+	}
+	return append(sentences, sentence)
+}
+
+// WebVTT renders the cues as a WebVTT file
+func WebVTT(cues []Cue) []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		fmt.Fprintf(&b, "%v --> %v\n%v\n\n", timestamp(c.Start, "."), timestamp(c.End, "."), c.Text)
+	}
+	return b.Bytes()
+}
+
+// SRT renders the cues as a SubRip file
+func SRT(cues []Cue) []byte {
+	var b bytes.Buffer
+	for i, c := range cues {
+		fmt.Fprintf(&b, "%v\n%v --> %v\n%v\n\n", i+1, timestamp(c.Start, ","), timestamp(c.End, ","), c.Text)
+	}
+	return b.Bytes()
+}
+
+func timestamp(d time.Duration, separator string) string {
+	millis := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%v%03d", millis/3600000, millis/60000%60, millis/1000%60, separator, millis%1000)
+}
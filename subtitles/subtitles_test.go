@@ -0,0 +1,58 @@
+package subtitles
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "empty", script: "  ", want: []string{}},
+		{name: "single", script: "Hello world", want: []string{"Hello world"}},
+		{name: "multiple", script: "Hello world. How are you?  Fine!", want: []string{"Hello world.", "How are you?", "Fine!"}},
+		{name: "decimals", script: "Pi is 3.14 roughly. Yes.", want: []string{"Pi is 3.14 roughly.", "Yes."}},
+		{name: "new lines", script: "First line\nSecond   line", want: []string{"First line", "Second line"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSentences(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitSentences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	clips := []Clip{
+		{Script: "Four. Sentence eight.", Duration: 5 * time.Second},
+		{Script: "", Duration: 3 * time.Second},
+		{Script: "Last", Duration: 4 * time.Second},
+	}
+	cues := Build(clips, time.Second)
+	want := []Cue{
+		{Start: 1 * time.Second, End: 1*time.Second + 750*time.Millisecond, Text: "Four."},
+		{Start: 1*time.Second + 750*time.Millisecond, End: 4 * time.Second, Text: "Sentence eight."},
+		{Start: 9 * time.Second, End: 11 * time.Second, Text: "Last"},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("Build() = %+v, want %+v", cues, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	cues := []Cue{{Start: 1500 * time.Millisecond, End: time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, Text: "Hello."}}
+	if got := string(WebVTT(cues)); got != "WEBVTT\n\n00:00:01.500 --> 01:02:03.004\nHello.\n\n" {
+		t.Errorf("unexpected webvtt. Got: %q", got)
+	}
+	if got := string(SRT(cues)); got != "1\n00:00:01,500 --> 01:02:03,004\nHello.\n\n" {
+		t.Errorf("unexpected srt. Got: %q", got)
+	}
+	if got := FileName("abc.mp4", FormatVTT); got != "abc.vtt" {
+		t.Errorf("unexpected file name. Got: %v", got)
+	}
+}
@@ -0,0 +1,53 @@
+package team
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+)
+
+// ACLStore resolves the access of users on projects through the teams that they are members of
+// All other operations are passed on to the acl store that it wraps
+type ACLStore struct {
+	acl.Store
+	Teams Store
+}
+
+func NewACLStore(aclStore acl.Store, teamStore Store) ACLStore {
+	return ACLStore{
+		Store: aclStore,
+		Teams: teamStore,
+	}
+}
+
+// Get retrieves the acl of the user on the project. Access granted to the user directly on the
+// project takes precedence. Otherwise, the strongest of the access inherited from folders and
+// teams applies. Acls resolved from a team carry the ID of the team and keep the details of the
+// acl inherited from a folder if there is one
+func (s ACLStore) Get(ctx context.Context, projectID, userID string) (acl.ACL, error) {
+	userACL, err := s.Store.Get(ctx, projectID, userID)
+	if err == nil && userACL.InheritedFrom == "" {
+		return userACL, nil
+	}
+	teams, teamErr := s.Teams.GetByMember(ctx, userID)
+	if teamErr != nil || len(teams) == 0 {
+		return userACL, err
+	}
+	teamACLs, teamErr := s.Store.GetForTeams(ctx, projectID, IDs(teams))
+	if teamErr != nil || len(teamACLs) == 0 {
+		return userACL, err
+	}
+
+	resolved := userACL
+	if err != nil {
+		resolved = acl.ACL{ProjectID: projectID, UserID: userID}
+	}
+	for _, t := range teamACLs {
+		if resolved.Permission != "" && resolved.Permission.Stronger(t.Permission) {
+			continue
+		}
+		resolved.Permission = t.Permission
+		resolved.TeamID = t.TeamID
+	}
+	return resolved, nil
+}
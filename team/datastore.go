@@ -0,0 +1,92 @@
+package team
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger     logger.Logger
+	entityName string
+	client     *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:     logger,
+		client:     ds,
+		entityName: en,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e Team) error {
+	newKey := datastore.NameKey(g.entityName, e.ID, nil)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) Get(ctx context.Context, ID string) (Team, error) {
+	key := datastore.NameKey(g.entityName, ID, nil)
+	t := Team{}
+	if err := g.client.Get(ctx, key, &t); err != nil {
+		return Team{}, fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+	}
+	setIDs(&t, ID)
+	return t, nil
+}
+
+func (g *googleDatastore) GetByMember(ctx context.Context, userID string) ([]Team, error) {
+	query := datastore.NewQuery(g.entityName).Filter("Members.UserID =", userID)
+	teams := []Team{}
+	keys, err := g.client.GetAll(ctx, query, &teams)
+	if err != nil {
+		return []Team{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	for i, key := range keys {
+		setIDs(&teams[i], key.Name)
+	}
+	sort.SliceStable(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams, nil
+}
+
+func (g *googleDatastore) Update(ctx context.Context, ID string, setters ...func(*Team) error) (Team, error) {
+	key := datastore.NameKey(g.entityName, ID, nil)
+	t := Team{}
+	_, err := g.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(key, &t); err != nil {
+			return fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+		}
+		setIDs(&t, ID)
+		for _, setFunc := range setters {
+			err := setFunc(&t)
+			if err != nil {
+				return err
+			}
+		}
+		t.DateModified = time.Now()
+		_, err := tx.Put(key, &t)
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Team{}, err
+	}
+	return t, nil
+}
+
+func setIDs(t *Team, ID string) {
+	t.ID = ID
+	for i := range t.Members {
+		t.Members[i].TeamID = ID
+	}
+}
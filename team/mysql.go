@@ -0,0 +1,99 @@
+package team
+
+import (
+	"context"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e Team) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, member := range e.Members {
+		result = m.db.Save(&member)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, ID string) (Team, error) {
+	t := Team{}
+	result := m.db.Where("id = ?", ID).First(&t)
+	if result.Error != nil {
+		return t, result.Error
+	}
+	return t, m.loadMembers(&t)
+}
+
+func (m mysql) loadMembers(t *Team) error {
+	members := []Membership{}
+	result := m.db.Where("team_id = ?", t.ID).Find(&members)
+	if result.Error != nil {
+		return result.Error
+	}
+	t.Members = members
+	return nil
+}
+
+func (m mysql) GetByMember(ctx context.Context, UserID string) ([]Team, error) {
+	var teams []Team
+	result := m.db.Model(&Team{}).Select("teams.*").Joins("inner join memberships on memberships.team_id = teams.id").Where("memberships.user_id = ?", UserID).Order("teams.name").Find(&teams)
+	if result.Error != nil {
+		return []Team{}, result.Error
+	}
+	for i := range teams {
+		err := m.loadMembers(&teams[i])
+		if err != nil {
+			return []Team{}, err
+		}
+	}
+	return teams, nil
+}
+
+func (m mysql) Update(ctx context.Context, ID string, setters ...func(*Team) error) (Team, error) {
+	t, err := m.Get(ctx, ID)
+	if err != nil {
+		return Team{}, err
+	}
+	for _, s := range setters {
+		err := s(&t)
+		if err != nil {
+			return Team{}, err
+		}
+	}
+	t.DateModified = time.Now()
+	// Members are replaced as a whole as they could have been removed by the setters
+	result := m.db.Where("team_id = ?", ID).Delete(Membership{})
+	if result.Error != nil {
+		return Team{}, result.Error
+	}
+	result = m.db.Save(&t)
+	if result.Error != nil {
+		return Team{}, result.Error
+	}
+	for _, member := range t.Members {
+		result = m.db.Save(&member)
+		if result.Error != nil {
+			return Team{}, result.Error
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,11 @@
+package team
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, e Team) error
+	Get(ctx context.Context, ID string) (Team, error)
+	// GetByMember returns the teams that the user is a member of
+	GetByMember(ctx context.Context, UserID string) ([]Team, error)
+	Update(ctx context.Context, ID string, setters ...func(*Team) error) (Team, error)
+}
@@ -0,0 +1,173 @@
+// Package team groups users into teams that can be granted access to projects
+//
+// Projects can be shared with a team or owned by a team. Members of the team inherit the
+// access that the team has on the project. Access granted to a user directly on the project
+// takes precedence over the access of their teams
+package team
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Role is the role of a member within the team
+type Role string
+
+// Owner of the team - able to rename the team, manage its members and create projects that
+// are owned by the team
+var Owner Role = "owner"
+
+// Member of the team - inherits the access of the team on projects
+var Member Role = "member"
+
+// ParseRole converts the name of a role into a role
+func ParseRole(raw string) (Role, error) {
+	switch Role(raw) {
+	case Owner, Member:
+		return Role(raw), nil
+	}
+	return "", fmt.Errorf("invalid team role %v", raw)
+}
+
+// Membership places a user in the team
+type Membership struct {
+	TeamID      string    `json:"-" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	UserID      string    `json:"user_id" gorm:"type:varchar(40);primary_key"`
+	Role        Role      `json:"role" gorm:"type:varchar(20)"`
+	DateCreated time.Time `json:"date_created"`
+}
+
+type Team struct {
+	ID           string       `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	Name         string       `json:"name" gorm:"type:varchar(250)"`
+	Members      []Membership `json:"members"`
+	DateCreated  time.Time    `json:"date_created"`
+	DateModified time.Time    `json:"date_modified"`
+}
+
+// New creates a team with the user that created it as its owner
+func New(name, ownerID string) (Team, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Team{}, fmt.Errorf("team name cannot be empty")
+	}
+	teamID, _ := uuid.NewV4()
+	currentTime := time.Now()
+	return Team{
+		ID:   teamID.String(),
+		Name: name,
+		Members: []Membership{{
+			TeamID:      teamID.String(),
+			UserID:      ownerID,
+			Role:        Owner,
+			DateCreated: currentTime,
+		}},
+		DateCreated:  currentTime,
+		DateModified: currentTime,
+	}, nil
+}
+
+// Member retrieves the membership of the user in the team
+func (t *Team) Member(userID string) (Membership, bool) {
+	for _, m := range t.Members {
+		if m.UserID == userID {
+			return m, true
+		}
+	}
+	return Membership{}, false
+}
+
+// IsOwner checks if the user is an owner of the team
+func (t *Team) IsOwner(userID string) bool {
+	m, exists := t.Member(userID)
+	return exists && m.Role == Owner
+}
+
+func SetName(name string) ([]func(*Team) error, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("team name cannot be empty")
+	}
+	var setters []func(*Team) error
+	setters = append(setters, func(t *Team) error {
+		t.Name = name
+		return nil
+	})
+	return setters, nil
+}
+
+// SetMember adds a user to the team or changes the role of an existing member
+func SetMember(userID, role string) ([]func(*Team) error, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user is required to add member to team")
+	}
+	r, err := ParseRole(role)
+	if err != nil {
+		return nil, err
+	}
+	var setters []func(*Team) error
+	setters = append(setters, func(t *Team) error {
+		for i, m := range t.Members {
+			if m.UserID == userID {
+				if m.Role == Owner && r != Owner && t.ownerCount() == 1 {
+					return fmt.Errorf("team needs to have at least one owner")
+				}
+				t.Members[i].Role = r
+				return nil
+			}
+		}
+		t.Members = append(t.Members, Membership{
+			TeamID:      t.ID,
+			UserID:      userID,
+			Role:        r,
+			DateCreated: time.Now(),
+		})
+		return nil
+	})
+	return setters, nil
+}
+
+// RemoveMember takes the user out of the team. The user loses the access inherited from the team
+func RemoveMember(userID string) ([]func(*Team) error, error) {
+	var setters []func(*Team) error
+	setters = append(setters, func(t *Team) error {
+		members := []Membership{}
+		for _, m := range t.Members {
+			if m.UserID != userID {
+				members = append(members, m)
+				continue
+			}
+			if m.Role == Owner && t.ownerCount() == 1 {
+				return fmt.Errorf("team needs to have at least one owner")
+			}
+		}
+		if len(members) == len(t.Members) {
+			return fmt.Errorf("user is not a member of the team")
+		}
+		t.Members = members
+		return nil
+	})
+	return setters, nil
+}
+
+func (t *Team) ownerCount() int {
+	count := 0
+	for _, m := range t.Members {
+		if m.Role == Owner {
+			count = count + 1
+		}
+	}
+	return count
+}
+
+// IDs lists the IDs of the teams
+func IDs(teams []Team) []string {
+	ids := []string{}
+	for _, t := range teams {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
@@ -0,0 +1,145 @@
+package team
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/acl"
+)
+
+func TestSetMember(t *testing.T) {
+	item, _ := New("Marketing", "owner")
+
+	setters, _ := SetMember("member", string(Member))
+	if err := setters[0](&item); err != nil {
+		t.Fatalf("unexpected error when adding member. Err: %v", err)
+	}
+	if m, _ := item.Member("member"); m.Role != Member || m.TeamID != item.ID {
+		t.Errorf("expected user to be added as member. Membership: %+v", m)
+	}
+
+	setters, _ = SetMember("owner", string(Member))
+	if err := setters[0](&item); err == nil {
+		t.Errorf("expected error when demoting last owner of team")
+	}
+
+	if _, err := SetMember("member", "admin"); err == nil {
+		t.Errorf("expected error for unknown team role")
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	item, _ := New("Marketing", "owner")
+	setters, _ := SetMember("member", string(Member))
+	setters[0](&item)
+
+	setters, _ = RemoveMember("owner")
+	if err := setters[0](&item); err == nil {
+		t.Errorf("expected error when removing last owner of team")
+	}
+	setters, _ = RemoveMember("stranger")
+	if err := setters[0](&item); err == nil {
+		t.Errorf("expected error when removing user that is not a member")
+	}
+	setters, _ = RemoveMember("member")
+	if err := setters[0](&item); err != nil || len(item.Members) != 1 {
+		t.Errorf("expected member to be removed. Err: %v Members: %+v", err, item.Members)
+	}
+}
+
+type fakeTeamStore struct {
+	teams map[string][]Team
+}
+
+func (f fakeTeamStore) Create(ctx context.Context, e Team) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (f fakeTeamStore) Get(ctx context.Context, ID string) (Team, error) {
+	return Team{}, fmt.Errorf("not implemented")
+}
+
+func (f fakeTeamStore) GetByMember(ctx context.Context, userID string) ([]Team, error) {
+	return f.teams[userID], nil
+}
+
+func (f fakeTeamStore) Update(ctx context.Context, ID string, setters ...func(*Team) error) (Team, error) {
+	return Team{}, fmt.Errorf("not implemented")
+}
+
+type fakeACLStore struct {
+	acl.Store
+	acls []acl.ACL
+}
+
+func (f fakeACLStore) Get(ctx context.Context, projectID, userID string) (acl.ACL, error) {
+	for _, a := range f.acls {
+		if a.ProjectID == projectID && a.UserID == userID {
+			return a, nil
+		}
+	}
+	return acl.ACL{}, fmt.Errorf("acl not found")
+}
+
+func (f fakeACLStore) GetForTeams(ctx context.Context, projectID string, teamIDs []string) ([]acl.ACL, error) {
+	teamACLs := []acl.ACL{}
+	for _, a := range f.acls {
+		for _, teamID := range teamIDs {
+			if a.ProjectID == projectID && a.IsTeam() && a.TeamID == teamID {
+				teamACLs = append(teamACLs, a)
+			}
+		}
+	}
+	return teamACLs, nil
+}
+
+func TestACLStore_Get(t *testing.T) {
+	direct := acl.New("project", "direct")
+	direct.Permission = acl.Reader
+	folderEditor := acl.NewInherited("project", "folder", "folder-1", acl.Editor)
+	folderReader := acl.NewInherited("project", "folder-reader", "folder-1", acl.Reader)
+	store := NewACLStore(fakeACLStore{acls: []acl.ACL{
+		direct,
+		folderEditor,
+		folderReader,
+		acl.NewTeam("project", "editors", acl.Editor),
+		acl.NewTeam("project", "owners", acl.Owner),
+	}}, fakeTeamStore{teams: map[string][]Team{
+		"direct":        {{ID: "owners"}},
+		"folder":        {{ID: "editors"}},
+		"folder-reader": {{ID: "editors"}},
+		"member":        {{ID: "editors"}, {ID: "owners"}},
+		"outsider":      {{ID: "other"}},
+	}})
+
+	tests := []struct {
+		name          string
+		userID        string
+		want          acl.Permission
+		teamID        string
+		inheritedFrom string
+		wantErr       bool
+	}{
+		{name: "direct access takes precedence", userID: "direct", want: acl.Reader},
+		{name: "folder kept when team is not stronger", userID: "folder", want: acl.Editor, inheritedFrom: "folder-1"},
+		{name: "team stronger than folder", userID: "folder-reader", want: acl.Editor, teamID: "editors", inheritedFrom: "folder-1"},
+		{name: "strongest team wins", userID: "member", want: acl.Owner, teamID: "owners"},
+		{name: "team without access", userID: "outsider", wantErr: true},
+		{name: "no teams", userID: "stranger", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Get(context.Background(), "project", tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Permission != tt.want || got.TeamID != tt.teamID || got.InheritedFrom != tt.inheritedFrom || got.UserID != tt.userID {
+				t.Errorf("Get() = %+v, want %v from team %q and folder %q", got, tt.want, tt.teamID, tt.inheritedFrom)
+			}
+		})
+	}
+}
@@ -280,6 +280,446 @@ def test_list_projects(base_endpoint, create_user, login, create_project):
 
     project_list = resp.json()
     assert len(project_list["projects"]) == 3
+    assert project_list["total"] == 3
+
+
+def test_search_projects(base_endpoint, create_user, login, create_project, update_project):
+    create_user(base_endpoint, "user2-1", "TestPassword123")
+    login(base_endpoint, "user2-1", "TestPassword123")
+    first = create_project(base_endpoint)
+    create_project(base_endpoint)
+    update_project(base_endpoint, first["id"], {"name": "Quarterly review"})
+    endpoint = base_endpoint + "/projects"
+
+    resp = requests.get(endpoint, params={"name": "review"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    project_list = resp.json()
+    assert project_list["total"] == 1
+    assert project_list["projects"][0]["id"] == first["id"]
+
+    resp = requests.get(endpoint, params={"limit": 1, "sort_by": "name", "order": "asc"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    page = resp.json()
+    assert len(page["projects"]) == 1
+    resp = requests.get(endpoint, params={"limit": 1, "sort_by": "name", "order": "asc", "cursor": page["next_cursor"]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["projects"][0]["id"] != page["projects"][0]["id"]
+
+    resp = requests.get(endpoint, params={"sort_by": "status"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+
+def test_trash_and_restore_project(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-2", "TestPassword123")
+    login(base_endpoint, "user2-2", "TestPassword123")
+    project = create_project(base_endpoint)
+    create_project(base_endpoint)
+    project_endpoint = base_endpoint + "/project/" + project["id"]
+
+    resp = requests.delete(project_endpoint, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["deleted_at"] != ""
+    resp = requests.get(project_endpoint, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 404
+
+    resp = requests.get(base_endpoint + "/projects", cookies=sess.cookies.get_dict())
+    assert resp.json()["total"] == 1
+    resp = requests.get(base_endpoint + "/projects", params={"trashed": "true"}, cookies=sess.cookies.get_dict())
+    assert resp.json()["projects"][0]["id"] == project["id"]
+
+    resp = requests.post(project_endpoint + ":restore", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.get(project_endpoint, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+
+
+def test_folders_and_tags(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-3", "TestPassword123")
+    create_user(base_endpoint, "user2-4", "TestPassword123")
+    login(base_endpoint, "user2-3", "TestPassword123")
+    project = create_project(base_endpoint)
+    create_project(base_endpoint)
+
+    resp = requests.post(base_endpoint + "/folder", json={"name": "Team"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    team_folder = resp.json()
+    resp = requests.post(base_endpoint + "/folder", json={"name": "Reviews", "parent_id": team_folder["id"]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    review_folder = resp.json()
+
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + ":move", json={"folder_id": review_folder["id"]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/tags", json={"tags": ["finance", "q3"]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["tags"] == ["finance", "q3"]
+
+    resp = requests.get(base_endpoint + "/projects", params={"tag": "q3"}, cookies=sess.cookies.get_dict())
+    assert resp.json()["total"] == 1
+    resp = requests.get(base_endpoint + "/projects", params={"folder_id": review_folder["id"]}, cookies=sess.cookies.get_dict())
+    assert resp.json()["projects"][0]["id"] == project["id"]
+
+    resp = requests.put(base_endpoint + "/folder/" + team_folder["id"] + "/share", json={"email": "user2-4", "permission": "reader"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+
+    login(base_endpoint, "user2-4", "TestPassword123")
+    resp = requests.get(base_endpoint + "/projects", cookies=sess.cookies.get_dict())
+    assert resp.json()["total"] == 1
+    resp = requests.get(base_endpoint + "/project/" + project["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/tags", json={"tags": []}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+
+
+def test_project_settings(base_endpoint, create_user, login, create_project, get_project):
+    create_user(base_endpoint, "user2-5", "TestPassword123")
+    login(base_endpoint, "user2-5", "TestPassword123")
+    project = create_project(base_endpoint)
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/settings", json={"voice": {"language_code": "en-GB", "gender": "male"}, "render": {"width": 1280, "height": 720}}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["settings"]["voice"]["gender"] == "MALE"
+    assert resp.json()["effective"]["render"]["frame_rate"] == 25
+    assert resp.json()["effective"]["render"]["width"] == 1280
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/settings", json={"render": {"video_codec": "mpeg2video"}}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+    project = get_project(base_endpoint, project["id"])
+    assert project["settings"]["voice"]["language_code"] == "en-GB"
+
+
+def test_project_languages(base_endpoint, create_user, login, create_project, get_project):
+    create_user(base_endpoint, "user2-6", "TestPassword123")
+    login(base_endpoint, "user2-6", "TestPassword123")
+    project = create_project(base_endpoint)
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/languages", json={"languages": [{"code": "fr-FR", "voice": {"gender": "male"}}, {"code": "ja-JP"}]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["primary_language"] == "en-US"
+    assert [l["code"] for l in resp.json()["languages"]] == ["fr-FR", "ja-JP"]
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/languages", json={"languages": [{"code": "en-US"}]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+    project = get_project(base_endpoint, project["id"])
+    assert len(project["languages"]) == 2
+
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + ":generate-video?language=de-DE", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+
+def test_project_background_music(base_endpoint, create_user, login, create_project, get_project):
+    create_user(base_endpoint, "user2-7", "TestPassword123")
+    login(base_endpoint, "user2-7", "TestPassword123")
+    project = create_project(base_endpoint)
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/music", json={"volume": 0.5}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+    files = {'myfile': ('music.txt', b'not music')}
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/music", files=files, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+    files = {'myfile': ('music.mp3', b'music')}
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/music", files=files, data={"volume": "0.2", "ducking": "true", "fade_in": "2"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    music_file = resp.json()["music"]["file"]
+    assert music_file.endswith(".mp3")
+    assert resp.json()["music"]["ducking"] == True
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/music", json={"volume": 0.4, "ducking": False, "fade_out": 3}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["music"]["file"] == music_file
+    assert resp.json()["music"]["volume"] == 0.4
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/music", json={"volume": 5}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+    resp = requests.delete(base_endpoint + "/project/" + project["id"] + "/music", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    project = get_project(base_endpoint, project["id"])
+    assert project["music"].get("file") is None
+
+
+def test_project_subtitles(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-8", "TestPassword123")
+    login(base_endpoint, "user2-8", "TestPassword123")
+    project = create_project(base_endpoint)
+
+    resp = requests.get(base_endpoint + "/project/" + project["id"] + "/subtitles/ass", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+    resp = requests.get(base_endpoint + "/project/" + project["id"] + "/subtitles/vtt", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 404
+
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/videooutput", json={"name": "captioned", "burn_subtitles": True}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    assert resp.json()["burn_subtitles"] == True
+
+    resp = requests.get(base_endpoint + "/project/" + project["id"] + "/videooutput/" + resp.json()["id"] + "/subtitles/srt", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 404
+
+
+def test_project_collaborators(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-9", "TestPassword123")
+    create_user(base_endpoint, "user2-10", "TestPassword123")
+    login(base_endpoint, "user2-9", "TestPassword123")
+    project = create_project(base_endpoint)
+
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/collaborators", json={"email": "user2-10", "permission": "editor"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    collaborator_id = resp.json()["user_id"]
+    owner_id = [a["user_id"] for a in requests.get(base_endpoint + "/project/" + project["id"] + "/collaborators", cookies=sess.cookies.get_dict()).json()["collaborators"] if a["permission"] == "owner"][0]
+
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/collaborators", json={"email": "user2-10", "permission": "reader"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 409
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/collaborators", json={"email": "missing-user", "permission": "reader"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 404
+
+    resp = requests.get(base_endpoint + "/project/" + project["id"] + "/collaborators", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert sorted([a["email"] for a in resp.json()["collaborators"]]) == ["user2-10", "user2-9"]
+
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/collaborators/" + owner_id, json={"permission": "editor"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+    resp = requests.delete(base_endpoint + "/project/" + project["id"] + "/collaborators/" + owner_id, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+
+    login(base_endpoint, "user2-10", "TestPassword123")
+    resp = requests.get(base_endpoint + "/project/" + project["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/collaborators/" + collaborator_id, json={"permission": "owner"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+
+    login(base_endpoint, "user2-9", "TestPassword123")
+    resp = requests.put(base_endpoint + "/project/" + project["id"] + "/collaborators/" + collaborator_id, json={"permission": "reader"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["permission"] == "reader"
+    resp = requests.delete(base_endpoint + "/project/" + project["id"] + "/collaborators/" + collaborator_id, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 204
+
+    login(base_endpoint, "user2-10", "TestPassword123")
+    resp = requests.get(base_endpoint + "/project/" + project["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code != 200
+
+
+def test_project_subresource_acl(base_endpoint, create_user, login, create_project, create_videosegment):
+    create_user(base_endpoint, "user2-11", "TestPassword123")
+    create_user(base_endpoint, "user2-12", "TestPassword123")
+    login(base_endpoint, "user2-11", "TestPassword123")
+    project = create_project(base_endpoint)
+    videosegment = create_videosegment(base_endpoint, project["id"], "image.png", 1)
+    videosegment_endpoint = base_endpoint + "/project/" + project["id"] + "/videosegment/" + videosegment["id"]
+
+    resp = requests.put(videosegment_endpoint, json={"script": "Hello"})
+    assert resp.status_code == 401
+
+    login(base_endpoint, "user2-12", "TestPassword123")
+    resp = requests.get(videosegment_endpoint, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+    resp = requests.put(videosegment_endpoint, json={"script": "Hello"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+
+    login(base_endpoint, "user2-11", "TestPassword123")
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/collaborators", json={"email": "user2-12", "permission": "reader"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+
+    login(base_endpoint, "user2-12", "TestPassword123")
+    resp = requests.get(videosegment_endpoint, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.post(videosegment_endpoint + ":generate", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+
+
+def test_project_publisher_role(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-13", "TestPassword123")
+    create_user(base_endpoint, "user2-14", "TestPassword123")
+    login(base_endpoint, "user2-13", "TestPassword123")
+    project = create_project(base_endpoint)
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/collaborators", json={"email": "user2-14", "permission": "publisher"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+
+    login(base_endpoint, "user2-14", "TestPassword123")
+    resp = requests.get(base_endpoint + "/project/" + project["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.put(base_endpoint + "/project/" + project["id"], json={"name": "Published"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.delete(base_endpoint + "/project/" + project["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/collaborators", json={"email": "user2-13", "permission": "reader"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+
+
+def test_project_share_links(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-15", "TestPassword123")
+    create_user(base_endpoint, "user2-16", "TestPassword123")
+    login(base_endpoint, "user2-15", "TestPassword123")
+    project = create_project(base_endpoint)
+    resp = requests.post(base_endpoint + "/project/" + project["id"] + "/sharelinks", json={"password": "SharePassword"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    link = resp.json()
+    assert link["password_protected"] is True
+    assert link["view_count"] == 0
+    assert link.get("password") is None
+
+    viewer = requests.Session()
+    resp = viewer.get(base_endpoint + "/share/" + link["token"])
+    assert resp.status_code == 401
+    resp = viewer.post(base_endpoint + "/share/" + link["token"], json={"password": "WrongPassword"})
+    assert resp.status_code == 401
+    resp = viewer.get(base_endpoint + "/share/" + link["token"] + "/video")
+    assert resp.status_code == 401
+    resp = viewer.post(base_endpoint + "/share/" + link["token"], json={"password": "SharePassword"})
+    assert resp.status_code == 200
+    assert resp.json()["title"] == project["name"]
+    # Video has not been generated for the project yet
+    resp = viewer.get(base_endpoint + "/share/" + link["token"] + "/video")
+    assert resp.status_code == 404
+
+    login(base_endpoint, "user2-16", "TestPassword123")
+    resp = requests.get(base_endpoint + "/project/" + project["id"] + "/sharelinks", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+
+    login(base_endpoint, "user2-15", "TestPassword123")
+    resp = requests.get(base_endpoint + "/project/" + project["id"] + "/sharelinks", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["share_links"][0]["view_count"] == 1
+    resp = requests.delete(base_endpoint + "/project/" + project["id"] + "/sharelinks/" + link["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = viewer.get(base_endpoint + "/share/" + link["token"])
+    assert resp.status_code == 404
+
+
+def test_project_teams(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-17", "TestPassword123")
+    create_user(base_endpoint, "user2-18", "TestPassword123")
+    create_user(base_endpoint, "user2-19", "TestPassword123")
+    login(base_endpoint, "user2-17", "TestPassword123")
+    resp = requests.post(base_endpoint + "/team", json={"name": "Marketing"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    team = resp.json()
+    resp = requests.post(base_endpoint + "/team/" + team["id"] + "/members", json={"email": "user2-18", "role": "member"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert len(resp.json()["members"]) == 2
+
+    resp = requests.post(base_endpoint + "/project", json={"team_id": team["id"]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    team_project = resp.json()
+    assert team_project["team_id"] == team["id"]
+    user_project = create_project(base_endpoint)
+    resp = requests.put(base_endpoint + "/project/" + user_project["id"] + "/teams/" + team["id"], json={"permission": "reader"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+
+    login(base_endpoint, "user2-18", "TestPassword123")
+    resp = requests.get(base_endpoint + "/projects", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    project_ids = [p["id"] for p in resp.json()["projects"]]
+    assert team_project["id"] in project_ids
+    assert user_project["id"] in project_ids
+    resp = requests.put(base_endpoint + "/project/" + team_project["id"], json={"name": "Team Project"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.put(base_endpoint + "/project/" + user_project["id"], json={"name": "Read Only"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code != 200
+    resp = requests.post(base_endpoint + "/project", json={"team_id": team["id"]}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 403
+
+    login(base_endpoint, "user2-19", "TestPassword123")
+    resp = requests.get(base_endpoint + "/project/" + team_project["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code != 200
+
+
+def test_personal_access_tokens(base_endpoint, create_user, login, create_project):
+    create_user(base_endpoint, "user2-20", "TestPassword123")
+    login(base_endpoint, "user2-20", "TestPassword123")
+    project = create_project(base_endpoint)
+    resp = requests.post(base_endpoint + "/users/tokens", json={"name": "ci", "scope": "read-only", "expires_at": "2099-01-01T00:00:00Z"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    read_only = resp.json()
+    assert read_only["token"].startswith(read_only["hint"])
+    resp = requests.post(base_endpoint + "/users/tokens", json={"name": "deploy", "scope": "project-write", "expires_at": "2099-01-01T00:00:00Z"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 201
+    project_write = resp.json()
+    resp = requests.post(base_endpoint + "/users/tokens", json={"name": "expired", "scope": "read-only", "expires_at": "2000-01-01T00:00:00Z"}, cookies=sess.cookies.get_dict())
+    assert resp.status_code == 400
+    resp = requests.get(base_endpoint + "/users/tokens", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert len(resp.json()["access_tokens"]) == 2
+    assert "token" not in resp.json()["access_tokens"][0]
+
+    read_only_headers = {"Authorization": "Bearer " + read_only["token"]}
+    project_write_headers = {"Authorization": "Bearer " + project_write["token"]}
+    resp = requests.get(base_endpoint + "/project/" + project["id"], headers=read_only_headers)
+    assert resp.status_code == 200
+    resp = requests.put(base_endpoint + "/project/" + project["id"], json={"name": "CI Project"}, headers=read_only_headers)
+    assert resp.status_code == 403
+    resp = requests.put(base_endpoint + "/project/" + project["id"], json={"name": "CI Project"}, headers=project_write_headers)
+    assert resp.status_code == 200
+    resp = requests.post(base_endpoint + "/users/tokens", json={"name": "nested", "scope": "read-only", "expires_at": "2099-01-01T00:00:00Z"}, headers=project_write_headers)
+    assert resp.status_code == 403
+
+    resp = requests.delete(base_endpoint + "/users/tokens/" + read_only["id"], cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    resp = requests.get(base_endpoint + "/project/" + project["id"], headers=read_only_headers)
+    assert resp.status_code == 401
+
+
+def test_sessions(base_endpoint, create_user):
+    create_user(base_endpoint, "user2-21", "TestPassword123")
+    resp = requests.post(base_endpoint + "/login", json={"email": "user2-21", "password": "TestPassword123"})
+    assert resp.status_code == 200
+    first = resp.json()
+    assert first["token"] != ""
+    assert first["refresh_token"] != ""
+    first_cookies = resp.cookies.get_dict()
+    resp = requests.get(base_endpoint + "/projects", cookies=first_cookies)
+    assert resp.status_code == 200
+
+    resp = requests.post(base_endpoint + "/token/refresh", json={"refresh_token": first["refresh_token"]})
+    assert resp.status_code == 200
+    refreshed = resp.json()
+    assert refreshed["refresh_token"] != first["refresh_token"]
+    resp = requests.post(base_endpoint + "/token/refresh", json={"refresh_token": first["refresh_token"]})
+    assert resp.status_code == 401
+    resp = requests.get(base_endpoint + "/projects", headers={"Authorization": "Bearer " + refreshed["token"]})
+    assert resp.status_code == 200
+
+    resp = requests.post(base_endpoint + "/logout", headers={"Authorization": "Bearer " + refreshed["token"]})
+    assert resp.status_code == 200
+    resp = requests.get(base_endpoint + "/projects", headers={"Authorization": "Bearer " + first["token"]})
+    assert resp.status_code == 401
+    resp = requests.post(base_endpoint + "/token/refresh", json={"refresh_token": refreshed["refresh_token"]})
+    assert resp.status_code == 401
+
+    resp = requests.post(base_endpoint + "/login", json={"email": "user2-21", "password": "TestPassword123"})
+    assert resp.status_code == 200
+    second = resp.json()
+    resp = requests.post(base_endpoint + "/login", json={"email": "user2-21", "password": "TestPassword123"})
+    third_cookies = resp.cookies.get_dict()
+    resp = requests.put(base_endpoint + "/users/password", json={"current_password": "Wrong", "password": "TestPassword456"}, headers={"Authorization": "Bearer " + second["token"]})
+    assert resp.status_code == 403
+    resp = requests.put(base_endpoint + "/users/password", json={"current_password": "TestPassword123", "password": "TestPassword456"}, headers={"Authorization": "Bearer " + second["token"]})
+    assert resp.status_code == 200
+    resp = requests.get(base_endpoint + "/projects", headers={"Authorization": "Bearer " + second["token"]})
+    assert resp.status_code == 401
+    resp = requests.get(base_endpoint + "/projects", cookies=third_cookies)
+    assert resp.status_code == 401
+    resp = requests.post(base_endpoint + "/login", json={"email": "user2-21", "password": "TestPassword456"})
+    assert resp.status_code == 200
+
+
+def test_jwks(base_endpoint):
+    resp = requests.get(base_endpoint.replace("/api/v1", "") + "/.well-known/jwks.json")
+    assert resp.status_code == 200
+    for key in resp.json()["keys"]:
+        assert key["kid"] != ""
+        assert key["alg"] in ["RS256", "EdDSA"]
+
+
+def test_oidc_unknown_provider(base_endpoint):
+    resp = requests.get(base_endpoint + "/connect/oidc/unknown", allow_redirects=False)
+    assert resp.status_code == 404
+    resp = requests.get(base_endpoint + "/callback/oidc/unknown?code=code&state=state", allow_redirects=False)
+    assert resp.status_code == 404
 
 
 def test_add_pdf_slides(base_endpoint, create_user, login, create_project, create_pdfslideimages):
@@ -335,6 +775,30 @@ def test_update_script(base_endpoint, create_user, login, create_project, get_pr
         assert z["status"] == "created"
 
 
+def test_script_history(base_endpoint, create_user, login, create_project, get_project, create_pdfslideimages, await_pdf_slides, update_videosegment):
+    create_user(base_endpoint, "user6b", "TestPassword123")
+    login(base_endpoint, "user6b", "TestPassword123")
+    project = create_project(base_endpoint)
+    create_pdfslideimages(base_endpoint, project["id"])
+    await_pdf_slides(base_endpoint, project["id"])
+    time.sleep(1)
+    project = get_project(base_endpoint, project["id"])
+    v = project["video_segments"][0]
+    update_videosegment(base_endpoint, project["id"], v["id"], {"script": "hello"})
+    updated = update_videosegment(base_endpoint, project["id"], v["id"], {"script": "hello world"})
+    assert updated["script_revision"] == 2
+    segment_endpoint = base_endpoint + "/project/" + project["id"] + "/videosegment/" + v["id"]
+    resp = sess.get(segment_endpoint + "/scriptrevision", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    revisions = resp.json()["revisions"]
+    assert len(revisions) == 2
+    assert revisions[0]["revision"] == 2
+    assert revisions[0]["diff"] == "- hello\n+ hello world"
+    resp = sess.post(segment_endpoint + "/scriptrevision/1:revert", cookies=sess.cookies.get_dict())
+    assert resp.status_code == 200
+    assert resp.json()["script"] == "hello"
+    assert resp.json()["script_revision"] == 3
+
 def test_generate_video(
         base_endpoint, create_user, login, 
         create_project, get_project, create_pdfslideimages, 
@@ -0,0 +1,159 @@
+// Package trash handles the permanent removal of projects that have been left in the trash
+// beyond the retention period
+//
+// All blobs of the project are removed along with it - the source pdfs and scripts, slide
+// images, audio, music, the videos of the video segments in every language, the rendered
+// videos of the project and its video outputs, their versions and subtitles. Cloned projects
+// refer to the blobs of the project they were cloned from, so blobs that other projects still
+// refer to are kept. Imported projects are given copies of their own
+package trash
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/blobstorage"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/subtitles"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+)
+
+// maxPurgeBatch is the number of projects to be purged in each run
+const maxPurgeBatch = 20
+
+// maxChildRecords is the maximum number of video outputs and output versions looked at per project
+const maxChildRecords = 1000
+
+func NewPurger(logger logger.Logger, projectStore project.Store, videoOutputStore videooutput.Store, outputVersionStore outputversion.Store, storage blobstorage.BlobStorage, folders project.Folders, retention time.Duration) (Purger, error) {
+	if logger == nil || projectStore == nil || videoOutputStore == nil || outputVersionStore == nil || storage == nil {
+		return Purger{}, fmt.Errorf("cannot start purger as one of the inputs to purger is nil")
+	}
+	if retention <= 0 {
+		return Purger{}, fmt.Errorf("trash retention period needs to be positive")
+	}
+
+	return Purger{
+		logger:             logger,
+		projectStore:       projectStore,
+		videoOutputStore:   videoOutputStore,
+		outputVersionStore: outputVersionStore,
+		storage:            storage,
+		folders:            folders,
+		retention:          retention,
+	}, nil
+}
+
+type Purger struct {
+	logger             logger.Logger
+	projectStore       project.Store
+	videoOutputStore   videooutput.Store
+	outputVersionStore outputversion.Store
+	storage            blobstorage.BlobStorage
+	folders            project.Folders
+	retention          time.Duration
+}
+
+func (p Purger) Start() {
+	p.logger.Info("Start trash purger")
+	for {
+		purged, err := p.PurgeExpired(context.TODO())
+		if err != nil {
+			p.logger.Errorf("Unable to purge trashed projects :: Err %v", err)
+		}
+		if purged > 0 {
+			p.logger.Infof("Purged %v trashed projects", purged)
+		}
+		if purged == maxPurgeBatch {
+			// More projects might still be waiting to be purged
+			continue
+		}
+		time.Sleep(1 * time.Hour)
+	}
+}
+
+// PurgeExpired removes a batch of projects that have been in the trash beyond the retention period
+// Returns the number of projects that were removed
+func (p Purger) PurgeExpired(ctx context.Context) (int, error) {
+	projects, err := p.projectStore.GetAllTrashed(ctx, time.Now().Add(-p.retention), maxPurgeBatch)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, item := range projects {
+		err = p.purge(ctx, item)
+		if err != nil {
+			return purged, fmt.Errorf("unable to purge project %v. err: %v", item.ID, err)
+		}
+		purged = purged + 1
+	}
+	return purged, nil
+}
+
+func (p Purger) purge(ctx context.Context, item project.Project) error {
+	// The pdf slide images and video segments of the project are needed to find its blobs
+	item, err := p.projectStore.Get(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	blobs := []string{}
+	for _, b := range item.Blobs(p.folders) {
+		blobs = append(blobs, b.Path)
+	}
+	versions, err := p.outputVersionStore.GetAll(ctx, item.ID, "", maxChildRecords, 0)
+	if err != nil {
+		return err
+	}
+	videoOutputs, err := p.videoOutputStore.GetAll(ctx, item.ID, maxChildRecords, 0)
+	if err != nil {
+		return err
+	}
+	for _, o := range videoOutputs {
+		if o.OutputID != "" {
+			blobs = append(blobs, o.OutputID)
+			blobs = append(blobs, subtitleBlobs(o.OutputID)...)
+		}
+		if o.Music.File != "" {
+			blobs = append(blobs, o.Music.File)
+		}
+		outputVersions, err := p.outputVersionStore.GetAll(ctx, item.ID, o.ID, maxChildRecords, 0)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, outputVersions...)
+	}
+	for _, v := range versions {
+		blobs = append(blobs, v.BlobID)
+		blobs = append(blobs, subtitleBlobs(v.BlobID)...)
+	}
+
+	shared, err := project.SharedBlobs(ctx, p.projectStore, item, p.folders)
+	if err != nil {
+		return err
+	}
+
+	// Blobs are removed before the records so that a failed purge can be retried
+	for _, b := range blobs {
+		if shared[b] {
+			continue
+		}
+		err = p.storage.Delete(ctx, b)
+		if err != nil {
+			// Blob might have been removed in an earlier attempt or, for subtitles, never created
+			p.logger.Errorf("unable to delete blob of trashed project. ProjectID: %v :: Blob: %v :: Err: %v", item.ID, b, err)
+		}
+	}
+	return p.projectStore.Delete(ctx, item.ID)
+}
+
+// subtitleBlobs lists the subtitle files of the video. Subtitles are not created for videos
+// without scripts so these might not exist
+func subtitleBlobs(video string) []string {
+	blobs := []string{}
+	for format := range subtitles.Formats {
+		blobs = append(blobs, subtitles.FileName(video, format))
+	}
+	return blobs
+}
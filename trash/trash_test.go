@@ -0,0 +1,215 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/outputversion"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/pdfslideimages"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type fakeProjectStore struct {
+	project.Store
+	projects map[string]project.Project
+}
+
+func (f fakeProjectStore) Get(ctx context.Context, ID string) (project.Project, error) {
+	p, ok := f.projects[ID]
+	if !ok {
+		return project.Project{}, fmt.Errorf("project not found")
+	}
+	return p, nil
+}
+
+func (f fakeProjectStore) GetAllTrashed(ctx context.Context, DeletedBefore time.Time, Limit int) ([]project.Project, error) {
+	projects := []project.Project{}
+	for _, p := range f.projects {
+		if p.IsTrashed() && p.DeletedAt.Before(DeletedBefore) {
+			// Listings do not load the pdf slide images and video segments of the project
+			p.PDFSlideImages = nil
+			p.VideoSegments = nil
+			projects = append(projects, p)
+		}
+	}
+	return projects, nil
+}
+
+func (f fakeProjectStore) GetAllSharingBlobs(ctx context.Context, BlobSourceID string) ([]project.Project, error) {
+	projects := []project.Project{}
+	for _, p := range f.projects {
+		if p.BlobSourceID == BlobSourceID {
+			projects = append(projects, p)
+		}
+	}
+	return projects, nil
+}
+
+func (f fakeProjectStore) Delete(ctx context.Context, ID string) error {
+	delete(f.projects, ID)
+	return nil
+}
+
+type fakeVideoOutputStore struct {
+	videooutput.Store
+	videoOutputs []videooutput.VideoOutput
+}
+
+func (f fakeVideoOutputStore) GetAll(ctx context.Context, projectID string, Limit, After int) ([]videooutput.VideoOutput, error) {
+	videoOutputs := []videooutput.VideoOutput{}
+	for _, v := range f.videoOutputs {
+		if v.ProjectID == projectID {
+			videoOutputs = append(videoOutputs, v)
+		}
+	}
+	return videoOutputs, nil
+}
+
+type fakeOutputVersionStore struct {
+	outputversion.Store
+	versions []outputversion.OutputVersion
+}
+
+func (f fakeOutputVersionStore) GetAll(ctx context.Context, projectID, videoOutputID string, Limit, After int) ([]outputversion.OutputVersion, error) {
+	versions := []outputversion.OutputVersion{}
+	for _, v := range f.versions {
+		if v.ProjectID == projectID && v.VideoOutputID == videoOutputID {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+type memoryStorage map[string][]byte
+
+func (m memoryStorage) Save(ctx context.Context, fileName string, content []byte) error {
+	m[fileName] = content
+	return nil
+}
+
+func (m memoryStorage) Load(ctx context.Context, fileName string) ([]byte, error) {
+	content, ok := m[fileName]
+	if !ok {
+		return []byte{}, fmt.Errorf("%v not found", fileName)
+	}
+	return content, nil
+}
+
+func (m memoryStorage) Delete(ctx context.Context, fileName string) error {
+	if _, ok := m[fileName]; !ok {
+		return fmt.Errorf("%v not found", fileName)
+	}
+	delete(m, fileName)
+	return nil
+}
+
+func TestPurger_PurgeExpired(t *testing.T) {
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	trashed := project.New()
+	trashed.DeletedAt = &deletedAt
+	trashed.VideoOutputID = "trashed.mp4"
+	trashed.Music.File = "trashed-music.mp3"
+	trashed.PDFSlideImages = []pdfslideimages.PDFSlideImages{{
+		ID:          "pdf1",
+		PDFFile:     "pdf1.pdf",
+		ScriptFile:  "pdf1-script.txt",
+		SlideAssets: []pdfslideimages.SlideAsset{{ImageID: "pdf1-0.png"}},
+	}}
+	segment := videosegment.New(trashed.ID, "pdf1-0.png", 0)
+	segment.VideoFile = "segment.mp4"
+	segment.Translations = []videosegment.Translation{{Language: "ms", VideoFile: "segment-ms.mp4"}}
+	trashed.VideoSegments = []videosegment.VideoSegment{segment}
+
+	// Projects still within the retention period are kept along with their blobs
+	recentlyDeletedAt := time.Now()
+	recent := project.New()
+	recent.DeletedAt = &recentlyDeletedAt
+	recent.VideoOutputID = "recent.mp4"
+
+	projectStore := fakeProjectStore{projects: map[string]project.Project{trashed.ID: trashed, recent.ID: recent}}
+	videoOutputStore := fakeVideoOutputStore{videoOutputs: []videooutput.VideoOutput{
+		{ID: "output1", ProjectID: trashed.ID, OutputID: "output1-1.mp4", Music: trashed.Music},
+	}}
+	outputVersionStore := fakeOutputVersionStore{versions: []outputversion.OutputVersion{
+		{ID: "version1", ProjectID: trashed.ID, BlobID: "trashed-1.mp4"},
+		{ID: "version2", ProjectID: trashed.ID, VideoOutputID: "output1", BlobID: "output1-1.mp4"},
+	}}
+	storage := memoryStorage{
+		"pdf/pdf1.pdf":        []byte("pdf"),
+		"pdf/pdf1-script.txt": []byte("script"),
+		"images/pdf1-0.png":   []byte("png"),
+		"segment.mp4":         []byte("segment"),
+		"segment-ms.mp4":      []byte("segment in malay"),
+		"trashed.mp4":         []byte("output"),
+		"trashed.vtt":         []byte("subtitles"),
+		"trashed-music.mp3":   []byte("music"),
+		"trashed-1.mp4":       []byte("version"),
+		"trashed-1.srt":       []byte("subtitles"),
+		"output1-1.mp4":       []byte("video output"),
+		"output1-1.vtt":       []byte("subtitles"),
+		"recent.mp4":          []byte("recent output"),
+		"images/other-0.png":  []byte("other project"),
+	}
+
+	purger, err := NewPurger(logger.LoggerForTests{Tester: t}, projectStore, videoOutputStore, outputVersionStore, storage, project.Folders{PDF: "pdf", Images: "images"}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error creating purger. Err: %v", err)
+	}
+	purged, err := purger.PurgeExpired(context.TODO())
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected a single project to be purged. Purged: %v Err: %v", purged, err)
+	}
+
+	if _, ok := projectStore.projects[trashed.ID]; ok {
+		t.Errorf("Expected trashed project to be removed")
+	}
+	if _, ok := projectStore.projects[recent.ID]; !ok {
+		t.Errorf("Expected recently trashed project to be kept")
+	}
+	if len(storage) != 2 || storage["recent.mp4"] == nil || storage["images/other-0.png"] == nil {
+		t.Errorf("Expected only blobs of other projects to be kept. Storage: %v", storage)
+	}
+}
+
+func TestPurger_KeepsSharedBlobs(t *testing.T) {
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	source := project.New()
+	source.Music.File = "music.mp3"
+	source.PDFSlideImages = []pdfslideimages.PDFSlideImages{{
+		ID:          "pdf1",
+		PDFFile:     "pdf1.pdf",
+		SlideAssets: []pdfslideimages.SlideAsset{{ImageID: "pdf1-0.png"}},
+	}}
+	segment := videosegment.New(source.ID, "pdf1-0.png", 0)
+	segment.VideoFile = "segment.mp4"
+	source.VideoSegments = []videosegment.VideoSegment{segment}
+	clone := source.Reference("clone")
+	source.BlobSourceID = source.ID
+	source.DeletedAt = &deletedAt
+
+	projectStore := fakeProjectStore{projects: map[string]project.Project{source.ID: source, clone.ID: clone}}
+	storage := memoryStorage{
+		"pdf/pdf1.pdf":      []byte("pdf"),
+		"images/pdf1-0.png": []byte("png"),
+		"music.mp3":         []byte("music"),
+		"segment.mp4":       []byte("segment"),
+	}
+
+	purger, err := NewPurger(logger.LoggerForTests{Tester: t}, projectStore, fakeVideoOutputStore{}, fakeOutputVersionStore{}, storage, project.Folders{PDF: "pdf", Images: "images"}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error creating purger. Err: %v", err)
+	}
+	purged, err := purger.PurgeExpired(context.TODO())
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected a single project to be purged. Purged: %v Err: %v", purged, err)
+	}
+
+	if len(storage) != 3 || storage["segment.mp4"] != nil {
+		t.Errorf("Expected blobs that the clone refers to to be kept. Storage: %v", storage)
+	}
+}
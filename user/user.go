@@ -16,6 +16,7 @@ type Type string
 var PasswordAuth Type = "password"
 var EmailAuth Type = "passwordless-email"
 var GoogleAuth Type = "google"
+var OIDCAuth Type = "oidc"
 
 var ErrEmailInvalid = errors.New("Email is invalid")
 var ErrPasswordShort = errors.New("Password cannot be shorter than 8 characters")
@@ -59,6 +60,25 @@ func New(email, password string) (User, error) {
 	return user, nil
 }
 
+// NewFromProvider creates a user that signs in with an OpenID Connect provider. The user has no
+// password and is activated right away as the provider has already verified the email
+func NewFromProvider(email string) (User, error) {
+	if email == "" {
+		return User{}, ErrEmailInvalid
+	}
+	currentTime := time.Now()
+	return User{
+		ID:                       uuid.New().String(),
+		Email:                    email,
+		Activated:                true,
+		Type:                     string(OIDCAuth),
+		DateCreated:              currentTime,
+		DateModified:             currentTime,
+		ForgetPasswordExpiryDate: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		ActivationExpiryDate:     time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+
 func (u User) validateEmail() error {
 	reEmail := regexp.MustCompile(`\w+@\w{2,3}.\w{2,3}`)
 	isValid := reEmail.MatchString(u.Email)
@@ -5,22 +5,27 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/gofrs/uuid"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/project"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/queue"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
 )
 
 type basic struct {
-	queue        queue.Queue
-	projectStore project.Store
-	authStore    services.Auth
+	queue            queue.Queue
+	projectStore     project.Store
+	videoOutputStore videooutput.Store
+	authStore        services.Auth
 }
 
-func NewBasic(q queue.Queue, s project.Store, a services.Auth) basic {
+func NewBasic(q queue.Queue, s project.Store, o videooutput.Store, a services.Auth) basic {
 	return basic{
-		queue:        q,
-		projectStore: s,
-		authStore:    a,
+		queue:            q,
+		projectStore:     s,
+		videoOutputStore: o,
+		authStore:        a,
 	}
 }
 
@@ -29,21 +34,29 @@ func (b basic) Start(ctx context.Context, projectID, userID string, videoSegment
 		return fmt.Errorf("No video segments to combine to single output video")
 	}
 
+	singleProject, err := b.projectStore.Get(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
 	updaters, _ := project.RegenerateIdemKeys()
-	newProject, err := b.projectStore.Update(ctx, projectID, userID, updaters...)
+	newProject, err := b.projectStore.Update(ctx, projectID, updaters...)
 	if err != nil {
 		return err
 	}
 
-	token, err := services.NewToken(userID, b.authStore.ExpiryTime, b.authStore.Secret, b.authStore.Issuer)
+	token, err := services.NewServiceToken(services.ConcatenateVideo, services.UpdateProjectStatus, projectID, "", userID, b.authStore.ExpiryTime, b.authStore.Keys(), b.authStore.Issuer)
 	if err != nil {
 		return err
 	}
 
 	values := map[string]interface{}{
 		"id":                    projectID,
+		"output_file":           outputFileName(),
 		"auth_token":            "Bearer " + token,
 		"video_segments":        videoSegmentList,
+		"music":                 musicJob(newProject.Music),
+		"scripts":               subtitleScripts(singleProject, videoSegmentList),
 		"idem_key_running":      newProject.SetRunningIdemKey,
 		"idem_key_complete_rec": newProject.CompleteRecIdemKey,
 	}
@@ -56,3 +69,85 @@ func (b basic) Start(ctx context.Context, projectID, userID string, videoSegment
 
 	return nil
 }
+
+func (b basic) StartOutput(ctx context.Context, output videooutput.VideoOutput, userID string, videoSegmentList []string) error {
+	if len(videoSegmentList) == 0 {
+		return fmt.Errorf("No video segments to combine to video output")
+	}
+
+	singleProject, err := b.projectStore.Get(ctx, output.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	updaters, _ := videooutput.StartGeneration()
+	newOutput, err := b.videoOutputStore.Update(ctx, output.ProjectID, output.ID, updaters...)
+	if err != nil {
+		return err
+	}
+
+	token, err := services.NewServiceToken(services.ConcatenateVideo, services.UpdateVideoOutputStatus, newOutput.ProjectID, newOutput.ID, userID, b.authStore.ExpiryTime, b.authStore.Keys(), b.authStore.Issuer)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"id":                    newOutput.ProjectID,
+		"output_id":             newOutput.ID,
+		"output_file":           outputFileName(),
+		"auth_token":            "Bearer " + token,
+		"video_segments":        videoSegmentList,
+		"scale":                 newOutput.Scale(),
+		"video_bitrate":         newOutput.VideoBitrate(),
+		"music":                 musicJob(settings.ResolveMusic(singleProject.Music, newOutput.Music)),
+		"scripts":               subtitleScripts(singleProject, videoSegmentList),
+		"burn_subtitles":        newOutput.BurnSubtitles,
+		"idem_key_running":      newOutput.SetRunningIdemKey,
+		"idem_key_complete_rec": newOutput.CompleteRecIdemKey,
+	}
+	jsonValue, _ := json.Marshal(values)
+
+	err = b.queue.Add(ctx, jsonValue)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// musicJob provides the background music to be mixed by the worker. Nothing is sent if
+// there is no background music so that the worker keeps the narration as is
+func musicJob(m settings.Music) interface{} {
+	if m.File == "" {
+		return nil
+	}
+	return m.WithDefaults()
+}
+
+// subtitleScripts looks up the script narrated in each of the video files to be concatenated
+// The worker uses them to create the subtitles of the video
+func subtitleScripts(p project.Project, videoFiles []string) []string {
+	narrated := map[string]string{}
+	for _, v := range p.VideoSegments {
+		if v.VideoFile != "" {
+			narrated[v.VideoFile] = v.Script
+		}
+		for _, t := range v.Translations {
+			if t.VideoFile != "" {
+				narrated[t.VideoFile] = t.Script
+			}
+		}
+	}
+	scripts := []string{}
+	for _, f := range videoFiles {
+		scripts = append(scripts, narrated[f])
+	}
+	return scripts
+}
+
+// outputFileName provides a new file name for every concatenation run so that
+// earlier versions of the video are kept
+func outputFileName() string {
+	runID, _ := uuid.NewV4()
+	return runID.String() + ".mp4"
+}
@@ -1,7 +1,16 @@
 package videoconcater
 
-import "context"
+import (
+	"context"
 
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videooutput"
+)
+
+// VideoConcater combines the video segments of a project into a single video
+// The worker reports the progress of the video with a service account token that only allows
+// the status of the project or video output to be updated
 type VideoConcater interface {
 	Start(ctx context.Context, projectID, userID string, videoSegmentList []string) error
+	// StartOutput concatenates the video segments for a video output of the project
+	StartOutput(ctx context.Context, output videooutput.VideoOutput, userID string, videoSegmentList []string) error
 }
@@ -6,35 +6,80 @@ import (
 	"fmt"
 
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/queue"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/services"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 )
 
 type basic struct {
 	queue             queue.Queue
 	videosegmentStore videosegment.Store
+	authStore         services.Auth
 }
 
-func NewBasic(q queue.Queue, store videosegment.Store) basic {
+func NewBasic(q queue.Queue, store videosegment.Store, a services.Auth) basic {
 	return basic{
 		queue:             q,
 		videosegmentStore: store,
+		authStore:         a,
 	}
 }
 
-func (b basic) Start(ctx context.Context, v videosegment.VideoSegment) error {
-	updaters, _ := videosegment.RegenerateIdemKeys()
+func (b basic) Start(ctx context.Context, v videosegment.VideoSegment, userID string, projectSettings settings.Settings) error {
+	updaters, _ := videosegment.RegenerateIdemKeys(projectSettings)
 	newV, err := b.videosegmentStore.Update(ctx, v.ProjectID, v.ID, updaters...)
 	if err != nil {
 		return fmt.Errorf("unable to generate idem keys for video segment creation. %v %v", v.ProjectID, v.ID)
 	}
 
-	values := map[string]string{
+	token, err := services.NewServiceToken(services.ImageToVideo, services.UpdateVideoSegmentStatus, newV.ProjectID, newV.ID, userID, b.authStore.ExpiryTime, b.authStore.Keys(), b.authStore.Issuer)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
 		"id":                    newV.ID,
 		"project_id":            newV.ProjectID,
 		"script":                newV.Script,
 		"image_id":              newV.ImageID,
+		"auth_token":            "Bearer " + token,
 		"idem_key_running":      newV.SetRunningIdemKey,
 		"idem_key_complete_rec": newV.CompleteRecIdemKey,
+		"settings":              newV.Settings(projectSettings),
+	}
+	jsonValue, _ := json.Marshal(values)
+
+	err = b.queue.Add(context.Background(), jsonValue)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b basic) StartTranslation(ctx context.Context, v videosegment.VideoSegment, userID, language string, languageSettings settings.Settings) error {
+	updaters, _ := videosegment.RegenerateTranslationIdemKeys(language, languageSettings)
+	newV, err := b.videosegmentStore.Update(ctx, v.ProjectID, v.ID, updaters...)
+	if err != nil {
+		return fmt.Errorf("unable to generate idem keys for video segment creation. %v %v %v. Err: %v", v.ProjectID, v.ID, language, err)
+	}
+	t, _ := newV.Translation(language)
+
+	token, err := services.NewServiceToken(services.ImageToVideo, services.UpdateVideoSegmentStatus, newV.ProjectID, newV.ID, userID, b.authStore.ExpiryTime, b.authStore.Keys(), b.authStore.Issuer)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"id":                    newV.ID,
+		"project_id":            newV.ProjectID,
+		"language":              t.Language,
+		"script":                t.Script,
+		"image_id":              newV.ImageID,
+		"auth_token":            "Bearer " + token,
+		"idem_key_running":      t.SetRunningIdemKey,
+		"idem_key_complete_rec": t.CompleteRecIdemKey,
+		"settings":              settings.Effective(languageSettings, settings.Voice{}),
 	}
 	jsonValue, _ := json.Marshal(values)
 
@@ -3,11 +3,18 @@ package videogenerator
 import (
 	"context"
 
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
 )
 
 // VideoGenerator is expected to take a while to complete
 // It is assumed to be async in nature
+// The video segment is generated with the project settings merged with its voice override
+// The worker reports the progress of the video segment with a service account token that only
+// allows the status of the video segment to be updated
 type VideoGenerator interface {
-	Start(context.Context, videosegment.VideoSegment) error
+	Start(ctx context.Context, v videosegment.VideoSegment, userID string, projectSettings settings.Settings) error
+	// StartTranslation generates the video segment in one of the additional languages of the project
+	// The settings are the project settings for the language
+	StartTranslation(ctx context.Context, v videosegment.VideoSegment, userID, language string, languageSettings settings.Settings) error
 }
@@ -0,0 +1,109 @@
+package videooutput
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+)
+
+type googleDatastore struct {
+	logger            logger.Logger
+	projectEntityName string
+	entityName        string
+	client            *datastore.Client
+}
+
+func NewGoogleDatastore(logger logger.Logger, ds *datastore.Client, projectEntity, en string) *googleDatastore {
+	return &googleDatastore{
+		logger:            logger,
+		client:            ds,
+		entityName:        en,
+		projectEntityName: projectEntity,
+	}
+}
+
+func (g *googleDatastore) Create(ctx context.Context, e VideoOutput) error {
+	projectKey := datastore.NameKey(g.projectEntityName, e.ProjectID, nil)
+	newKey := datastore.NameKey(g.entityName, e.ID, projectKey)
+	_, err := g.client.Put(ctx, newKey, &e)
+	if err != nil {
+		return fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	return nil
+}
+
+func (g *googleDatastore) Get(ctx context.Context, projectID, ID string) (VideoOutput, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	key := datastore.NameKey(g.entityName, ID, projectKey)
+	v := VideoOutput{}
+	if err := g.client.Get(ctx, key, &v); err != nil {
+		return VideoOutput{}, fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+	}
+	v.ID = ID
+	v.ProjectID = projectID
+	setOutputSegmentIDs(&v)
+	return v, nil
+}
+
+func (g *googleDatastore) GetAll(ctx context.Context, projectID string, limit, after int) ([]VideoOutput, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	query := datastore.NewQuery(g.entityName).Ancestor(projectKey).Order("DateCreated").Limit(limit).Offset(after)
+	videoOutputs := []VideoOutput{}
+	keys, err := g.client.GetAll(ctx, query, &videoOutputs)
+	if err != nil {
+		return []VideoOutput{}, fmt.Errorf("unable to retrieve all results. err: %v", err)
+	}
+	for i, key := range keys {
+		videoOutputs[i].ID = key.Name
+		videoOutputs[i].ProjectID = projectID
+		setOutputSegmentIDs(&videoOutputs[i])
+	}
+	return videoOutputs, nil
+}
+
+func (g *googleDatastore) Update(ctx context.Context, projectID, ID string, setters ...func(*VideoOutput) error) (VideoOutput, error) {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	key := datastore.NameKey(g.entityName, ID, projectKey)
+	v := VideoOutput{}
+	_, err := g.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(key, &v); err != nil {
+			return fmt.Errorf("unable to retrieve value from datastore. err: %v", err)
+		}
+		v.ID = ID
+		v.ProjectID = projectID
+		for _, setFunc := range setters {
+			err := setFunc(&v)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := tx.Put(key, &v)
+		if err != nil {
+			return fmt.Errorf("unable to send record to datastore: err: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return VideoOutput{}, fmt.Errorf("unable to send record to datastore: err: %v", err)
+	}
+	setOutputSegmentIDs(&v)
+	return v, nil
+}
+
+func (g *googleDatastore) Delete(ctx context.Context, projectID, ID string) error {
+	projectKey := datastore.NameKey(g.projectEntityName, projectID, nil)
+	key := datastore.NameKey(g.entityName, ID, projectKey)
+	err := g.client.Delete(ctx, key)
+	if err != nil {
+		return fmt.Errorf("unable to delete video output. err: %v", err)
+	}
+	return nil
+}
+
+func setOutputSegmentIDs(v *VideoOutput) {
+	for i := range v.Segments {
+		v.Segments[i].VideoOutputID = v.ID
+	}
+}
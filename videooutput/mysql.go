@@ -0,0 +1,96 @@
+package videooutput
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/logger"
+	"github.com/jinzhu/gorm"
+)
+
+type mysql struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
+	return mysql{
+		db:     dbClient,
+		logger: logger,
+	}
+}
+
+func (m mysql) Create(ctx context.Context, e VideoOutput) error {
+	result := m.db.Create(&e)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, s := range e.Segments {
+		result = m.db.Save(&s)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func (m mysql) Get(ctx context.Context, projectID, ID string) (VideoOutput, error) {
+	v := VideoOutput{}
+	result := m.db.Where("id = ? AND project_id = ?", ID, projectID).First(&v)
+	if result.Error != nil {
+		return v, result.Error
+	}
+	s := []OutputSegment{}
+	result = m.db.Where("video_output_id = ?", ID).Find(&s)
+	if result.Error != nil {
+		return VideoOutput{}, result.Error
+	}
+	v.Segments = s
+	return v, nil
+}
+
+func (m mysql) GetAll(ctx context.Context, projectID string, Limit, After int) ([]VideoOutput, error) {
+	var videoOutputs []VideoOutput
+	result := m.db.Where("project_id = ?", projectID).Order("date_created").Limit(Limit).Offset(After).Find(&videoOutputs)
+	if result.Error != nil {
+		return []VideoOutput{}, result.Error
+	}
+	for i := range videoOutputs {
+		s := []OutputSegment{}
+		result = m.db.Where("video_output_id = ?", videoOutputs[i].ID).Find(&s)
+		if result.Error != nil {
+			return []VideoOutput{}, result.Error
+		}
+		videoOutputs[i].Segments = s
+	}
+	return videoOutputs, nil
+}
+
+func (m mysql) Update(ctx context.Context, projectID, ID string, setters ...func(*VideoOutput) error) (VideoOutput, error) {
+	v, err := m.Get(ctx, projectID, ID)
+	if err != nil {
+		return VideoOutput{}, err
+	}
+	for _, s := range setters {
+		err := s(&v)
+		if err != nil {
+			return VideoOutput{}, err
+		}
+	}
+	result := m.db.Save(&v)
+	if result.Error != nil {
+		return VideoOutput{}, result.Error
+	}
+	return v, nil
+}
+
+func (m mysql) Delete(ctx context.Context, projectID, ID string) error {
+	result := m.db.Where("video_output_id = ?", ID).Delete(OutputSegment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	result = m.db.Where("id = ? and project_id = ?", ID, projectID).Delete(VideoOutput{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
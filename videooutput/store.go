@@ -0,0 +1,152 @@
+package videooutput
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
+
+type Store interface {
+	Create(ctx context.Context, e VideoOutput) error
+	Get(ctx context.Context, projectID, ID string) (VideoOutput, error)
+	GetAll(ctx context.Context, projectID string, Limit, After int) ([]VideoOutput, error)
+	Update(ctx context.Context, projectID, ID string, setters ...func(*VideoOutput) error) (VideoOutput, error)
+	Delete(ctx context.Context, projectID, ID string) error
+}
+
+// maxLanguageLookup is the number of video outputs looked at to find the video output of a language
+const maxLanguageLookup = 100
+
+// GetLanguageOutput retrieves the video output of the project that holds the video of all
+// visible video segments in the additional language. It is created on the first concatenation
+func GetLanguageOutput(ctx context.Context, store Store, projectID, language string) (VideoOutput, error) {
+	videoOutputs, err := store.GetAll(ctx, projectID, maxLanguageLookup, 0)
+	if err != nil {
+		return VideoOutput{}, err
+	}
+	for _, v := range videoOutputs {
+		if v.Language == language && len(v.Segments) == 0 && v.Resolution == "" && v.Profile == "" {
+			return v, nil
+		}
+	}
+	item, err := New(projectID, language, nil, "", "")
+	if err != nil {
+		return VideoOutput{}, err
+	}
+	item.Language = language
+	err = store.Create(ctx, item)
+	if err != nil {
+		return VideoOutput{}, err
+	}
+	return item, nil
+}
+
+func GetUpdaters(runningIdemKey, completeRecIdemKey, state, outputID string) ([]func(*VideoOutput) error, error) {
+	var s status
+	switch state {
+	case "running":
+		s = running
+	case "completed":
+		s = completed
+	case "error":
+		s = errorStatus
+	default:
+		return []func(*VideoOutput) error{}, fmt.Errorf("Bad status is passed into it")
+	}
+	var setters []func(*VideoOutput) error
+	if s == running && runningIdemKey == "" {
+		return setters, fmt.Errorf("No IdemKey passed to change the status to running state")
+	}
+	if s == errorStatus && completeRecIdemKey == "" || s == completed && completeRecIdemKey == "" {
+		return setters, fmt.Errorf("No CompleteRec IdemKey passed to change status to error/completed")
+	}
+	if s == completed && !strings.Contains(outputID, ".mp4") {
+		return setters, fmt.Errorf("Missing/invalid output id")
+	}
+	if s == running {
+		setters = append(setters, setStatus(s), clearSetRunningIdemKey(runningIdemKey))
+		return setters, nil
+	}
+	if s == errorStatus {
+		setters = append(setters, setStatus(s), clearCompleteRecIdemKey(completeRecIdemKey))
+		return setters, nil
+	}
+	setters = append(setters, setStatus(s), clearCompleteRecIdemKey(completeRecIdemKey), setOutputID(outputID))
+	return setters, nil
+}
+
+// StartGeneration marks the video output as pending for generation and
+// creates new idem keys for the worker to update the video output
+func StartGeneration() ([]func(*VideoOutput) error, error) {
+	var setters []func(*VideoOutput) error
+	setters = append(setters, setStatus(created), recreateIdemKeys())
+	return setters, nil
+}
+
+// SetMusic replaces the background music of the video output. Music without a file
+// removes the music so that the background music of the project is used instead
+func SetMusic(m settings.Music) ([]func(*VideoOutput) error, error) {
+	err := m.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var setters []func(*VideoOutput) error
+	setters = append(setters, func(a *VideoOutput) error {
+		if m.File == "" {
+			m = settings.Music{}
+		}
+		a.Music = m
+		a.DateModified = time.Now()
+		return nil
+	})
+	return setters, nil
+}
+
+func setStatus(s status) func(*VideoOutput) error {
+	return func(a *VideoOutput) error {
+		a.Status = s
+		a.DateModified = time.Now()
+		return nil
+	}
+}
+
+func setOutputID(outputID string) func(*VideoOutput) error {
+	return func(a *VideoOutput) error {
+		a.OutputID = outputID
+		return nil
+	}
+}
+
+func recreateIdemKeys() func(*VideoOutput) error {
+	return func(a *VideoOutput) error {
+		idemKey1, _ := uuid.NewV4()
+		idemKey2, _ := uuid.NewV4()
+		a.SetRunningIdemKey = idemKey1.String()
+		a.CompleteRecIdemKey = idemKey2.String()
+		return nil
+	}
+}
+
+func clearSetRunningIdemKey(idemKey string) func(*VideoOutput) error {
+	return func(a *VideoOutput) error {
+		if a.SetRunningIdemKey == idemKey {
+			a.SetRunningIdemKey = ""
+			return nil
+		}
+		return fmt.Errorf("Idemkey set is not the same. Cannot clear idemkey values")
+	}
+}
+
+func clearCompleteRecIdemKey(idemKey string) func(*VideoOutput) error {
+	return func(a *VideoOutput) error {
+		if a.CompleteRecIdemKey == idemKey {
+			a.CompleteRecIdemKey = ""
+			return nil
+		}
+		return fmt.Errorf("Idemkey set is not the same. Cannot clear idemkey values")
+	}
+}
@@ -0,0 +1,153 @@
+// Package videooutput handles the renditions that a project can produce.
+// Each video output is a concatenation of a selection of the video segments of a project
+// at a given resolution and bitrate profile
+package videooutput
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/videosegment"
+)
+
+type status string
+
+var (
+	created     status = "created"
+	running     status = "running"
+	errorStatus status = "error"
+	completed   status = "completed"
+)
+
+// Resolutions lists the supported resolutions of a video output and the ffmpeg scale it maps to
+// An empty resolution keeps the resolution of the video segments
+var Resolutions = map[string]string{
+	"":      "",
+	"1080p": "1920:1080",
+	"720p":  "1280:720",
+	"480p":  "854:480",
+}
+
+// Profiles lists the supported bitrate profiles of a video output and the video bitrate it maps to
+// An empty profile keeps the video segments as is without re-encoding them
+var Profiles = map[string]string{
+	"":       "",
+	"high":   "5000k",
+	"medium": "2500k",
+	"low":    "1000k",
+}
+
+type OutputSegment struct {
+	VideoSegmentID string `json:"video_segment_id" gorm:"type:varchar(40);primary_key"`
+	VideoOutputID  string `json:"-" datastore:"-" gorm:"type:varchar(40);primary_key"`
+}
+
+type VideoOutput struct {
+	ID         string          `json:"id" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	ProjectID  string          `json:"project_id" datastore:"-" gorm:"type:varchar(40)"`
+	Name       string          `json:"name" gorm:"type:varchar(250)"`
+	Segments   []OutputSegment `json:"segments"`
+	Resolution string          `json:"resolution" gorm:"type:varchar(20)"`
+	Profile    string          `json:"profile" gorm:"type:varchar(20)"`
+	// Language is the additional language of the project the video output is narrated in
+	// An empty language uses the primary language of the project
+	Language string `json:"language,omitempty" gorm:"type:varchar(20)"`
+	// BurnSubtitles renders the subtitles into the video. Subtitle files are generated
+	// for every video output regardless
+	BurnSubtitles bool `json:"burn_subtitles"`
+	// Music replaces the background music of the project for the video output
+	Music              settings.Music `json:"music" gorm:"embedded;embedded_prefix:music_"`
+	Status             status         `json:"status" gorm:"type:varchar(20)"`
+	OutputID           string         `json:"output_id,omitempty" gorm:"type:varchar(100)"`
+	DateCreated        time.Time      `json:"date_created"`
+	DateModified       time.Time      `json:"date_modified"`
+	SetRunningIdemKey  string         `json:"-" gorm:"type:varchar(40)"`
+	CompleteRecIdemKey string         `json:"-" gorm:"type:varchar(40)"`
+}
+
+// New creates a video output. An empty list of video segment ids would mean that
+// all visible video segments of the project are to be used
+func New(projectID, name string, videoSegmentIDs []string, resolution, profile string) (VideoOutput, error) {
+	if name == "" {
+		return VideoOutput{}, fmt.Errorf("name of video output cannot be empty")
+	}
+	if _, ok := Resolutions[resolution]; !ok {
+		return VideoOutput{}, fmt.Errorf("unsupported resolution %v", resolution)
+	}
+	if _, ok := Profiles[profile]; !ok {
+		return VideoOutput{}, fmt.Errorf("unsupported profile %v", profile)
+	}
+	id, _ := uuid.NewV4()
+	segments := []OutputSegment{}
+	seen := map[string]bool{}
+	for _, v := range videoSegmentIDs {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		segments = append(segments, OutputSegment{
+			VideoSegmentID: v,
+			VideoOutputID:  id.String(),
+		})
+	}
+	return VideoOutput{
+		ID:           id.String(),
+		ProjectID:    projectID,
+		Name:         name,
+		Segments:     segments,
+		Resolution:   resolution,
+		Profile:      profile,
+		Status:       created,
+		DateCreated:  time.Now(),
+		DateModified: time.Now(),
+	}, nil
+}
+
+// VideoFiles lists the video files of the video segments in the language of the video output
+// The video segments are expected to be in the order that they are to be concatenated in
+func (v *VideoOutput) VideoFiles(videoSegments []videosegment.VideoSegment) ([]string, error) {
+	videoSegmentList := []string{}
+	for _, s := range videoSegments {
+		if !v.Includes(s.ID) {
+			continue
+		}
+		if v.Language == "" {
+			if !s.IsReady() || s.VideoFile == "" {
+				return []string{}, fmt.Errorf("video segment %v has not been generated", s.ID)
+			}
+			videoSegmentList = append(videoSegmentList, s.VideoFile)
+			continue
+		}
+		t, ok := s.Translation(v.Language)
+		if !ok || !t.IsReady() || t.VideoFile == "" {
+			return []string{}, fmt.Errorf("video segment %v has not been generated in language %v", s.ID, v.Language)
+		}
+		videoSegmentList = append(videoSegmentList, t.VideoFile)
+	}
+	return videoSegmentList, nil
+}
+
+// Includes checks if the video segment is part of the segment selection of the video output
+func (v *VideoOutput) Includes(videoSegmentID string) bool {
+	if len(v.Segments) == 0 {
+		return true
+	}
+	for _, s := range v.Segments {
+		if s.VideoSegmentID == videoSegmentID {
+			return true
+		}
+	}
+	return false
+}
+
+// Scale is the ffmpeg scale to be applied when generating the video output
+func (v *VideoOutput) Scale() string {
+	return Resolutions[v.Resolution]
+}
+
+// VideoBitrate is the video bitrate to be used when generating the video output
+func (v *VideoOutput) VideoBitrate() string {
+	return Profiles[v.Profile]
+}
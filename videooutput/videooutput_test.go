@@ -0,0 +1,45 @@
+package videooutput
+
+import "testing"
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		outputName string
+		resolution string
+		profile    string
+		wantErr    bool
+	}{
+		{name: "full output", outputName: "full"},
+		{name: "mobile output", outputName: "mobile", resolution: "720p", profile: "low"},
+		{name: "missing name", outputName: "", wantErr: true},
+		{name: "bad resolution", outputName: "full", resolution: "4k", wantErr: true},
+		{name: "bad profile", outputName: "full", profile: "ultra", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New("project", tt.outputName, []string{}, tt.resolution, tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVideoOutput_Includes(t *testing.T) {
+	all, _ := New("project", "full", []string{}, "", "")
+	if !all.Includes("segment-1") {
+		t.Errorf("video output without selection is expected to include all video segments")
+	}
+
+	summary, _ := New("project", "executive summary", []string{"segment-1", "segment-1", "segment-3"}, "720p", "medium")
+	if len(summary.Segments) != 2 {
+		t.Errorf("duplicated video segments are expected to be removed. Segments: %+v", summary.Segments)
+	}
+	if !summary.Includes("segment-3") || summary.Includes("segment-2") {
+		t.Errorf("unexpected video segment selection. Segments: %+v", summary.Segments)
+	}
+	if summary.Scale() != "1280:720" || summary.VideoBitrate() != "2500k" {
+		t.Errorf("unexpected scale/bitrate. Scale: %v Bitrate: %v", summary.Scale(), summary.VideoBitrate())
+	}
+}
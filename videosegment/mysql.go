@@ -20,10 +20,50 @@ func NewMySQL(logger logger.Logger, dbClient *gorm.DB) mysql {
 }
 
 func (m mysql) Create(ctx context.Context, e VideoSegment) error {
-	result := m.db.Create(&e)
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Create(&e)
+		if result.Error != nil {
+			return result.Error
+		}
+		return saveTranslations(tx, nil, e)
+	})
+}
+
+// saveTranslations writes the translations of the video segment that differ from the ones that
+// were loaded with it. Translations that are no longer on the video segment are removed
+func saveTranslations(db *gorm.DB, previous []Translation, v VideoSegment) error {
+	previousByLanguage := map[string]Translation{}
+	for _, t := range previous {
+		previousByLanguage[t.Language] = t
+	}
+	for _, t := range v.Translations {
+		p, ok := previousByLanguage[t.Language]
+		delete(previousByLanguage, t.Language)
+		t.VideoSegmentID = v.ID
+		if ok && p == t {
+			continue
+		}
+		result := db.Save(&t)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	for language := range previousByLanguage {
+		result := db.Where("video_segment_id = ? AND language = ?", v.ID, language).Delete(Translation{})
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func loadTranslations(db *gorm.DB, v *VideoSegment) error {
+	var translations []Translation
+	result := db.Where("video_segment_id = ?", v.ID).Order("language").Find(&translations)
 	if result.Error != nil {
 		return result.Error
 	}
+	v.Translations = translations
 	return nil
 }
 
@@ -33,6 +73,10 @@ func (m mysql) Get(ctx context.Context, projectID, ID string) (VideoSegment, err
 	if result.Error != nil {
 		return p, result.Error
 	}
+	err := loadTranslations(m.db, &p)
+	if err != nil {
+		return VideoSegment{}, err
+	}
 	return p, nil
 }
 
@@ -42,30 +86,53 @@ func (m mysql) GetAll(ctx context.Context, ProjectID string, Limit, After int) (
 	if result.Error != nil {
 		return []VideoSegment{}, result.Error
 	}
+	for i := range videosegments {
+		err := loadTranslations(m.db, &videosegments[i])
+		if err != nil {
+			return []VideoSegment{}, err
+		}
+	}
 	return videosegments, nil
 }
 
 func (m mysql) Update(ctx context.Context, projectID, ID string, setters ...func(*VideoSegment) error) (VideoSegment, error) {
-	var p VideoSegment
-	result := m.db.Where("project_id = ? AND id = ?", projectID, ID).First(&p)
-	if result.Error != nil {
-		return VideoSegment{}, result.Error
-	}
-	for _, s := range setters {
-		err := s(&p)
+	p := VideoSegment{}
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		// The row is locked so that concurrent updates of the video segment and its translations,
+		// such as the status reports of the workers, do not overwrite one another
+		result := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ? AND project_id = ?", ID, projectID).First(&p)
+		if result.Error != nil {
+			return result.Error
+		}
+		err := loadTranslations(tx, &p)
 		if err != nil {
-			return VideoSegment{}, err
+			return err
 		}
-	}
-	result = m.db.Save(&p)
-	if result.Error != nil {
-		return VideoSegment{}, result.Error
+		previous := append([]Translation{}, p.Translations...)
+		for _, s := range setters {
+			err := s(&p)
+			if err != nil {
+				return err
+			}
+		}
+		result = tx.Save(&p)
+		if result.Error != nil {
+			return result.Error
+		}
+		return saveTranslations(tx, previous, p)
+	})
+	if err != nil {
+		return VideoSegment{}, err
 	}
 	return p, nil
 }
 
 func (m mysql) Delete(ctx context.Context, projectID, ID string) error {
-	result := m.db.Where("id = ? and project_id = ?", ID, projectID).Delete(VideoSegment{})
+	result := m.db.Where("video_segment_id = ?", ID).Delete(Translation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	result = m.db.Where("id = ? and project_id = ?", ID, projectID).Delete(VideoSegment{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 )
 
 type Store interface {
@@ -29,10 +31,21 @@ func GetUpdaters(runningIdemKey, completeRecIdemKey, state, videoFile, script st
 		s = unset
 	}
 	var setters []func(*VideoSegment) error
-	if s == unset && script != "" {
-		setters = append(setters, setScript(script))
+	if s == unset {
+		if script == "" && hidden == nil {
+			return setters, fmt.Errorf("No fields passed to update video segment")
+		}
+		if script != "" {
+			setters = append(setters, setScript(script))
+		}
+		if hidden != nil {
+			setters = append(setters, setHidden(*hidden))
+		}
 		return setters, nil
 	}
+	if hidden != nil {
+		return setters, fmt.Errorf("Hidden cannot be updated together with the status of the video segment")
+	}
 	if s == running && runningIdemKey == "" {
 		return setters, fmt.Errorf("No IdemKey passed to change the status to running state")
 	}
@@ -51,18 +64,44 @@ func GetUpdaters(runningIdemKey, completeRecIdemKey, state, videoFile, script st
 		return setters, nil
 	}
 	if s == completed && completeRecIdemKey != "" {
-		setters = append(setters, setStatus(s), clearCompleteRecIdemKey(completeRecIdemKey), setVideoFile(videoFile))
+		setters = append(setters, setStatus(s), clearCompleteRecIdemKey(completeRecIdemKey), setVideoFile(videoFile), setRenderedInputs())
 		return setters, nil
 	}
-	if hidden != nil {
-		setters = append(setters, setHidden(*hidden))
-	}
 	return setters, fmt.Errorf("Unexpected issue found")
 }
 
-func RegenerateIdemKeys() ([]func(*VideoSegment) error, error) {
+// RegenerateIdemKeys prepares the video segment to be generated with the settings of the project
+func RegenerateIdemKeys(projectSettings settings.Settings) ([]func(*VideoSegment) error, error) {
+	var setters []func(*VideoSegment) error
+	setters = append(setters, recreateIdemKeys(projectSettings))
+	return setters, nil
+}
+
+// SetVoiceOverride replaces the voice override of the video segment
+// An empty voice override uses the voice settings of the project
+func SetVoiceOverride(voice settings.Voice) ([]func(*VideoSegment) error, error) {
+	voice = voice.Normalize()
+	err := voice.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var setters []func(*VideoSegment) error
+	setters = append(setters, func(a *VideoSegment) error {
+		if a.VoiceOverride == voice {
+			return nil
+		}
+		a.VoiceOverride = voice
+		a.DateModified = time.Now()
+		return nil
+	})
+	return setters, nil
+}
+
+// RevertScript sets the script back to the script of an earlier revision
+// Reverting is recorded as a new revision of the script
+func RevertScript(script string) ([]func(*VideoSegment) error, error) {
 	var setters []func(*VideoSegment) error
-	setters = append(setters, recreateIdemKeys())
+	setters = append(setters, setScript(script))
 	return setters, nil
 }
 
@@ -73,12 +112,14 @@ func ResetStatus() ([]func(*VideoSegment) error, error) {
 	return setters, nil
 }
 
-func recreateIdemKeys() func(*VideoSegment) error {
+func recreateIdemKeys(projectSettings settings.Settings) func(*VideoSegment) error {
 	return func(a *VideoSegment) error {
 		idemKey1, _ := uuid.NewV4()
 		idemKey2, _ := uuid.NewV4()
 		a.SetRunningIdemKey = idemKey1.String()
 		a.CompleteRecIdemKey = idemKey2.String()
+		a.GeneratingScriptRevision = a.ScriptRevision
+		a.GeneratingFingerprint = a.Fingerprint(projectSettings)
 		return nil
 	}
 }
@@ -92,7 +133,11 @@ func setStatus(s status) func(*VideoSegment) error {
 
 func setHidden(hide bool) func(*VideoSegment) error {
 	return func(a *VideoSegment) error {
+		if a.Hidden == hide {
+			return nil
+		}
 		a.Hidden = hide
+		a.DateModified = time.Now()
 		return nil
 	}
 }
@@ -106,7 +151,20 @@ func setVideoFile(videoFile string) func(*VideoSegment) error {
 
 func setScript(script string) func(*VideoSegment) error {
 	return func(a *VideoSegment) error {
+		if a.Script == script {
+			return nil
+		}
 		a.Script = script
+		a.ScriptRevision = a.ScriptRevision + 1
+		a.DateModified = time.Now()
+		return nil
+	}
+}
+
+func setRenderedInputs() func(*VideoSegment) error {
+	return func(a *VideoSegment) error {
+		a.VideoScriptRevision = a.GeneratingScriptRevision
+		a.RenderedFingerprint = a.GeneratingFingerprint
 		return nil
 	}
 }
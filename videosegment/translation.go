@@ -0,0 +1,186 @@
+package videosegment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
+
+// Translation holds the script and generated video of the video segment in one of the
+// additional languages of the project. The primary language is kept in the fields of the
+// video segment itself
+type Translation struct {
+	VideoSegmentID        string    `json:"-" datastore:"-" gorm:"type:varchar(40);primary_key"`
+	Language              string    `json:"language" gorm:"type:varchar(20);primary_key"`
+	Script                string    `json:"script" datastore:",noindex" gorm:"type:text"`
+	VideoFile             string    `json:"video_file" gorm:"type:varchar(100)"`
+	Status                status    `json:"status" gorm:"type:varchar(20)"`
+	SetRunningIdemKey     string    `json:"-" gorm:"type:varchar(40)"`
+	CompleteRecIdemKey    string    `json:"-" gorm:"type:varchar(40)"`
+	RenderedFingerprint   string    `json:"rendered_fingerprint" gorm:"type:varchar(64)"`
+	GeneratingFingerprint string    `json:"-" gorm:"type:varchar(64)"`
+	DateModified          time.Time `json:"date_modified"`
+}
+
+func (t *Translation) IsReady() bool {
+	return t.Status == completed
+}
+
+// Translation retrieves the script and video of the video segment in the language
+func (v *VideoSegment) Translation(language string) (Translation, bool) {
+	for _, t := range v.Translations {
+		if strings.EqualFold(t.Language, language) {
+			return t, true
+		}
+	}
+	return Translation{}, false
+}
+
+// TranslationFingerprint is a hash of all inputs that affect the generated video of the
+// video segment in the language. The settings are the project settings for the language
+// Pre-recorded audio is only used for the primary language and is left out of the hash
+func (v *VideoSegment) TranslationFingerprint(language string, languageSettings settings.Settings) string {
+	t, _ := v.Translation(language)
+	return fingerprint(v.ImageID, t.Script, "", v.VideoSrcID, settings.Effective(languageSettings, settings.Voice{}))
+}
+
+// IsTranslationStale returns true if the video segment has no generated video in the
+// language or if its inputs have changed since the video was generated
+func (v *VideoSegment) IsTranslationStale(language string, languageSettings settings.Settings) bool {
+	t, ok := v.Translation(language)
+	if !ok || t.Status != completed || t.VideoFile == "" {
+		return true
+	}
+	return t.RenderedFingerprint != v.TranslationFingerprint(language, languageSettings)
+}
+
+// SetTranslationScript sets the script of the video segment in an additional language
+func SetTranslationScript(language, script string) ([]func(*VideoSegment) error, error) {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		return nil, fmt.Errorf("language is required to set the script of a translation")
+	}
+	var setters []func(*VideoSegment) error
+	setters = append(setters, func(a *VideoSegment) error {
+		for i, t := range a.Translations {
+			if strings.EqualFold(t.Language, language) {
+				a.Translations[i].Script = script
+				a.Translations[i].DateModified = time.Now()
+				return nil
+			}
+		}
+		a.Translations = append(a.Translations, Translation{
+			VideoSegmentID: a.ID,
+			Language:       language,
+			Script:         script,
+			Status:         created,
+			DateModified:   time.Now(),
+		})
+		return nil
+	})
+	return setters, nil
+}
+
+// RemoveTranslation removes the script and video of the video segment in the language
+func RemoveTranslation(language string) ([]func(*VideoSegment) error, error) {
+	var setters []func(*VideoSegment) error
+	setters = append(setters, func(a *VideoSegment) error {
+		translations := []Translation{}
+		for _, t := range a.Translations {
+			if !strings.EqualFold(t.Language, language) {
+				translations = append(translations, t)
+			}
+		}
+		if len(translations) == len(a.Translations) {
+			return fmt.Errorf("video segment has no script for language %v", language)
+		}
+		a.Translations = translations
+		return nil
+	})
+	return setters, nil
+}
+
+// RegenerateTranslationIdemKeys prepares the video segment to be generated in the language
+// with the project settings for the language
+func RegenerateTranslationIdemKeys(language string, languageSettings settings.Settings) ([]func(*VideoSegment) error, error) {
+	var setters []func(*VideoSegment) error
+	setters = append(setters, updateTranslation(language, func(v *VideoSegment, t *Translation) error {
+		if strings.TrimSpace(t.Script) == "" {
+			return fmt.Errorf("video segment has no script for language %v", language)
+		}
+		idemKey1, _ := uuid.NewV4()
+		idemKey2, _ := uuid.NewV4()
+		t.SetRunningIdemKey = idemKey1.String()
+		t.CompleteRecIdemKey = idemKey2.String()
+		t.GeneratingFingerprint = v.TranslationFingerprint(language, languageSettings)
+		return nil
+	}))
+	return setters, nil
+}
+
+func ResetTranslationStatus(language string) ([]func(*VideoSegment) error, error) {
+	var setters []func(*VideoSegment) error
+	setters = append(setters, updateTranslation(language, func(v *VideoSegment, t *Translation) error {
+		t.Status = unset
+		t.VideoFile = ""
+		return nil
+	}))
+	return setters, nil
+}
+
+// GetTranslationUpdaters are used by the workers to report the progress of the generation
+// of the video of the video segment in the language
+func GetTranslationUpdaters(language, runningIdemKey, completeRecIdemKey, state, videoFile string) ([]func(*VideoSegment) error, error) {
+	var setters []func(*VideoSegment) error
+	switch state {
+	case "running":
+		if runningIdemKey == "" {
+			return setters, fmt.Errorf("No IdemKey passed to change the status to running state")
+		}
+		setters = append(setters, updateTranslation(language, func(v *VideoSegment, t *Translation) error {
+			if t.SetRunningIdemKey != runningIdemKey {
+				return fmt.Errorf("Idemkey set is not the same. Cannot clear idemkey values")
+			}
+			t.SetRunningIdemKey = ""
+			t.Status = running
+			return nil
+		}))
+	case "error", "completed":
+		if completeRecIdemKey == "" {
+			return setters, fmt.Errorf("No CompleteRec IdemKey passed to change status to error/completed")
+		}
+		if state == "completed" && !strings.Contains(videoFile, ".mp4") {
+			return setters, fmt.Errorf("Missing/invalid videofile")
+		}
+		setters = append(setters, updateTranslation(language, func(v *VideoSegment, t *Translation) error {
+			if t.CompleteRecIdemKey != completeRecIdemKey {
+				return fmt.Errorf("Idemkey set is not the same. Cannot clear idemkey values")
+			}
+			t.CompleteRecIdemKey = ""
+			t.Status = errorStatus
+			if state == "completed" {
+				t.Status = completed
+				t.VideoFile = videoFile
+				t.RenderedFingerprint = t.GeneratingFingerprint
+			}
+			return nil
+		}))
+	default:
+		return setters, fmt.Errorf("Unsupported status %v for translation", state)
+	}
+	return setters, nil
+}
+
+func updateTranslation(language string, update func(*VideoSegment, *Translation) error) func(*VideoSegment) error {
+	return func(a *VideoSegment) error {
+		for i, t := range a.Translations {
+			if strings.EqualFold(t.Language, language) {
+				return update(a, &a.Translations[i])
+			}
+		}
+		return fmt.Errorf("video segment has no script for language %v", language)
+	}
+}
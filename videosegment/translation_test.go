@@ -0,0 +1,67 @@
+package videosegment
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
+
+func TestVideoSegment_Translation(t *testing.T) {
+	v := New("project", "image.png", 0)
+	v.Script = "hello"
+	languageSettings := settings.Settings{Voice: settings.Voice{LanguageCode: "fr-FR"}}
+
+	if _, err := RegenerateTranslationIdemKeys("fr-FR", languageSettings); err != nil {
+		t.Fatalf("unexpected error when retrieving updaters. Err: %v", err)
+	}
+	updaters, _ := RegenerateTranslationIdemKeys("fr-FR", languageSettings)
+	if err := updaters[0](&v); err == nil {
+		t.Errorf("expected error when generating a language without a script")
+	}
+
+	scriptUpdaters, _ := SetTranslationScript("fr-FR", "bonjour")
+	scriptUpdaters[0](&v)
+	if !v.IsTranslationStale("fr-FR", languageSettings) {
+		t.Errorf("translation without generated video is expected to be stale")
+	}
+
+	updaters, _ = RegenerateTranslationIdemKeys("fr-FR", languageSettings)
+	if err := updaters[0](&v); err != nil {
+		t.Fatalf("unexpected error when regenerating idem keys. Err: %v", err)
+	}
+	tr, _ := v.Translation("fr-FR")
+	runningUpdaters, err := GetTranslationUpdaters("fr-FR", tr.SetRunningIdemKey, "", "running", "")
+	if err != nil {
+		t.Fatalf("unexpected error when retrieving updaters. Err: %v", err)
+	}
+	if err = runningUpdaters[0](&v); err != nil {
+		t.Fatalf("unexpected error when setting translation to running. Err: %v", err)
+	}
+	completeUpdaters, _ := GetTranslationUpdaters("fr-FR", "", tr.CompleteRecIdemKey, "completed", "video-fr-FR.mp4")
+	if err = completeUpdaters[0](&v); err != nil {
+		t.Fatalf("unexpected error when completing translation. Err: %v", err)
+	}
+	if v.IsTranslationStale("fr-FR", languageSettings) {
+		t.Errorf("translation is not expected to be stale after generation")
+	}
+	if v.VideoFile != "" || v.Status == completed {
+		t.Errorf("video of the primary language is not expected to change. Segment: %+v", v)
+	}
+
+	scriptUpdaters, _ = SetTranslationScript("fr-FR", "bonjour le monde")
+	scriptUpdaters[0](&v)
+	if !v.IsTranslationStale("fr-FR", languageSettings) {
+		t.Errorf("translation is expected to be stale after its script changed")
+	}
+	if len(v.Translations) != 1 {
+		t.Errorf("expected script of language to be replaced. Translations: %+v", v.Translations)
+	}
+
+	removeUpdaters, _ := RemoveTranslation("fr-FR")
+	if err = removeUpdaters[0](&v); err != nil || len(v.Translations) != 0 {
+		t.Errorf("expected translation to be removed. Err: %v", err)
+	}
+	if err = removeUpdaters[0](&v); err == nil {
+		t.Errorf("expected error when removing a translation that does not exist")
+	}
+}
@@ -1,9 +1,13 @@
 package videosegment
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
 )
 
 type status string
@@ -30,10 +34,23 @@ type VideoSegment struct {
 	// Image Source
 	ImageID string `json:"image_id" gorm:"type:varchar(100)"`
 	Script  string `json:"script" gorm:"type:text"`
+	// ScriptRevision is incremented on every edit of the script while VideoScriptRevision
+	// is the script revision that was used to generate the current video file
+	ScriptRevision           int `json:"script_revision" gorm:"type:int"`
+	VideoScriptRevision      int `json:"video_script_revision" gorm:"type:int"`
+	GeneratingScriptRevision int `json:"-" gorm:"type:int"`
+	// RenderedFingerprint is the fingerprint of the inputs used to generate the current video file
+	RenderedFingerprint   string `json:"rendered_fingerprint" gorm:"type:varchar(64)"`
+	GeneratingFingerprint string `json:"-" gorm:"type:varchar(64)"`
 	// Audio Source
 	AudioID string `json:"audio_id" gorm:"type:varchar(40)"`
 	// Video Source
 	VideoSrcID string `json:"video_src_id" gorm:"type:varchar(40)"`
+	// VoiceOverride replaces the voice settings of the project for this video segment
+	VoiceOverride settings.Voice `json:"voice_override" gorm:"embedded;embedded_prefix:voice_override_"`
+	// Translations are the scripts and videos of the additional languages of the project
+	// The voice override only applies to the primary language
+	Translations []Translation `json:"translations,omitempty" gorm:"-"`
 }
 
 func (v *VideoSegment) IsReady() bool {
@@ -43,6 +60,48 @@ func (v *VideoSegment) IsReady() bool {
 	return false
 }
 
+// IsScriptStale returns true if the script was edited after the video file was generated
+func (v *VideoSegment) IsScriptStale() bool {
+	return v.VideoFile != "" && v.VideoScriptRevision != v.ScriptRevision
+}
+
+// Settings are the effective settings used to generate the video of the segment
+func (v *VideoSegment) Settings(projectSettings settings.Settings) settings.Settings {
+	return settings.Effective(projectSettings, v.VoiceOverride)
+}
+
+// Fingerprint is a hash of all inputs that affect the generated video of the segment
+func (v *VideoSegment) Fingerprint(projectSettings settings.Settings) string {
+	return fingerprint(v.ImageID, v.Script, v.AudioID, v.VideoSrcID, v.Settings(projectSettings))
+}
+
+func fingerprint(imageID, script, audioID, videoSrcID string, s settings.Settings) string {
+	inputs := struct {
+		ImageID    string            `json:"image_id"`
+		Script     string            `json:"script"`
+		AudioID    string            `json:"audio_id"`
+		VideoSrcID string            `json:"video_src_id"`
+		Settings   settings.Settings `json:"settings"`
+	}{
+		ImageID:    imageID,
+		Script:     script,
+		AudioID:    audioID,
+		VideoSrcID: videoSrcID,
+		Settings:   s,
+	}
+	raw, _ := json.Marshal(inputs)
+	return fmt.Sprintf("%x", sha256.Sum256(raw))
+}
+
+// IsStale returns true if the video segment has no generated video or if its inputs
+// have changed since the video was generated
+func (v *VideoSegment) IsStale(projectSettings settings.Settings) bool {
+	if v.Status != completed || v.VideoFile == "" {
+		return true
+	}
+	return v.RenderedFingerprint != v.Fingerprint(projectSettings)
+}
+
 func New(projectID, imageID string, order int) VideoSegment {
 	videoSegmentID, _ := uuid.NewV4()
 	return VideoSegment{
@@ -56,6 +115,28 @@ func New(projectID, imageID string, order int) VideoSegment {
 	}
 }
 
+// Clone copies the script and settings of the video segment into a new video segment
+// under the given project. The new video segment refers to the same image and audio files
+// and is marked as not yet generated. Project.Copy gives the files new names for the copy
+func (v *VideoSegment) Clone(projectID string) VideoSegment {
+	newSegment := New(projectID, v.ImageID, v.Order)
+	newSegment.Hidden = v.Hidden
+	newSegment.Script = v.Script
+	newSegment.AudioID = v.AudioID
+	newSegment.VideoSrcID = v.VideoSrcID
+	newSegment.VoiceOverride = v.VoiceOverride
+	for _, t := range v.Translations {
+		newSegment.Translations = append(newSegment.Translations, Translation{
+			VideoSegmentID: newSegment.ID,
+			Language:       t.Language,
+			Script:         t.Script,
+			Status:         created,
+			DateModified:   newSegment.DateModified,
+		})
+	}
+	return newSegment
+}
+
 type ByOrder []VideoSegment
 
 func (s ByOrder) Len() int { return len(s) }
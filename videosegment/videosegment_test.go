@@ -0,0 +1,130 @@
+package videosegment
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/slides-to-video-manager/settings"
+)
+
+func TestVideoSegment_IsStale(t *testing.T) {
+	v := New("project", "image.png", 0)
+	v.Script = "hello"
+	if !v.IsStale(settings.Settings{}) {
+		t.Errorf("video segment without generated video is expected to be stale")
+	}
+
+	updaters, _ := RegenerateIdemKeys(settings.Settings{})
+	for _, u := range updaters {
+		u(&v)
+	}
+	completeUpdaters, err := GetUpdaters("", v.CompleteRecIdemKey, "completed", "video.mp4", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error when retrieving updaters. Err: %v", err)
+	}
+	for _, u := range completeUpdaters {
+		u(&v)
+	}
+	if v.IsStale(settings.Settings{}) {
+		t.Errorf("video segment is not expected to be stale after generation")
+	}
+
+	setScript("hello world")(&v)
+	if !v.IsStale(settings.Settings{}) {
+		t.Errorf("video segment is expected to be stale after its script changed")
+	}
+	if !v.IsScriptStale() {
+		t.Errorf("video segment script is expected to be stale after its script changed")
+	}
+}
+
+func TestVideoSegment_IsStale_Settings(t *testing.T) {
+	v := New("project", "image.png", 0)
+	v.Script = "hello"
+	projectSettings := settings.Settings{Voice: settings.Voice{LanguageCode: "en-GB"}}
+
+	updaters, _ := RegenerateIdemKeys(projectSettings)
+	for _, u := range updaters {
+		u(&v)
+	}
+	completeUpdaters, _ := GetUpdaters("", v.CompleteRecIdemKey, "completed", "video.mp4", "", nil)
+	for _, u := range completeUpdaters {
+		u(&v)
+	}
+	if v.IsStale(projectSettings) {
+		t.Errorf("video segment is not expected to be stale after generation")
+	}
+	if !v.IsStale(settings.Settings{Render: settings.Render{FrameRate: 30}}) {
+		t.Errorf("video segment is expected to be stale after the project settings changed")
+	}
+
+	overrideUpdaters, err := SetVoiceOverride(settings.Voice{Gender: "male"})
+	if err != nil {
+		t.Fatalf("unexpected error when overriding voice. Err: %v", err)
+	}
+	for _, u := range overrideUpdaters {
+		u(&v)
+	}
+	if v.VoiceOverride.Gender != settings.GenderMale {
+		t.Errorf("expected voice gender to be normalized. Voice: %+v", v.VoiceOverride)
+	}
+	if !v.IsStale(projectSettings) {
+		t.Errorf("video segment is expected to be stale after its voice override changed")
+	}
+	if _, err = SetVoiceOverride(settings.Voice{SpeakingRate: 10}); err == nil {
+		t.Errorf("expected error for invalid voice override")
+	}
+}
+
+func TestGetUpdaters_Hidden(t *testing.T) {
+	hidden := true
+	v := New("project", "image.png", 0)
+
+	updaters, err := GetUpdaters("", "", "", "", "", &hidden)
+	if err != nil {
+		t.Fatalf("unexpected error when hiding video segment. Err: %v", err)
+	}
+	for _, u := range updaters {
+		u(&v)
+	}
+	if !v.Hidden {
+		t.Errorf("video segment is expected to be hidden")
+	}
+
+	_, err = GetUpdaters("", "", "", "", "", nil)
+	if err == nil {
+		t.Errorf("expected error when no fields are passed for update")
+	}
+
+	_, err = GetUpdaters("", "1234", "error", "", "", &hidden)
+	if err == nil {
+		t.Errorf("expected error when hidden is updated together with status")
+	}
+}
+
+func TestVideoSegment_Clone(t *testing.T) {
+	v := New("project", "image.png", 3)
+	v.Hidden = true
+	v.Script = "hello"
+	v.AudioID = "audio.mp3"
+	v.VideoFile = v.ID + ".mp4"
+	v.Status = completed
+	v.Translations = []Translation{{VideoSegmentID: v.ID, Language: "fr-FR", Script: "bonjour", VideoFile: v.ID + "-fr-FR.mp4", Status: completed}}
+
+	cloned := v.Clone("other")
+	if cloned.ID == v.ID || cloned.ProjectID != "other" || cloned.Order != 3 || cloned.ImageID != "image.png" {
+		t.Fatalf("expected clone to be a new video segment of the other project. Clone: %+v", cloned)
+	}
+	if !cloned.Hidden || cloned.Script != "hello" || cloned.AudioID != "audio.mp3" {
+		t.Errorf("expected script and settings to be copied. Clone: %+v", cloned)
+	}
+	if cloned.VideoFile != "" || cloned.Status != created {
+		t.Errorf("expected clone to not be generated yet. Clone: %+v", cloned)
+	}
+	if len(cloned.Translations) != 1 {
+		t.Fatalf("expected translations to be copied. Translations: %+v", cloned.Translations)
+	}
+	translation := cloned.Translations[0]
+	if translation.VideoSegmentID != cloned.ID || translation.Script != "bonjour" || translation.VideoFile != "" || translation.Status != created {
+		t.Errorf("unexpected translation of clone. Translation: %+v", translation)
+	}
+}